@@ -0,0 +1,68 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Availability describes whether a trade archive has been published
+type Availability struct {
+	Symbol        string    `json:"symbol"`
+	Date          string    `json:"date"`
+	Exists        bool      `json:"exists"`
+	ContentLength int64     `json:"content_length"`
+	LastModified  time.Time `json:"last_modified,omitzero"`
+}
+
+// CheckAvailability checks whether the trade archive for a given symbol and date exists
+// without downloading it
+func (c *Connector) CheckAvailability(ctx context.Context, symbol, year, month, day string) (*Availability, error) {
+	downloader := c.getDownloader()
+
+	resp, err := downloader.Head(ctx, downloader.TradesURL(symbol, year, month, day))
+	if err != nil {
+		return nil, err
+	}
+
+	year, month, day = formatDate(year, month, day)
+	availability := &Availability{
+		Symbol: symbol,
+		Date:   fmt.Sprintf("%s-%s-%s", year, month, day),
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		availability.Exists = true
+		availability.ContentLength = resp.ContentLength
+		if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+			availability.LastModified = lastModified.UTC()
+		}
+	case http.StatusNotFound:
+		// Not published (yet)
+	case http.StatusTooManyRequests:
+		return nil, &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	default:
+		return nil, fmt.Errorf("failed to check file: status code %d", resp.StatusCode)
+	}
+
+	return availability, nil
+}
+
+// Ping checks that the data source is reachable with a lightweight HEAD request.
+// Any response below 500 counts as reachable.
+func (c *Connector) Ping(ctx context.Context) error {
+	downloader := c.getDownloader()
+
+	resp, err := downloader.Head(ctx, downloader.URL("/"))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("data source unavailable: status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package binancevisionconnector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// bookDepthTimeLayout is the layout of the timestamps in book depth files, in UTC
+const bookDepthTimeLayout = "2006-01-02 15:04:05"
+
+// BookDepth is the cumulative order book depth within a percentage of the mid price at
+// one snapshot. Negative percentages are bid levels below the mid price, positive ones
+// ask levels above it.
+type BookDepth struct {
+	Timestamp  int64   `json:"timestamp"`  // Snapshot time in epoch milliseconds
+	Percentage float64 `json:"percentage"` // Distance from the mid price in percent
+	Depth      float64 `json:"depth"`      // Quantity in the base asset
+	Notional   float64 `json:"notional"`   // Value in the quote asset
+}
+
+// BookDepthResult contains the downloaded book depth data
+type BookDepthResult struct {
+	Symbol      string      `json:"symbol"`
+	Date        string      `json:"date"`
+	RecordCount int         `json:"record_count"`
+	Records     []BookDepth `json:"records"`
+	Warnings    []string    `json:"warnings,omitempty"`
+	SourceURL   string      `json:"source_url,omitempty"`
+}
+
+// DownloadBookDepth downloads and parses book depth snapshots for a given symbol and date
+func (c *Connector) DownloadBookDepth(ctx context.Context, symbol, year, month, day string) (*BookDepthResult, error) {
+	year, month, day = formatDate(year, month, day)
+	downloader := c.getDownloader()
+	url := downloader.URL(fmt.Sprintf("/data/futures/um/daily/bookDepth/%s/%s-bookDepth-%s-%s-%s.zip",
+		symbol, symbol, year, month, day))
+
+	archive, err := downloader.spoolURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	records, warnings, err := c.parser.parseBookDepthZip(ctx, archive, archive.size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+
+	return &BookDepthResult{
+		Symbol:      symbol,
+		Date:        fmt.Sprintf("%s-%s-%s", year, month, day),
+		RecordCount: len(records),
+		Records:     records,
+		Warnings:    warnings,
+		SourceURL:   url,
+	}, nil
+}
+
+// ParseBookDepthZip extracts all CSV files from the zip archive and parses them as book depth
+// records. Per-file failures are returned as warnings when the parser runs in partial mode.
+func (p *Parser) ParseBookDepthZip(ctx context.Context, zipData []byte) ([]BookDepth, []string, error) {
+	return p.parseBookDepthZip(ctx, bytes.NewReader(zipData), int64(len(zipData)))
+}
+
+// parseBookDepthZip is ParseBookDepthZip for an archive of the given size read through r
+func (p *Parser) parseBookDepthZip(ctx context.Context, r io.ReaderAt, size int64) ([]BookDepth, []string, error) {
+	var (
+		allRecords []BookDepth
+		mu         sync.Mutex
+	)
+
+	warnings, err := p.processCSVFiles(ctx, r, size, func(r io.Reader, _ int64) error {
+		records, err := p.parseBookDepthCSV(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		allRecords = append(allRecords, records...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return allRecords, warnings, nil
+}
+
+// bookDepthSchema is the layout of book depth files, parsed by parseBookDepthRecord
+type bookDepthSchema struct{}
+
+func (bookDepthSchema) ColumnCount() int { return 4 }
+
+func (bookDepthSchema) ParseRecord(record []string) (interface{}, error) {
+	return parseBookDepthRecord(record)
+}
+
+// parseBookDepthCSV parses book depth CSV records one at a time
+func (p *Parser) parseBookDepthCSV(ctx context.Context, r io.Reader) ([]BookDepth, error) {
+	// A snapshot of ten levels is taken about every 30 seconds
+	records := make([]BookDepth, 0, 30000)
+
+	_, err := p.readCSVRows(ctx, r, fixedSchema(bookDepthSchema{}), 0, func(record interface{}) error {
+		records = append(records, record.(BookDepth))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// parseBookDepthRecord converts a CSV record into a BookDepth.
+// Columns: timestamp (YYYY-MM-DD HH:MM:SS in UTC), percentage, depth, notional
+func parseBookDepthRecord(record []string) (BookDepth, error) {
+	var depth BookDepth
+
+	timestamp, err := time.Parse(bookDepthTimeLayout, record[0])
+	if err != nil {
+		return depth, fmt.Errorf("invalid Timestamp: %w", err)
+	}
+	depth.Timestamp = timestamp.UnixMilli()
+
+	depth.Percentage, err = strconv.ParseFloat(record[1], 64)
+	if err != nil {
+		return depth, fmt.Errorf("invalid Percentage: %w", err)
+	}
+
+	depth.Depth, err = strconv.ParseFloat(record[2], 64)
+	if err != nil {
+		return depth, fmt.Errorf("invalid Depth: %w", err)
+	}
+
+	depth.Notional, err = strconv.ParseFloat(record[3], 64)
+	if err != nil {
+		return depth, fmt.Errorf("invalid Notional: %w", err)
+	}
+
+	return depth, nil
+}
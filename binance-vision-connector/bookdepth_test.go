@@ -0,0 +1,52 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDownloadBookDepth(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-bookDepth-2025-01-01.csv": "timestamp,percentage,depth,notional\n" +
+			"2025-01-01 00:00:08,-1.00,812.345,76512345.67\n" +
+			"2025-01-01 00:00:08,1.00,905.5,85312000.25\n" +
+			"2025-01-01 00:00:38,abc,1,1\n" +
+			"not-a-time,1.00,1,1\n",
+	})
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	result, err := c.DownloadBookDepth(context.Background(), "BTCUSDT", "2025", "1", "1")
+	if err != nil {
+		t.Fatalf("DownloadBookDepth() error = %v", err)
+	}
+
+	if want := "/data/futures/um/daily/bookDepth/BTCUSDT/BTCUSDT-bookDepth-2025-01-01.zip"; path != want {
+		t.Errorf("requested %q, want %q", path, want)
+	}
+	if result.Date != "2025-01-01" || result.SourceURL != server.URL+path {
+		t.Errorf("Date = %q, SourceURL = %q", result.Date, result.SourceURL)
+	}
+	if result.RecordCount != 2 {
+		t.Fatalf("RecordCount = %d, want 2 (malformed rows are skipped)", result.RecordCount)
+	}
+
+	want := BookDepth{Timestamp: 1735689608000, Percentage: -1, Depth: 812.345, Notional: 76512345.67}
+	if result.Records[0] != want {
+		t.Errorf("Records[0] = %+v, want %+v", result.Records[0], want)
+	}
+}
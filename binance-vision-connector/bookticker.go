@@ -0,0 +1,150 @@
+package binancevisionconnector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// BookTicker represents a single best bid/ask update from Binance Vision
+type BookTicker struct {
+	UpdateID   int64   `json:"update_id"`
+	BestBid    float64 `json:"best_bid"`
+	BestBidQty float64 `json:"best_bid_qty"`
+	BestAsk    float64 `json:"best_ask"`
+	BestAskQty float64 `json:"best_ask_qty"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+// BookTickerResult contains the downloaded book ticker data
+type BookTickerResult struct {
+	Symbol      string       `json:"symbol"`
+	Date        string       `json:"date"`
+	RecordCount int          `json:"record_count"`
+	Records     []BookTicker `json:"records"`
+	Warnings    []string     `json:"warnings,omitempty"`
+}
+
+// DownloadBookTicker downloads and parses book ticker data for a given symbol and date
+func (c *Connector) DownloadBookTicker(ctx context.Context, symbol, year, month, day string) (*BookTickerResult, error) {
+	year, month, day = formatDate(year, month, day)
+	downloader := c.getDownloader()
+	url := downloader.URL(fmt.Sprintf("/data/futures/um/daily/bookTicker/%s/%s-bookTicker-%s-%s-%s.zip",
+		symbol, symbol, year, month, day))
+
+	archive, err := downloader.spoolURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	records, warnings, err := c.parser.parseBookTickerZip(ctx, archive, archive.size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+
+	return &BookTickerResult{
+		Symbol:      symbol,
+		Date:        fmt.Sprintf("%s-%s-%s", year, month, day),
+		RecordCount: len(records),
+		Records:     records,
+		Warnings:    warnings,
+	}, nil
+}
+
+// ParseBookTickerZip extracts all CSV files from the zip archive and parses them as book ticker
+// records. Per-file failures are returned as warnings when the parser runs in partial mode.
+func (p *Parser) ParseBookTickerZip(ctx context.Context, zipData []byte) ([]BookTicker, []string, error) {
+	return p.parseBookTickerZip(ctx, bytes.NewReader(zipData), int64(len(zipData)))
+}
+
+// parseBookTickerZip is ParseBookTickerZip for an archive of the given size read through r
+func (p *Parser) parseBookTickerZip(ctx context.Context, r io.ReaderAt, size int64) ([]BookTicker, []string, error) {
+	var (
+		allRecords []BookTicker
+		mu         sync.Mutex
+	)
+
+	warnings, err := p.processCSVFiles(ctx, r, size, func(r io.Reader, _ int64) error {
+		records, err := p.parseBookTickerCSV(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		allRecords = append(allRecords, records...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return allRecords, warnings, nil
+}
+
+// bookTickerSchema is the layout of book ticker files, parsed by parseBookTickerRecord
+type bookTickerSchema struct{}
+
+func (bookTickerSchema) ColumnCount() int { return 6 }
+
+func (bookTickerSchema) ParseRecord(record []string) (interface{}, error) {
+	return parseBookTickerRecord(record)
+}
+
+// parseBookTickerCSV parses book ticker CSV records one at a time
+func (p *Parser) parseBookTickerCSV(ctx context.Context, r io.Reader) ([]BookTicker, error) {
+	records := make([]BookTicker, 0, 10000)
+
+	_, err := p.readCSVRows(ctx, r, fixedSchema(bookTickerSchema{}), 0, func(record interface{}) error {
+		records = append(records, record.(BookTicker))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// parseBookTickerRecord converts a CSV record into a BookTicker.
+// Columns: update_id, best_bid_price, best_bid_qty, best_ask_price, best_ask_qty, transaction_time[, event_time]
+func parseBookTickerRecord(record []string) (BookTicker, error) {
+	var ticker BookTicker
+	var err error
+
+	ticker.UpdateID, err = strconv.ParseInt(record[0], 10, 64)
+	if err != nil {
+		return ticker, fmt.Errorf("invalid UpdateId: %w", err)
+	}
+
+	ticker.BestBid, err = strconv.ParseFloat(record[1], 64)
+	if err != nil {
+		return ticker, fmt.Errorf("invalid BestBid: %w", err)
+	}
+
+	ticker.BestBidQty, err = strconv.ParseFloat(record[2], 64)
+	if err != nil {
+		return ticker, fmt.Errorf("invalid BestBidQty: %w", err)
+	}
+
+	ticker.BestAsk, err = strconv.ParseFloat(record[3], 64)
+	if err != nil {
+		return ticker, fmt.Errorf("invalid BestAsk: %w", err)
+	}
+
+	ticker.BestAskQty, err = strconv.ParseFloat(record[4], 64)
+	if err != nil {
+		return ticker, fmt.Errorf("invalid BestAskQty: %w", err)
+	}
+
+	ticker.Timestamp, err = strconv.ParseInt(record[5], 10, 64)
+	if err != nil {
+		return ticker, fmt.Errorf("invalid Timestamp: %w", err)
+	}
+
+	return ticker, nil
+}
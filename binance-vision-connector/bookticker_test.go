@@ -0,0 +1,51 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDownloadBookTicker(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-bookTicker-2025-01-01.csv": "update_id,best_bid_price,best_bid_qty,best_ask_price,best_ask_qty,transaction_time,event_time\n" +
+			"5000000001,94000.1,1.25,94000.2,0.5,1735689600001,1735689600002\n" +
+			"5000000002,94000.0,2,94000.3,0.75,1735689600010,1735689600011\n" +
+			"5000000003,bad,1,94000.3,1,1735689600020,1735689600021\n",
+	})
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	result, err := c.DownloadBookTicker(context.Background(), "BTCUSDT", "2025", "1", "1")
+	if err != nil {
+		t.Fatalf("DownloadBookTicker() error = %v", err)
+	}
+
+	if want := "/data/futures/um/daily/bookTicker/BTCUSDT/BTCUSDT-bookTicker-2025-01-01.zip"; path != want {
+		t.Errorf("requested %q, want %q", path, want)
+	}
+	if result.Date != "2025-01-01" {
+		t.Errorf("Date = %q, want 2025-01-01", result.Date)
+	}
+	if result.RecordCount != 2 {
+		t.Fatalf("RecordCount = %d, want 2 (malformed rows are skipped)", result.RecordCount)
+	}
+
+	want := BookTicker{UpdateID: 5000000001, BestBid: 94000.1, BestBidQty: 1.25, BestAsk: 94000.2, BestAskQty: 0.5, Timestamp: 1735689600001}
+	if result.Records[0] != want {
+		t.Errorf("Records[0] = %+v, want %+v", result.Records[0], want)
+	}
+}
@@ -0,0 +1,117 @@
+package binancevisionconnector
+
+import (
+	"container/list"
+	"sync"
+)
+
+// resultCache is a fixed-size LRU cache of parsed daily trade results. Published
+// archives never change, so entries do not expire.
+type resultCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List               // Most recently used at the front
+	entries map[string]*list.Element // Values are *cacheEntry
+
+	hits, misses, evictions int64
+}
+
+// CacheStats reports the activity of the result cache since the connector was created
+type CacheStats struct {
+	Hits      int64   `json:"cache_hits"`
+	Misses    int64   `json:"cache_misses"`
+	Evictions int64   `json:"cache_evictions"`
+	HitRatio  float64 `json:"cache_hit_ratio"` // Hits over lookups, 0 before the first lookup
+	Size      int     `json:"cache_size"`      // Results currently cached
+	Capacity  int     `json:"cache_capacity"`  // CacheSize, 0 when caching is disabled
+}
+
+// cacheEntry is a cached result and its key
+type cacheEntry struct {
+	key    string
+	result *DownloadResult
+}
+
+// newResultCache creates a cache holding up to size results, or nil when size is not positive
+func newResultCache(size int) *resultCache {
+	if size <= 0 {
+		return nil
+	}
+	return &resultCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// cacheKey identifies a symbol's trades for one day
+func cacheKey(symbol, date string) string {
+	return symbol + "|" + date
+}
+
+// get returns a copy of the cached result so callers can change its fields (for
+// example to paginate) without affecting the cache. The Trades slice is shared and
+// must not be modified in place. A nil cache never hits.
+func (c *resultCache) get(key string) (*DownloadResult, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+
+	result := *elem.Value.(*cacheEntry).result
+	return &result, true
+}
+
+// add stores a copy of result, evicting the least recently used entry when full
+func (c *resultCache) add(key string, result *DownloadResult) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stored := *result
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).result = &stored
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: &stored})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.evictions++
+	}
+}
+
+// stats returns the cache's counters. A nil cache reports zeros.
+func (c *resultCache) stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      c.order.Len(),
+		Capacity:  c.size,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRatio = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
@@ -0,0 +1,99 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResultCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newResultCache(2)
+	c.add("a", &DownloadResult{Symbol: "A"})
+	c.add("b", &DownloadResult{Symbol: "B"})
+	c.get("a") // a is now more recent than b
+	c.add("c", &DownloadResult{Symbol: "C"})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("get(b) hit, want it evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("get(%s) missed, want a hit", key)
+		}
+	}
+
+	// Callers get copies, so changing a returned result leaves the cache intact
+	result, _ := c.get("a")
+	result.TradeCount = 99
+	if cached, _ := c.get("a"); cached.TradeCount != 0 {
+		t.Errorf("cached TradeCount = %d after modifying a returned copy, want 0", cached.TradeCount)
+	}
+}
+
+func TestResultCache_Stats(t *testing.T) {
+	c := newResultCache(2)
+	c.add("a", &DownloadResult{Symbol: "A"})
+	c.add("b", &DownloadResult{Symbol: "B"})
+	c.add("c", &DownloadResult{Symbol: "C"}) // Evicts a
+	c.get("a")
+	c.get("b")
+	c.get("c")
+	c.get("c")
+
+	want := CacheStats{Hits: 3, Misses: 1, Evictions: 1, HitRatio: 0.75, Size: 2, Capacity: 2}
+	if got := c.stats(); got != want {
+		t.Errorf("stats() = %+v, want %+v", got, want)
+	}
+
+	var disabled *resultCache
+	if got := disabled.stats(); got != (CacheStats{}) {
+		t.Errorf("stats() of a disabled cache = %+v, want zeros", got)
+	}
+}
+
+func TestDownloadTrades_Cache(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n"})
+
+	// Each request reports a later publish time, as if the archive were re-published
+	published := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", published.Add(time.Duration(requests)*time.Hour).Format(http.TimeFormat))
+		requests++
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.CacheSize = 4
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	first, err := c.DownloadTrades(context.Background(), "BTCUSDT", "2025", "1", "1")
+	if err != nil {
+		t.Fatalf("DownloadTrades() error = %v", err)
+	}
+	// The same day with different zero-padding is the same cache entry
+	second, err := c.DownloadTrades(context.Background(), "BTCUSDT", "2025", "01", "01")
+	if err != nil {
+		t.Fatalf("DownloadTrades() error = %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("upstream requests = %d, want 1", requests)
+	}
+	if first.CacheHit || !second.CacheHit {
+		t.Errorf("CacheHit = %v, %v, want false, true", first.CacheHit, second.CacheHit)
+	}
+	if second.TradeCount != 1 {
+		t.Errorf("cached TradeCount = %d, want 1", second.TradeCount)
+	}
+	if !first.PublishedAt.Equal(published) || !second.PublishedAt.Equal(published) {
+		t.Errorf("PublishedAt = %v, %v, want %v from the original download", first.PublishedAt, second.PublishedAt, published)
+	}
+}
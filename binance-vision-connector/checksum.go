@@ -0,0 +1,91 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChecksumReport is the result of verifying one daily archive against its .CHECKSUM file
+type ChecksumReport struct {
+	Date     string `json:"date"`
+	OK       bool   `json:"ok"`
+	Expected string `json:"expected,omitempty"` // SHA256 published in the .CHECKSUM file
+	Actual   string `json:"actual,omitempty"`   // SHA256 of the downloaded archive
+	Error    string `json:"error,omitempty"`    // Why the day could not be verified
+}
+
+// VerifyRange downloads the trade archive and its .CHECKSUM file for every day from
+// from to to (inclusive) and compares their SHA256 digests without parsing the CSV data.
+// Per-day failures such as missing files are reported in the day's Error field;
+// only an invalid range or a cancelled context fails the whole call.
+func (c *Connector) VerifyRange(ctx context.Context, symbol string, from, to time.Time) ([]ChecksumReport, error) {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s is after %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	downloader := c.getDownloader()
+
+	var reports []ChecksumReport
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		report := ChecksumReport{Date: day.Format("2006-01-02")}
+		url := downloader.TradesURL(symbol, day.Format("2006"), day.Format("01"), day.Format("02"))
+
+		expected, err := downloadChecksum(ctx, downloader, url+".CHECKSUM")
+		if err != nil {
+			report.Error = err.Error()
+			reports = append(reports, report)
+			continue
+		}
+		report.Expected = expected
+
+		zipData, err := downloader.DownloadURLToMemory(ctx, url)
+		if err != nil {
+			report.Error = err.Error()
+			reports = append(reports, report)
+			continue
+		}
+
+		sum := sha256.Sum256(zipData)
+		report.Actual = hex.EncodeToString(sum[:])
+		report.OK = report.Actual == report.Expected
+		reports = append(reports, report)
+	}
+
+	// A cancellation during the last download is reported as-is
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// downloadChecksum fetches a .CHECKSUM file, which has the form "<sha256>  <file name>",
+// and returns the lowercase hex digest
+func downloadChecksum(ctx context.Context, downloader *Downloader, url string) (string, error) {
+	data, err := downloader.DownloadURLToMemory(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksum: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file")
+	}
+
+	digest := strings.ToLower(fields[0])
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid checksum: %s", fields[0])
+	}
+
+	return digest, nil
+}
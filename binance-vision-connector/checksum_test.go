@@ -0,0 +1,65 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyRange(t *testing.T) {
+	archive := []byte("archive contents")
+	sum := sha256.Sum256(archive)
+	digest := hex.EncodeToString(sum[:])
+
+	// Day 1 matches, day 2 has a mismatched checksum, day 3 is missing
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "2025-01-01.zip"), strings.HasSuffix(r.URL.Path, "2025-01-02.zip"):
+			w.Write(archive)
+		case strings.HasSuffix(r.URL.Path, "2025-01-01.zip.CHECKSUM"):
+			fmt.Fprintf(w, "%s  BTCUSDT-trades-2025-01-01.zip\n", digest)
+		case strings.HasSuffix(r.URL.Path, "2025-01-02.zip.CHECKSUM"):
+			fmt.Fprintf(w, "%s  BTCUSDT-trades-2025-01-02.zip\n", strings.Repeat("0", 64))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	reports, err := c.VerifyRange(context.Background(), "BTCUSDT", from, to)
+	if err != nil {
+		t.Fatalf("VerifyRange() error = %v", err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("VerifyRange() returned %d reports, want 3", len(reports))
+	}
+
+	if r := reports[0]; r.Date != "2025-01-01" || !r.OK || r.Actual != digest || r.Expected != digest {
+		t.Errorf("day 1 report = %+v, want a match", r)
+	}
+	if r := reports[1]; r.OK || r.Actual != digest || r.Error != "" {
+		t.Errorf("day 2 report = %+v, want a mismatch", r)
+	}
+	if r := reports[2]; r.OK || r.Error == "" {
+		t.Errorf("day 3 report = %+v, want an error", r)
+	}
+
+	if _, err := c.VerifyRange(context.Background(), "BTCUSDT", to, from); err == nil {
+		t.Error("VerifyRange() with reversed range should fail")
+	}
+}
@@ -2,8 +2,13 @@ package binancevisionconnector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 )
@@ -21,53 +26,274 @@ type Trade struct {
 
 // DownloadResult contains the downloaded trades data
 type DownloadResult struct {
-	Symbol     string  `json:"symbol"`
-	Date       string  `json:"date"`
-	TradeCount int     `json:"trade_count"`
-	Trades     []Trade `json:"trades"`
+	Symbol     string     `json:"symbol"`
+	Date       string     `json:"date"`
+	TradeCount int        `json:"trade_count"`
+	Trades     []Trade    `json:"trades"`
+	Warnings   []string   `json:"warnings,omitempty"` // Per-file failures when PartialOK is set
+	ParseStats ParseStats `json:"parse_stats"`
+	Truncated  bool       `json:"truncated"`      // At least one file hit MaxTradesPerFile, so trades are incomplete
+	VWAP       float64    `json:"vwap,omitempty"` // Volume-weighted average price; set when streaming, or on request
+
+	// EmptyDay is set when the archive held no trade rows at all, as on days an illiquid
+	// symbol did not trade. AllRowsSkipped is set instead when rows were present but every
+	// one was skipped, so the zero trades point at a parsing problem rather than a quiet day.
+	EmptyDay       bool `json:"empty_day"`
+	AllRowsSkipped bool `json:"all_rows_skipped"`
+
+	DuplicatesRemoved int    `json:"duplicates_removed"` // Trades dropped by Deduplicate because their TradeID repeated
+	Timing            Timing `json:"timing"`
+	CompressedBytes   int64  `json:"compressed_bytes"`     // Size of the downloaded zip archive
+	UncompressedBytes int64  `json:"uncompressed_bytes"`   // Total size of the CSV files in the archive
+	CacheHit          bool   `json:"cache_hit"`            // Served from the result cache, so Timing is from the original download
+	SourceURL         string `json:"source_url,omitempty"` // Archive URL the trades were downloaded from
+
+	// PublishedAt is the Last-Modified time Binance Vision sent with the archive, for
+	// telling a re-published archive apart. Zero when the server sent none.
+	PublishedAt time.Time `json:"published_at,omitzero"`
+
+	// MonthlyFallback is set when the daily archive was missing and the trades were
+	// taken from the monthly archive under FallbackToMonthly. SourceURL then names the
+	// monthly archive, and ParseStats.Filtered counts the trades of the other days.
+	MonthlyFallback bool `json:"monthly_fallback,omitempty"`
+}
+
+// Timing reports how long each phase of a download took, in milliseconds
+type Timing struct {
+	DownloadMS float64 `json:"download_ms"` // Upstream HTTP fetch, including rate limiter waits
+	ParseMS    float64 `json:"parse_ms"`    // Decompressing and parsing the CSV files
 }
 
 // Connector handles downloading and parsing Binance Vision trade data
 type Connector struct {
 	downloader *Downloader
 	parser     *Parser
+	config     ConnectorConfig // Copy of the configuration the connector was built with
+	cache      *resultCache    // nil when caching is disabled
+	flights    flightGroup     // Unfiltered DownloadTrades calls in progress
+	symbols    symbolIndex     // Empty until RefreshSymbols succeeds
 	mu         sync.RWMutex
+
+	refreshLimiter *rateLimiter // Paces RefreshSymbols apart from the downloads' limiter
 }
 
 // ConnectorConfig holds configuration for the connector
 type ConnectorConfig struct {
-	Timeout           time.Duration
-	MaxIdleConns      int
-	MaxConnsPerHost   int
-	IdleConnTimeout   time.Duration
-	MaxResponseSize   int64 // Maximum response size in bytes (0 = unlimited)
-	MaxTradesPerFile  int   // Maximum trades to parse per file (0 = unlimited)
+	Timeout          time.Duration // Overall download timeout (0 = no timeout)
+	MaxIdleConns     int
+	MaxConnsPerHost  int
+	IdleConnTimeout  time.Duration
+	MaxResponseSize  int64  // Maximum response size in bytes (0 = 500MB)
+	MaxTradesPerFile int    // Maximum trades to parse per file (0 = unlimited)
+	BaseURL          string // Data source base URL (defaults to https://data.binance.vision)
+	ListingURL       string // S3 bucket listing endpoint for ListAvailableDates (defaults to DefaultListingURL)
+	Market           Market // Market trade archives are downloaded from (defaults to MarketSpot)
+	PartialOK        bool   // Return trades from successfully parsed files when others fail
+	ParseConcurrency int    // Maximum CSV files parsed concurrently per archive (defaults to runtime.NumCPU)
+
+	// SortTrades stably sorts the trades of multi-file archives, which are otherwise
+	// returned in file completion order. Single-file callers can leave it off.
+	SortTrades bool
+	SortBy     TradeSortKey // Sort key when SortTrades is set (defaults to SortByTradeID)
+
+	// Deduplicate drops trades whose TradeID already appeared, which can happen when
+	// files in an archive overlap at their boundaries. The first occurrence is kept.
+	Deduplicate bool
+
+	// MaxUncompressedSize bounds the total size of an archive's CSV files once
+	// decompressed, guarding against zip bombs (0 = unlimited)
+	MaxUncompressedSize int64
+
+	// CacheSize is the number of parsed daily results DownloadTrades keeps in memory
+	// and serves again without downloading (0 = caching disabled)
+	CacheSize int
+
+	// SpoolDir is where downloaded archives are written while they are parsed, so
+	// they are not held in memory next to the parsed trades (empty = os.TempDir)
+	SpoolDir string
+
+	// DownloadRetries is the number of extra requests made for an archive whose body
+	// is cut off mid-transfer. Servers that support Range requests are only asked for
+	// the missing bytes; otherwise the download restarts (0 = no retries).
+	DownloadRetries int
+
+	// OnArchiveDownloaded is called with the number of bytes read from each archive
+	// fetched from upstream, including archives that later fail to parse or exceed
+	// MaxResponseSize. It is called concurrently and must be safe for that.
+	OnArchiveDownloaded func(bytes int64)
+
+	// CSVDelimiter is the field separator of the CSV files in archives, for mirrors
+	// that re-export the data with e.g. semicolons (0 = comma)
+	CSVDelimiter rune
+
+	// LazyQuotes tolerates stray quotes in CSV fields instead of failing the file,
+	// as csv.Reader.LazyQuotes does
+	LazyQuotes bool
+
+	// ParseErrorDetails is the number of skipped rows per archive reported in
+	// ParseStats.ErrorDetails with their line number and raw content, for debugging
+	// data issues (0 = none)
+	ParseErrorDetails int
+
+	ProxyURL string // Outbound proxy, e.g. http://proxy:3128 or socks5://proxy:1080 (empty = direct)
+
+	// ForceHTTP2 negotiates HTTP/2 with servers that support it. A custom transport
+	// otherwise speaks HTTP/1.1 only.
+	ForceHTTP2 bool
+
+	// Transport timeouts that fail stalled connections before the overall Timeout,
+	// which matters when many downloads run at once (0 = no limit)
+	ResponseHeaderTimeout time.Duration // Waiting for response headers once the request is sent
+	TLSHandshakeTimeout   time.Duration // Waiting for the TLS handshake
+	ExpectContinueTimeout time.Duration // Waiting for 100-continue before sending a body anyway
+
+	RequestsPerSecond float64 // Upstream request rate limit (0 = unlimited)
+	Burst             int     // Maximum burst of upstream requests when rate limited
+
+	// MaxConcurrentDownloads bounds the archive downloads in flight across every
+	// caller of the connector, however many requests arrive at once. Further
+	// downloads queue until a slot frees up or their context is done (0 = unlimited).
+	MaxConcurrentDownloads int
+
+	// FallbackToMonthly serves a day whose daily archive is missing (404) from the
+	// month's archive, keeping only that day's trades. Results served this way have
+	// MonthlyFallback set. The whole month counts against MaxResponseSize and
+	// MaxUncompressedSize, which the months of busy symbols exceed at their defaults.
+	FallbackToMonthly bool
 }
 
+// Transport defaults of DefaultConfig, which NewConnectorWithConfig also applies to
+// zero fields
+const (
+	defaultMaxIdleConns    = 100
+	defaultMaxConnsPerHost = 10
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
 // DefaultConfig returns a default connector configuration
 func DefaultConfig() *ConnectorConfig {
 	return &ConnectorConfig{
 		Timeout:          30 * time.Second,
-		MaxIdleConns:     100,
-		MaxConnsPerHost:  10,
-		IdleConnTimeout:  90 * time.Second,
-		MaxResponseSize:  0, // Unlimited by default
+		MaxIdleConns:     defaultMaxIdleConns,
+		MaxConnsPerHost:  defaultMaxConnsPerHost,
+		IdleConnTimeout:  defaultIdleConnTimeout,
+		MaxResponseSize:  0, // 500MB by default
 		MaxTradesPerFile: 0, // Unlimited by default
+		BaseURL:          DefaultBaseURL,
+		ListingURL:       DefaultListingURL,
+		ParseConcurrency: runtime.NumCPU(),
 	}
 }
 
+// Validate reports the first nonsensical setting of the config: a negative timeout,
+// size, count or rate, an unknown Market or SortBy, a BaseURL or ListingURL that is
+// not an http(s) URL, or an unusable ProxyURL or CSVDelimiter. Zero values are valid
+// and take their documented defaults.
+func (c *ConnectorConfig) Validate() error {
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"Timeout", c.Timeout},
+		{"IdleConnTimeout", c.IdleConnTimeout},
+		{"ResponseHeaderTimeout", c.ResponseHeaderTimeout},
+		{"TLSHandshakeTimeout", c.TLSHandshakeTimeout},
+		{"ExpectContinueTimeout", c.ExpectContinueTimeout},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			return fmt.Errorf("invalid %s %v: must not be negative", d.name, d.value)
+		}
+	}
+
+	counts := []struct {
+		name  string
+		value int64
+	}{
+		{"MaxIdleConns", int64(c.MaxIdleConns)},
+		{"MaxConnsPerHost", int64(c.MaxConnsPerHost)},
+		{"MaxResponseSize", c.MaxResponseSize},
+		{"MaxTradesPerFile", int64(c.MaxTradesPerFile)},
+		{"ParseConcurrency", int64(c.ParseConcurrency)},
+		{"MaxUncompressedSize", c.MaxUncompressedSize},
+		{"CacheSize", int64(c.CacheSize)},
+		{"DownloadRetries", int64(c.DownloadRetries)},
+		{"ParseErrorDetails", int64(c.ParseErrorDetails)},
+		{"Burst", int64(c.Burst)},
+		{"MaxConcurrentDownloads", int64(c.MaxConcurrentDownloads)},
+	}
+	for _, n := range counts {
+		if n.value < 0 {
+			return fmt.Errorf("invalid %s %d: must not be negative", n.name, n.value)
+		}
+	}
+	if c.RequestsPerSecond < 0 || math.IsNaN(c.RequestsPerSecond) || math.IsInf(c.RequestsPerSecond, 0) {
+		return fmt.Errorf("invalid RequestsPerSecond %v: must be a non-negative number", c.RequestsPerSecond)
+	}
+
+	if c.Market != "" {
+		if _, err := ParseMarket(string(c.Market)); err != nil {
+			return err
+		}
+	}
+	switch c.SortBy {
+	case "", SortByTradeID, SortByTimestamp:
+	default:
+		return fmt.Errorf("invalid SortBy %q (use %s or %s)", c.SortBy, SortByTradeID, SortByTimestamp)
+	}
+
+	for _, u := range []struct{ name, value string }{{"BaseURL", c.BaseURL}, {"ListingURL", c.ListingURL}} {
+		if u.value == "" {
+			continue
+		}
+		parsed, err := url.Parse(u.value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid %s %q: must be an http or https URL", u.name, u.value)
+		}
+	}
+	if c.ProxyURL != "" {
+		if _, err := parseProxyURL(c.ProxyURL); err != nil {
+			return err
+		}
+	}
+	if c.CSVDelimiter != 0 && !validCSVDelimiter(c.CSVDelimiter) {
+		return fmt.Errorf("invalid CSV delimiter %q", c.CSVDelimiter)
+	}
+	return nil
+}
+
 // NewConnector creates a new Binance Vision connector with default settings
 func NewConnector(timeout time.Duration) *Connector {
-	return NewConnectorWithConfig(&ConnectorConfig{
-		Timeout:         timeout,
-		MaxIdleConns:    100,
-		MaxConnsPerHost: 10,
-		IdleConnTimeout: 90 * time.Second,
+	// The default settings are always valid
+	c, _ := NewConnectorWithConfig(&ConnectorConfig{
+		Timeout:          timeout,
+		BaseURL:          DefaultBaseURL,
+		ParseConcurrency: runtime.NumCPU(),
 	})
+	return c
 }
 
-// NewConnectorWithConfig creates a new connector with custom configuration
-func NewConnectorWithConfig(config *ConnectorConfig) *Connector {
+// NewConnectorWithConfig creates a new connector with custom configuration. It
+// returns the error of config.Validate if the configuration is invalid. A zero
+// MaxIdleConns, MaxConnsPerHost or IdleConnTimeout takes the value of DefaultConfig,
+// since zero would give a transport that keeps only two idle connections per host.
+// A zero Timeout means no timeout, as for http.Client.
+func NewConnectorWithConfig(config *ConnectorConfig) (*Connector, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	defaulted := *config
+	config = &defaulted
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = defaultMaxIdleConns
+	}
+	if config.MaxConnsPerHost == 0 {
+		config.MaxConnsPerHost = defaultMaxConnsPerHost
+	}
+	if config.IdleConnTimeout == 0 {
+		config.IdleConnTimeout = defaultIdleConnTimeout
+	}
+
 	transport := &http.Transport{
 		MaxIdleConns:        config.MaxIdleConns,
 		MaxConnsPerHost:     config.MaxConnsPerHost,
@@ -75,6 +301,19 @@ func NewConnectorWithConfig(config *ConnectorConfig) *Connector {
 		DisableCompression:  false,
 		DisableKeepAlives:   false,
 		MaxIdleConnsPerHost: config.MaxConnsPerHost,
+
+		ForceAttemptHTTP2:     config.ForceHTTP2,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ExpectContinueTimeout: config.ExpectContinueTimeout,
+	}
+
+	if config.ProxyURL != "" {
+		proxyURL, err := parseProxyURL(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
 	}
 
 	client := &http.Client{
@@ -82,56 +321,366 @@ func NewConnectorWithConfig(config *ConnectorConfig) *Connector {
 		Transport: transport,
 	}
 
-	downloader := NewDownloader(client, config.Timeout)
+	downloader := NewDownloader(client, config.Timeout, config.BaseURL)
+	downloader.limiter = newRateLimiter(config.RequestsPerSecond, config.Burst)
+	downloader.slots = newDownloadSlots(config.MaxConcurrentDownloads)
+	downloader.maxSize = config.MaxResponseSize
+	downloader.spoolDir = config.SpoolDir
+	downloader.onDownload = config.OnArchiveDownloaded
+	downloader.retries = config.DownloadRetries
+	downloader.market = MarketSpot
+	if config.Market != "" {
+		market, err := ParseMarket(string(config.Market))
+		if err != nil {
+			return nil, err
+		}
+		downloader.market = market
+	}
+	if config.ListingURL != "" {
+		downloader.listingURL = strings.TrimRight(config.ListingURL, "/")
+	}
 	parser := NewParser()
+	if config.CSVDelimiter != 0 {
+		parser.comma = config.CSVDelimiter
+	}
+	parser.lazyQuotes = config.LazyQuotes
+	parser.maxErrorDetails = config.ParseErrorDetails
+	parser.partialOK = config.PartialOK
+	parser.concurrency = config.ParseConcurrency
+	if parser.concurrency <= 0 {
+		parser.concurrency = runtime.NumCPU()
+	}
+	parser.maxTrades = config.MaxTradesPerFile
+	parser.maxUncompressed = config.MaxUncompressedSize
+	// A nested archive is held in memory whole, so it gets the tighter of both limits
+	parser.maxNested = config.MaxResponseSize
+	if config.MaxUncompressedSize > 0 && (parser.maxNested <= 0 || config.MaxUncompressedSize < parser.maxNested) {
+		parser.maxNested = config.MaxUncompressedSize
+	}
+	parser.deduplicate = config.Deduplicate
+	parser.sortTrades = config.SortTrades
+	parser.sortBy = config.SortBy
+	if parser.sortBy == "" {
+		parser.sortBy = SortByTradeID
+	}
+
+	// Record the effective values of defaulted fields
+	stored := *config
+	stored.BaseURL = downloader.baseURL
+	stored.ListingURL = downloader.listingURL
+	stored.Market = downloader.market
+	if downloader.slots == nil {
+		stored.MaxConcurrentDownloads = 0
+	}
+	stored.ParseConcurrency = parser.concurrency
+	stored.SortBy = parser.sortBy
+	stored.CSVDelimiter = parser.comma
 
 	return &Connector{
 		downloader: downloader,
 		parser:     parser,
+		config:     stored,
+		cache:      newResultCache(config.CacheSize),
+
+		refreshLimiter: newRateLimiter(symbolRefreshRate, symbolRefreshConcurrency),
+	}, nil
+}
+
+// parseProxyURL parses and validates an http, https or socks5 proxy URL
+func parseProxyURL(raw string) (*url.URL, error) {
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
 	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: unsupported scheme %q (use http, https or socks5)", raw, proxyURL.Scheme)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+
+	return proxyURL, nil
+}
+
+// Config returns a copy of the connector's current configuration
+func (c *Connector) Config() ConnectorConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
 }
 
 // SetClient sets a custom HTTP client (useful for testing)
 func (c *Connector) SetClient(client *http.Client) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.downloader.SetClient(client)
+
+	// Replace rather than mutate the downloader so in-flight downloads keep a consistent client
+	downloader := c.downloader.clone()
+	downloader.SetClient(client)
+	c.downloader = downloader
+}
+
+// GetClient returns the current HTTP client
+func (c *Connector) GetClient() *http.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.downloader.Client()
 }
 
 // Client returns the current HTTP client (useful for testing)
 func (c *Connector) Client() *http.Client {
+	return c.GetClient()
+}
+
+// SetTimeout changes the HTTP timeout used for subsequent downloads
+func (c *Connector) SetTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Copy the client so callers sharing the previous one are unaffected
+	client := *c.downloader.Client()
+	client.Timeout = timeout
+
+	downloader := c.downloader.clone()
+	downloader.SetClient(&client)
+	downloader.timeout = timeout
+	c.downloader = downloader
+	c.config.Timeout = timeout
+}
+
+// getDownloader returns the current downloader under the read lock
+func (c *Connector) getDownloader() *Downloader {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.downloader.Client()
+	return c.downloader
+}
+
+// DownloadQueue returns the number of archive downloads in flight and waiting for a
+// slot under MaxConcurrentDownloads. Nothing queues when the limit is not set.
+func (c *Connector) DownloadQueue() (active, queued int) {
+	return c.getDownloader().slots.stats()
+}
+
+// CacheStats returns the hits, misses and evictions of the result cache and the number
+// of results it holds. Filtered downloads bypass the cache and are not counted.
+func (c *Connector) CacheStats() CacheStats {
+	return c.cache.stats()
 }
 
-// DownloadTrades downloads and parses trade data for a given symbol and date
+// DownloadTrades downloads and parses trade data for a given symbol and date. With
+// CacheSize set, recent results are served from memory; the Trades of a cached result
+// are shared between callers and must not be modified in place.
+//
+// Concurrent calls for the same symbol and day share a single download: callers
+// arriving while it is in flight wait for its result, which they receive with the
+// same shared Trades. Filtered calls are always downloaded on their own.
 func (c *Connector) DownloadTrades(ctx context.Context, symbol, year, month, day string) (*DownloadResult, error) {
+	y, m, d := formatDate(year, month, day)
+	key := cacheKey(symbol, fmt.Sprintf("%s-%s-%s", y, m, d))
+	// A filtered result holds only part of the day, so it is neither served from nor
+	// added to the cache
+	filtered := hasTradeFilter(ctx)
+	if !filtered {
+		if result, ok := c.cache.get(key); ok {
+			result.CacheHit = true
+			return result, nil
+		}
+	}
+	if err := c.checkSymbolIndex(symbol, fmt.Sprintf("%s-%s-%s", y, m, d)); err != nil {
+		return nil, err
+	}
+	downloader := c.getDownloader()
+	if filtered {
+		return c.downloadTrades(ctx, downloader, symbol, year, month, day)
+	}
+
+	flightKey := string(downloader.market) + "|" + key
+	for {
+		result, shared, err := c.flights.do(ctx, flightKey, func() (*DownloadResult, error) {
+			result, err := c.downloadTrades(ctx, downloader, symbol, year, month, day)
+			if err == nil {
+				c.cache.add(key, result)
+			}
+			return result, err
+		})
+		// The caller that ran the download may have given up, which says nothing
+		// about this caller, so it downloads again while its context allows
+		if shared && ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Every caller gets its own copy, as with cached results
+		copied := *result
+		return &copied, nil
+	}
+}
+
+// downloadTrades downloads and parses the trades of one day with downloader
+func (c *Connector) downloadTrades(ctx context.Context, downloader *Downloader, symbol, year, month, day string) (*DownloadResult, error) {
 	// Download the zip file
-	zipData, err := c.downloader.DownloadToMemory(ctx, symbol, year, month, day)
+	downloadStart := time.Now()
+	ctx, archive, monthly, err := c.spoolTrades(ctx, downloader, symbol, year, month, day)
 	if err != nil {
 		return nil, err
 	}
+	defer archive.Close()
+	downloadTime := time.Since(downloadStart)
 
 	// Parse the zip file
-	trades, err := c.parser.ParseZip(zipData)
+	parseStart := time.Now()
+	parsed, err := c.parser.ParseZipReader(ctx, archive, archive.size)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse zip file: %w", err)
 	}
+	parseTime := time.Since(parseStart)
 
 	// Format dates with zero-padding for result
 	year, month, day = formatDate(year, month, day)
 
+	result := &DownloadResult{
+		Symbol:            symbol,
+		Date:              fmt.Sprintf("%s-%s-%s", year, month, day),
+		TradeCount:        len(parsed.Trades),
+		Trades:            parsed.Trades,
+		Warnings:          parsed.Warnings,
+		ParseStats:        parsed.Stats,
+		Truncated:         parsed.Stats.TruncatedFiles > 0,
+		DuplicatesRemoved: parsed.DuplicatesRemoved,
+		Timing: Timing{
+			DownloadMS: DurationMS(downloadTime),
+			ParseMS:    DurationMS(parseTime),
+		},
+		CompressedBytes:   archive.size,
+		UncompressedBytes: parsed.UncompressedBytes,
+		SourceURL:         downloader.TradesURL(symbol, year, month, day),
+		PublishedAt:       archive.lastModified,
+		MonthlyFallback:   monthly,
+	}
+	if monthly {
+		result.SourceURL = downloader.MonthlyTradesURL(symbol, year, month)
+	}
+
+	result.classifyEmpty()
+
+	return result, nil
+}
+
+// spoolTrades spools the trade archive of a day. When it is missing and
+// FallbackToMonthly is set, the month's archive is spooled instead, monthly is set
+// and the returned context also keeps only the day's trades. If the monthly archive
+// is missing as well, the daily archive's error is returned.
+func (c *Connector) spoolTrades(ctx context.Context, downloader *Downloader, symbol, year, month, day string) (context.Context, *spooledArchive, bool, error) {
+	archive, err := downloader.spoolURL(ctx, downloader.TradesURL(symbol, year, month, day))
+	if err == nil || !errors.Is(err, ErrArchiveNotFound) || !c.Config().FallbackToMonthly {
+		return ctx, archive, false, err
+	}
+
+	year, month, day = formatDate(year, month, day)
+	date, parseErr := time.Parse("2006-01-02", fmt.Sprintf("%s-%s-%s", year, month, day))
+	if parseErr != nil {
+		return ctx, nil, false, err
+	}
+	archive, monthlyErr := downloader.spoolURL(ctx, downloader.MonthlyTradesURL(symbol, year, month))
+	if monthlyErr != nil {
+		if errors.Is(monthlyErr, ErrArchiveNotFound) {
+			return ctx, nil, false, err
+		}
+		return ctx, nil, false, monthlyErr
+	}
+	ctx = ContextWithTradeFilter(ctx, AllOf(tradeFilterFromContext(ctx), tradesOnDay(date)))
+	return ctx, archive, true, nil
+}
+
+// StreamTrades downloads trade data like DownloadTrades but passes each trade to emit
+// as soon as it is parsed. The returned result has TradeCount and VWAP set but no Trades.
+// Sorting and deduplication are not applied. If emit returns an error, parsing
+// stops and that error is returned.
+func (c *Connector) StreamTrades(ctx context.Context, symbol, year, month, day string, emit func(Trade) error) (*DownloadResult, error) {
+	y, m, d := formatDate(year, month, day)
+	if err := c.checkSymbolIndex(symbol, fmt.Sprintf("%s-%s-%s", y, m, d)); err != nil {
+		return nil, err
+	}
+
+	downloader := c.getDownloader()
+	downloadStart := time.Now()
+	ctx, archive, monthly, err := c.spoolTrades(ctx, downloader, symbol, y, m, d)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	result, err := c.streamArchive(ctx, c.parser, downloader, archive, symbol, y, m, d, time.Since(downloadStart), emit)
+	if err == nil && monthly {
+		result.MonthlyFallback = true
+		result.SourceURL = downloader.MonthlyTradesURL(symbol, y, m)
+	}
+	return result, err
+}
+
+// streamArchive parses a spooled trade archive with parser for StreamTrades, passing
+// each trade to emit, and builds the result for the zero-padded date from it
+func (c *Connector) streamArchive(ctx context.Context, parser *Parser, downloader *Downloader, archive *spooledArchive, symbol, year, month, day string, downloadTime time.Duration, emit func(Trade) error) (*DownloadResult, error) {
+	count := 0
+	var vwap vwapAccumulator
+	parseStart := time.Now()
+	parsed, err := parser.StreamZipReader(ctx, archive, archive.size, func(trade Trade) error {
+		count++
+		vwap.add(trade)
+		return emit(trade)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+	parseTime := time.Since(parseStart)
+
 	result := &DownloadResult{
 		Symbol:     symbol,
 		Date:       fmt.Sprintf("%s-%s-%s", year, month, day),
-		TradeCount: len(trades),
-		Trades:     trades,
+		TradeCount: count,
+		Warnings:   parsed.Warnings,
+		ParseStats: parsed.Stats,
+		Truncated:  parsed.Stats.TruncatedFiles > 0,
+		VWAP:       vwap.value(),
+		Timing: Timing{
+			DownloadMS: DurationMS(downloadTime),
+			ParseMS:    DurationMS(parseTime),
+		},
+		CompressedBytes:   archive.size,
+		UncompressedBytes: parsed.UncompressedBytes,
+		SourceURL:         downloader.TradesURL(symbol, year, month, day),
+		PublishedAt:       archive.lastModified,
 	}
-
+	result.classifyEmpty()
 	return result, nil
 }
 
+// classifyEmpty sets EmptyDay or AllRowsSkipped on a result without trades. Neither is
+// set when files failed under PartialOK, since their rows were never counted, or when
+// a trade filter or trade ID range dropped trades.
+func (r *DownloadResult) classifyEmpty() {
+	if r.TradeCount > 0 || len(r.Warnings) > 0 || r.ParseStats.Filtered > 0 || r.ParseStats.StoppedFiles > 0 {
+		return
+	}
+	r.EmptyDay = r.ParseStats.Skipped() == 0
+	r.AllRowsSkipped = !r.EmptyDay
+}
+
+// TradesURL returns the archive URL DownloadTrades fetches for the given symbol and date
+func (c *Connector) TradesURL(symbol, year, month, day string) string {
+	return c.getDownloader().TradesURL(symbol, year, month, day)
+}
+
+// DurationMS converts a duration to fractional milliseconds
+func DurationMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
 // formatDate ensures date components are zero-padded
 func formatDate(year, month, day string) (string, string, string) {
 	// Ensure zero-padding
@@ -143,8 +692,3 @@ func formatDate(year, month, day string) (string, string, string) {
 	}
 	return year, month, day
 }
-
-// ToJSON converts DownloadResult to JSON
-func (r *DownloadResult) ToJSON() ([]byte, error) {
-	return nil, fmt.Errorf("not implemented - use json.Marshal instead")
-}
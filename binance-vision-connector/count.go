@@ -0,0 +1,51 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+)
+
+// TradeCountResult reports how many trades a daily archive contains
+type TradeCountResult struct {
+	Symbol     string     `json:"symbol"`
+	Date       string     `json:"date"`
+	TradeCount int        `json:"trade_count"`
+	Warnings   []string   `json:"warnings,omitempty"` // Per-file failures when PartialOK is set
+	ParseStats ParseStats `json:"parse_stats"`
+	SourceURL  string     `json:"source_url,omitempty"`
+}
+
+// CountTrades downloads the trade archive for a symbol and date and counts its trades
+// without parsing them, so memory use stays flat however busy the day was. Unlike
+// DownloadTrades, MaxTradesPerFile does not cap the count and results are not cached.
+func (c *Connector) CountTrades(ctx context.Context, symbol, year, month, day string) (*TradeCountResult, error) {
+	y, m, d := formatDate(year, month, day)
+	if err := c.checkSymbolIndex(symbol, fmt.Sprintf("%s-%s-%s", y, m, d)); err != nil {
+		return nil, err
+	}
+
+	downloader := c.getDownloader()
+	url := downloader.TradesURL(symbol, year, month, day)
+
+	archive, err := downloader.spoolURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	count, parsed, err := c.parser.CountZipReader(ctx, archive, archive.size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+
+	year, month, day = formatDate(year, month, day)
+
+	return &TradeCountResult{
+		Symbol:     symbol,
+		Date:       fmt.Sprintf("%s-%s-%s", year, month, day),
+		TradeCount: count,
+		Warnings:   parsed.Warnings,
+		ParseStats: parsed.Stats,
+		SourceURL:  url,
+	}, nil
+}
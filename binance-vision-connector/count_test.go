@@ -0,0 +1,48 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCountTrades(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01-a.csv": "id,price,qty,quote_qty,time,is_buyer_maker,is_best_match\n" +
+			"1,0.5,10,5,1735430400000,true,true\n" +
+			"2,0.5,10,5,1735430400001,false,true\n" +
+			"3,0.5\n",
+		"BTCUSDT-trades-2025-01-01-b.csv": "4,0.5,10,5,1735430400002,true,true\n" +
+			"5,0.5,10,5,1735430400003,true,true\n",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.MaxTradesPerFile = 1
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	result, err := c.CountTrades(context.Background(), "BTCUSDT", "2025", "1", "1")
+	if err != nil {
+		t.Fatalf("CountTrades() error = %v", err)
+	}
+
+	// The header and the short row are not counted, and MaxTradesPerFile is ignored
+	if result.TradeCount != 4 {
+		t.Errorf("TradeCount = %d, want 4", result.TradeCount)
+	}
+	if result.ParseStats.SkippedShort != 1 {
+		t.Errorf("SkippedShort = %d, want 1", result.ParseStats.SkippedShort)
+	}
+	if result.Date != "2025-01-01" || result.SourceURL != c.TradesURL("BTCUSDT", "2025", "01", "01") {
+		t.Errorf("Date = %q, SourceURL = %q", result.Date, result.SourceURL)
+	}
+}
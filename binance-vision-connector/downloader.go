@@ -0,0 +1,298 @@
+package binancevisionconnector
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxDownloadSize limits the size of downloaded archives when no maximum is configured (500MB)
+const maxDownloadSize = 500 * 1024 * 1024
+
+// DefaultBaseURL is the default Binance Vision data source
+const DefaultBaseURL = "https://data.binance.vision"
+
+// Market selects the Binance Vision market trade archives are downloaded from
+type Market string
+
+const (
+	MarketSpot Market = "spot"
+	// MarketCOINM is the COIN-M futures market, whose symbols name a contract, such as
+	// BTCUSD_PERP for the perpetual or BTCUSD_240628 for a quarterly contract
+	MarketCOINM Market = "cm"
+)
+
+// tradesPrefix returns the bucket prefix of the market's daily trade archives
+func (m Market) tradesPrefix() string {
+	if m == MarketCOINM {
+		return "data/futures/cm/daily/trades/"
+	}
+	return "data/spot/daily/trades/"
+}
+
+// monthlyTradesPrefix returns the bucket prefix of the market's monthly trade archives
+func (m Market) monthlyTradesPrefix() string {
+	return strings.Replace(m.tradesPrefix(), "/daily/", "/monthly/", 1)
+}
+
+// ParseMarket parses a market name: spot, or cm (also coinm or coin-m) for COIN-M
+// futures. An empty name is spot.
+func ParseMarket(name string) (Market, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "spot":
+		return MarketSpot, nil
+	case "cm", "coinm", "coin-m":
+		return MarketCOINM, nil
+	default:
+		return "", fmt.Errorf("invalid market %q (use spot or cm)", name)
+	}
+}
+
+// Downloader handles fetching trade archives from Binance Vision
+type Downloader struct {
+	client     *http.Client
+	timeout    time.Duration
+	baseURL    string
+	listingURL string         // Bucket listing endpoint used by ListAvailableDates
+	market     Market         // Market of the trade archives (empty = MarketSpot)
+	limiter    *rateLimiter   // Shared across clones; nil = unlimited
+	slots      *downloadSlots // Shared across clones; nil = unlimited
+	maxSize    int64          // Maximum archive size in bytes (0 = maxDownloadSize)
+	spoolDir   string         // Directory for temporary archive files (empty = os.TempDir)
+	onDownload func(int64)    // Called with the bytes read from each archive body (nil = none)
+	retries    int            // Extra attempts for archive bodies cut off mid-transfer
+}
+
+// NewDownloader creates a new downloader using the given HTTP client.
+// An empty baseURL falls back to DefaultBaseURL.
+func NewDownloader(client *http.Client, timeout time.Duration, baseURL string) *Downloader {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Downloader{
+		client:     client,
+		timeout:    timeout,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		listingURL: DefaultListingURL,
+	}
+}
+
+// SetClient sets a custom HTTP client
+func (d *Downloader) SetClient(client *http.Client) {
+	d.client = client
+}
+
+// Client returns the current HTTP client
+func (d *Downloader) Client() *http.Client {
+	return d.client
+}
+
+// clone returns a shallow copy of the downloader
+func (d *Downloader) clone() *Downloader {
+	copied := *d
+	return &copied
+}
+
+// Download issues the HTTP request for the trade archive and returns the response.
+// The caller is responsible for closing the response body.
+func (d *Downloader) Download(ctx context.Context, symbol, year, month, day string) (*http.Response, error) {
+	return d.DownloadURL(ctx, d.TradesURL(symbol, year, month, day))
+}
+
+// TradesURL returns the archive URL for the given symbol and date
+func (d *Downloader) TradesURL(symbol, year, month, day string) string {
+	year, month, day = formatDate(year, month, day)
+	return d.URL(fmt.Sprintf("/%s%s/%s-trades-%s-%s-%s.zip",
+		d.market.tradesPrefix(), symbol, symbol, year, month, day))
+}
+
+// MonthlyTradesURL returns the URL of the archive holding every trade of the given month
+func (d *Downloader) MonthlyTradesURL(symbol, year, month string) string {
+	year, month, _ = formatDate(year, month, "")
+	return d.URL(fmt.Sprintf("/%s%s/%s-trades-%s-%s.zip",
+		d.market.monthlyTradesPrefix(), symbol, symbol, year, month))
+}
+
+// URL returns the absolute URL for the given path on the configured data source
+func (d *Downloader) URL(path string) string {
+	return d.baseURL + path
+}
+
+// DownloadURL issues a GET request for the given archive URL and returns the response.
+// The caller is responsible for closing the response body.
+func (d *Downloader) DownloadURL(ctx context.Context, url string) (*http.Response, error) {
+	return d.get(ctx, url, nil)
+}
+
+// get issues a GET request with the given extra headers. When header asks for a
+// Range, 206 Partial Content is accepted as well as 200.
+func (d *Downloader) get(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	if err := d.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", "binance-vision-connector/1.0")
+	// Accept-Encoding is left to the transport, which then decompresses gzip transparently.
+	// Setting it here would disable that; readArchive still decodes any encoding that gets through.
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		return nil, &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download file: %w (status code %d)", ErrArchiveNotFound, resp.StatusCode)
+	}
+
+	partial := resp.StatusCode == http.StatusPartialContent && header.Get("Range") != ""
+	if resp.StatusCode != http.StatusOK && !partial {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download file: status code %d", resp.StatusCode)
+	}
+
+	// During maintenance the data source serves an HTML page with a 200 status
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/html" {
+		resp.Body.Close()
+		return nil, &MaintenanceError{
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			Detail:     "served text/html instead of the archive",
+		}
+	}
+
+	return resp, nil
+}
+
+// Head issues a HEAD request for the given archive URL and returns the response.
+// Unlike DownloadURL, non-200 responses are returned to the caller without an error.
+func (d *Downloader) Head(ctx context.Context, url string) (*http.Response, error) {
+	if err := d.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "binance-vision-connector/1.0")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check file: %w", err)
+	}
+	resp.Body.Close()
+
+	return resp, nil
+}
+
+// DownloadToMemory downloads the trade archive and returns its contents
+func (d *Downloader) DownloadToMemory(ctx context.Context, symbol, year, month, day string) ([]byte, error) {
+	return d.DownloadURLToMemory(ctx, d.TradesURL(symbol, year, month, day))
+}
+
+// DownloadURLToMemory downloads the archive at url and returns its contents
+func (d *Downloader) DownloadURLToMemory(ctx context.Context, url string) ([]byte, error) {
+	if err := d.slots.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer d.slots.release()
+
+	resp, err := d.DownloadURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return d.readArchive(resp)
+}
+
+// readArchive reads the response body into memory, failing with ErrResponseTooLarge
+// if the archive is larger than the configured maximum size
+func (d *Downloader) readArchive(resp *http.Response) ([]byte, error) {
+	body, limit, err := d.archiveBody(resp, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	// Read one byte past the limit to detect bodies that would be truncated
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	d.recordDownload(int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip file: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: body exceeds limit of %d bytes", ErrResponseTooLarge, limit)
+	}
+
+	return data, nil
+}
+
+// recordDownload reports bytes read from an archive body to the download hook
+func (d *Downloader) recordDownload(n int64) {
+	if d.onDownload != nil && n > 0 {
+		d.onDownload(n)
+	}
+}
+
+// archiveBody returns the decoded response body and the maximum number of bytes that
+// may still be read from it, given that offset bytes of the archive are already
+// stored. It fails with ErrResponseTooLarge when the announced length is already over the limit.
+func (d *Downloader) archiveBody(resp *http.Response, offset int64) (io.ReadCloser, int64, error) {
+	limit := d.maxSize
+	if limit <= 0 {
+		limit = maxDownloadSize
+	}
+
+	// Fail fast when the server announces the size up front
+	if resp.ContentLength >= 0 && offset+resp.ContentLength > limit {
+		return nil, 0, fmt.Errorf("%w: content length %d exceeds limit of %d bytes", ErrResponseTooLarge, offset+resp.ContentLength, limit)
+	}
+
+	body, err := decodeBody(resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, limit - offset, nil
+}
+
+// decodeBody returns the response body decoded according to its Content-Encoding.
+// Bodies already decompressed by the transport are returned as-is.
+func decodeBody(resp *http.Response) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+		return io.NopCloser(resp.Body), nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode gzip response: %w", err)
+		}
+		return gz, nil
+	case "deflate":
+		zr, err := zlib.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode deflate response: %w", err)
+		}
+		return zr, nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", resp.Header.Get("Content-Encoding"))
+	}
+}
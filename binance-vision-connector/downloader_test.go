@@ -0,0 +1,123 @@
+package binancevisionconnector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloaderMaxResponseSize(t *testing.T) {
+	body := strings.Repeat("x", 100)
+
+	tests := []struct {
+		name    string
+		maxSize int64
+		chunked bool // Omit Content-Length so only the body read can detect the overflow
+		wantErr bool
+	}{
+		{"under limit", 200, false, false},
+		{"exactly at limit", 100, false, false},
+		{"content length over limit", 50, false, true},
+		{"chunked body over limit", 50, true, true},
+		{"chunked body at limit", 100, true, false},
+		{"no limit configured", 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.chunked {
+					w.Write([]byte(body[:10]))
+					w.(http.Flusher).Flush()
+					w.Write([]byte(body[10:]))
+					return
+				}
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			d := NewDownloader(server.Client(), 5*time.Second, server.URL)
+			d.maxSize = tt.maxSize
+
+			data, err := d.DownloadURLToMemory(context.Background(), server.URL+"/archive.zip")
+			if tt.wantErr {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Fatalf("DownloadURLToMemory() error = %v, want ErrResponseTooLarge", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadURLToMemory() error = %v", err)
+			}
+			if len(data) != len(body) {
+				t.Errorf("DownloadURLToMemory() read %d bytes, want %d", len(data), len(body))
+			}
+		})
+	}
+}
+
+func TestDownloaderContentEncoding(t *testing.T) {
+	archive := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n",
+	})
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write(archive)
+	gw.Close()
+
+	var deflated bytes.Buffer
+	zw := zlib.NewWriter(&deflated)
+	zw.Write(archive)
+	zw.Close()
+
+	tests := []struct {
+		name               string
+		encoding           string
+		body               []byte
+		disableCompression bool // Keep the transport from decoding so readArchive must
+	}{
+		{"identity", "", archive, false},
+		{"gzip decoded by transport", "gzip", gzipped.Bytes(), false},
+		{"gzip decoded explicitly", "gzip", gzipped.Bytes(), true},
+		{"deflate decoded explicitly", "deflate", deflated.Bytes(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &http.Transport{DisableCompression: tt.disableCompression}}
+			d := NewDownloader(client, 5*time.Second, server.URL)
+
+			data, err := d.DownloadURLToMemory(context.Background(), server.URL+"/archive.zip")
+			if err != nil {
+				t.Fatalf("DownloadURLToMemory() error = %v", err)
+			}
+			if !bytes.Equal(data, archive) {
+				t.Fatalf("DownloadURLToMemory() returned %d bytes, want the %d byte archive", len(data), len(archive))
+			}
+
+			parsed, err := NewParser().ParseZip(context.Background(), data)
+			if err != nil {
+				t.Fatalf("ParseZip() error = %v", err)
+			}
+			if len(parsed.Trades) != 1 {
+				t.Errorf("ParseZip() parsed %d trades, want 1", len(parsed.Trades))
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// downloadSlots bounds the number of archive downloads in flight across every caller
+// of a connector. Downloads beyond the limit queue for a slot in arrival order.
+type downloadSlots struct {
+	slots  chan struct{}
+	queued atomic.Int64 // Downloads waiting for a slot
+}
+
+// newDownloadSlots creates a pool of max slots. It returns nil (unlimited) when max
+// is not positive.
+func newDownloadSlots(max int) *downloadSlots {
+	if max <= 0 {
+		return nil
+	}
+	return &downloadSlots{slots: make(chan struct{}, max)}
+}
+
+// acquire blocks until a slot is free or the context is done. A nil pool never blocks.
+func (s *downloadSlots) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	s.queued.Add(1)
+	defer s.queued.Add(-1)
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire
+func (s *downloadSlots) release() {
+	if s != nil {
+		<-s.slots
+	}
+}
+
+// stats returns the number of downloads holding a slot and waiting for one
+func (s *downloadSlots) stats() (active, queued int) {
+	if s == nil {
+		return 0, 0
+	}
+	return len(s.slots), int(s.queued.Load())
+}
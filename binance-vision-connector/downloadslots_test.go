@@ -0,0 +1,107 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadSlotsNilIsUnlimited(t *testing.T) {
+	slots := newDownloadSlots(0)
+	if slots != nil {
+		t.Fatalf("newDownloadSlots(0) = %v, want nil", slots)
+	}
+	if err := slots.acquire(context.Background()); err != nil {
+		t.Errorf("acquire() on nil slots error = %v", err)
+	}
+	slots.release()
+	if active, queued := slots.stats(); active != 0 || queued != 0 {
+		t.Errorf("stats() = %d, %d, want 0, 0", active, queued)
+	}
+}
+
+func TestDownloadSlotsQueueRespectsContext(t *testing.T) {
+	slots := newDownloadSlots(1)
+	if err := slots.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- slots.acquire(ctx) }()
+
+	time.Sleep(5 * time.Millisecond)
+	if active, queued := slots.stats(); active != 1 || queued != 1 {
+		t.Errorf("stats() while waiting = %d, %d, want 1, 1", active, queued)
+	}
+	if err := <-done; err != context.DeadlineExceeded {
+		t.Errorf("acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, queued := slots.stats(); queued != 0 {
+		t.Errorf("queued after timeout = %d, want 0", queued)
+	}
+
+	slots.release()
+	if err := slots.acquire(context.Background()); err != nil {
+		t.Errorf("acquire() after release error = %v", err)
+	}
+}
+
+func TestConnector_MaxConcurrentDownloads(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n",
+	})
+
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.MaxConcurrentDownloads = 2
+	connector, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(day int) {
+			defer wg.Done()
+			_, err := connector.DownloadTrades(context.Background(), "BTCUSDT", "2025", "01", "0"+string(rune('1'+day)))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("DownloadTrades() error = %v", err)
+		}
+	}
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrent downloads = %d, want 2", got)
+	}
+	if active, queued := connector.DownloadQueue(); active != 0 || queued != 0 {
+		t.Errorf("DownloadQueue() after downloads = %d, %d, want 0, 0", active, queued)
+	}
+}
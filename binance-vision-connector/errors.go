@@ -0,0 +1,90 @@
+package binancevisionconnector
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRateLimited is returned when the data source responds with 429 Too Many Requests
+var ErrRateLimited = errors.New("rate limited by data source")
+
+// ErrResponseTooLarge is returned when an archive exceeds the configured maximum response size
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ErrUncompressedTooLarge is returned when the CSV files in an archive expand to more
+// than the configured maximum uncompressed size, as a highly compressible zip bomb would
+var ErrUncompressedTooLarge = errors.New("uncompressed archive too large")
+
+// ErrArchiveNotFound is returned when the data source has no archive at the requested
+// URL, typically because the symbol did not trade on that date
+var ErrArchiveNotFound = errors.New("archive not found")
+
+// ErrNotAZipFile is returned when a downloaded archive is not a zip file, such as an
+// HTML maintenance page served with a 200 status
+var ErrNotAZipFile = errors.New("not a zip file")
+
+// ErrUpstreamMaintenance is returned when the data source answers with an HTML page
+// instead of the archive, as Binance Vision does with a 200 status during maintenance
+var ErrUpstreamMaintenance = errors.New("data source is under maintenance")
+
+// ErrNoCSVFiles is returned when an archive holds no CSV files, such as one that only
+// contains checksums or documentation. The error lists the files that were found.
+var ErrNoCSVFiles = errors.New("no CSV files found")
+
+// RateLimitError wraps ErrRateLimited with the delay requested by the data source
+type RateLimitError struct {
+	RetryAfter time.Duration // Zero if the data source did not send Retry-After
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v (retry after %v)", ErrRateLimited, e.RetryAfter)
+	}
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// MaintenanceError wraps ErrUpstreamMaintenance with the delay requested by the data
+// source. It also matches ErrNotAZipFile, as the page is not an archive either.
+type MaintenanceError struct {
+	RetryAfter time.Duration // Zero if the data source did not send Retry-After
+	Detail     string        // How the page was recognized
+}
+
+func (e *MaintenanceError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrUpstreamMaintenance, e.Detail)
+}
+
+func (e *MaintenanceError) Unwrap() []error {
+	return []error{ErrUpstreamMaintenance, ErrNotAZipFile}
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,125 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TradeFilter reports whether a parsed trade should be kept
+type TradeFilter func(trade *Trade) bool
+
+// Trade filters by aggressor side. On a buyer-maker trade the seller took liquidity,
+// so MakerTrades keeps the aggressive sells and TakerTrades the aggressive buys.
+var (
+	MakerTrades TradeFilter = func(trade *Trade) bool { return trade.IsBuyerMaker }
+	TakerTrades TradeFilter = func(trade *Trade) bool { return !trade.IsBuyerMaker }
+)
+
+// MinQuoteQuantity returns a filter keeping trades with a QuoteQuantity of at least
+// threshold, such as the large trades of a day
+func MinQuoteQuantity(threshold float64) TradeFilter {
+	return func(trade *Trade) bool { return trade.QuoteQuantity >= threshold }
+}
+
+// AllOf returns a filter keeping the trades that every non-nil filter keeps, or nil
+// when all filters are nil
+func AllOf(filters ...TradeFilter) TradeFilter {
+	var set []TradeFilter
+	for _, keep := range filters {
+		if keep != nil {
+			set = append(set, keep)
+		}
+	}
+	switch len(set) {
+	case 0:
+		return nil
+	case 1:
+		return set[0]
+	}
+	return func(trade *Trade) bool {
+		for _, keep := range set {
+			if !keep(trade) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// microsecondTimestamp is the smallest Timestamp read as microseconds rather than
+// milliseconds. Spot archives switched to microseconds in 2025; a millisecond
+// timestamp this large would lie past the year 5000.
+const microsecondTimestamp = 1e14
+
+// timestampMillis returns a Timestamp in milliseconds, whichever unit it is in
+func timestampMillis(ts int64) int64 {
+	if ts >= microsecondTimestamp {
+		return ts / 1000
+	}
+	return ts
+}
+
+// tradesOnDay returns a filter keeping trades whose Timestamp, in milliseconds or
+// microseconds, falls on the UTC day starting at day
+func tradesOnDay(day time.Time) TradeFilter {
+	from, to := day.UnixMilli(), day.AddDate(0, 0, 1).UnixMilli()
+	return func(trade *Trade) bool {
+		ts := timestampMillis(trade.Timestamp)
+		return ts >= from && ts < to
+	}
+}
+
+type tradeFilterKey struct{}
+
+// ContextWithTradeFilter returns a copy of ctx carrying keep, which the parser applies
+// to every trade of downloads made with that context before collecting or emitting
+// it, so dropped trades are never materialized. Dropped trades are counted in
+// ParseStats.Filtered and do not count towards MaxTradesPerFile. Filtered downloads
+// bypass the result cache. keep is called concurrently for different files.
+func ContextWithTradeFilter(ctx context.Context, keep TradeFilter) context.Context {
+	return context.WithValue(ctx, tradeFilterKey{}, keep)
+}
+
+// tradeFilterFromContext returns the filter stored by ContextWithTradeFilter, or nil
+func tradeFilterFromContext(ctx context.Context) TradeFilter {
+	keep, _ := ctx.Value(tradeFilterKey{}).(TradeFilter)
+	return keep
+}
+
+// TradeIDRange selects the trades whose TradeID lies between From and To, inclusive
+type TradeIDRange struct {
+	From int64
+	To   int64
+}
+
+type tradeIDRangeKey struct{}
+
+// ContextWithTradeIDRange returns a copy of ctx carrying ids, which the parser applies
+// to every trade of downloads made with that context like a ContextWithTradeFilter
+// filter. Trade IDs increase through each CSV file, so a file is no longer read once
+// a trade past ids.To appears; such files are counted in ParseStats.StoppedFiles.
+// Trades before ids.From are counted in ParseStats.Filtered.
+func ContextWithTradeIDRange(ctx context.Context, ids TradeIDRange) context.Context {
+	return context.WithValue(ctx, tradeIDRangeKey{}, ids)
+}
+
+// tradeIDRangeFromContext returns the range stored by ContextWithTradeIDRange
+func tradeIDRangeFromContext(ctx context.Context) (TradeIDRange, bool) {
+	ids, ok := ctx.Value(tradeIDRangeKey{}).(TradeIDRange)
+	return ids, ok
+}
+
+// hasTradeFilter reports whether downloads made with ctx return only some trades
+func hasTradeFilter(ctx context.Context) bool {
+	_, ranged := tradeIDRangeFromContext(ctx)
+	return ranged || tradeFilterFromContext(ctx) != nil
+}
+
+// errRecordFiltered is returned by a readCSVRows emit function to drop a record
+// without stopping, so it is counted as filtered rather than emitted
+var errRecordFiltered = errors.New("record filtered")
+
+// errStopRows is returned by a readCSVRows emit function to stop reading the file
+// without an error, when no later row can be wanted
+var errStopRows = errors.New("stop reading rows")
@@ -0,0 +1,115 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextWithTradeFilter(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n" +
+			"2,0.5,10,5,1735430400001,false,true\n" +
+			"3,0.5,10,5,1735430400002,true,true\n" +
+			"4,0.5,10,5,1735430400003,false,true\n" +
+			"5,0.5,10,5,1735430400004,false,true\n",
+	})
+
+	tests := []struct {
+		name         string
+		keep         TradeFilter
+		maxTrades    int
+		wantIDs      []int64
+		wantFiltered int
+	}{
+		{"no filter", nil, 0, []int64{1, 2, 3, 4, 5}, 0},
+		{"maker trades", MakerTrades, 0, []int64{1, 3}, 3},
+		{"taker trades", TakerTrades, 0, []int64{2, 4, 5}, 2},
+		{"filtered trades do not count towards the limit", TakerTrades, 2, []int64{2, 4}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.keep != nil {
+				ctx = ContextWithTradeFilter(ctx, tt.keep)
+			}
+			parser := NewParser()
+			parser.maxTrades = tt.maxTrades
+
+			parsed, err := parser.ParseZip(ctx, zipData)
+			if err != nil {
+				t.Fatalf("ParseZip() error = %v", err)
+			}
+			var streamed []int64
+			streamResult, err := parser.StreamZip(ctx, zipData, func(trade Trade) error {
+				streamed = append(streamed, trade.TradeID)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("StreamZip() error = %v", err)
+			}
+
+			if len(parsed.Trades) != len(tt.wantIDs) || len(streamed) != len(tt.wantIDs) {
+				t.Fatalf("got %d parsed and %d streamed trades, want %d", len(parsed.Trades), len(streamed), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if parsed.Trades[i].TradeID != id || streamed[i] != id {
+					t.Errorf("trade %d: parsed ID %d, streamed ID %d, want %d", i, parsed.Trades[i].TradeID, streamed[i], id)
+				}
+			}
+			if parsed.Stats.Filtered != tt.wantFiltered || streamResult.Stats.Filtered != tt.wantFiltered {
+				t.Errorf("Filtered = %d parsed, %d streamed, want %d", parsed.Stats.Filtered, streamResult.Stats.Filtered, tt.wantFiltered)
+			}
+		})
+	}
+}
+
+func TestContextWithTradeIDRange(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n" +
+			"2,0.5,10,5,1735430400001,false,true\n" +
+			"3,0.5,10,5,1735430400002,true,true\n" +
+			"4,0.5,10,5,1735430400003,false,true\n" +
+			"5,0.5,10,5,1735430400004,false,true\n",
+	})
+
+	tests := []struct {
+		name         string
+		ids          TradeIDRange
+		keep         TradeFilter
+		wantIDs      []int64
+		wantFiltered int
+		wantStopped  int
+	}{
+		{"middle", TradeIDRange{From: 2, To: 4}, nil, []int64{2, 3, 4}, 1, 1},
+		{"through the end", TradeIDRange{From: 4, To: 10}, nil, []int64{4, 5}, 3, 0},
+		{"before the first trade", TradeIDRange{From: -5, To: 0}, nil, nil, 0, 1},
+		{"with a filter", TradeIDRange{From: 1, To: 3}, MakerTrades, []int64{1, 3}, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ContextWithTradeIDRange(context.Background(), tt.ids)
+			if tt.keep != nil {
+				ctx = ContextWithTradeFilter(ctx, tt.keep)
+			}
+
+			parsed, err := NewParser().ParseZip(ctx, zipData)
+			if err != nil {
+				t.Fatalf("ParseZip() error = %v", err)
+			}
+			if len(parsed.Trades) != len(tt.wantIDs) {
+				t.Fatalf("got %d trades, want %d", len(parsed.Trades), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if parsed.Trades[i].TradeID != id {
+					t.Errorf("trade %d: ID %d, want %d", i, parsed.Trades[i].TradeID, id)
+				}
+			}
+			if parsed.Stats.Filtered != tt.wantFiltered || parsed.Stats.StoppedFiles != tt.wantStopped {
+				t.Errorf("Filtered = %d, StoppedFiles = %d, want %d, %d",
+					parsed.Stats.Filtered, parsed.Stats.StoppedFiles, tt.wantFiltered, tt.wantStopped)
+			}
+		})
+	}
+}
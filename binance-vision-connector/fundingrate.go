@@ -0,0 +1,139 @@
+package binancevisionconnector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// FundingRate represents a single funding event of a USDⓈ-M perpetual contract
+type FundingRate struct {
+	Symbol      string  `json:"symbol"`
+	FundingTime int64   `json:"funding_time"`
+	FundingRate float64 `json:"funding_rate"`
+	MarkPrice   float64 `json:"mark_price"`
+}
+
+// FundingRateResult contains the downloaded funding rate data
+type FundingRateResult struct {
+	Symbol      string        `json:"symbol"`
+	Date        string        `json:"date"`
+	RecordCount int           `json:"record_count"`
+	Records     []FundingRate `json:"records"`
+	Warnings    []string      `json:"warnings,omitempty"`
+	SourceURL   string        `json:"source_url,omitempty"`
+}
+
+// DownloadFundingRate downloads and parses funding rate data for a given symbol and date
+func (c *Connector) DownloadFundingRate(ctx context.Context, symbol, year, month, day string) (*FundingRateResult, error) {
+	year, month, day = formatDate(year, month, day)
+	downloader := c.getDownloader()
+	url := downloader.URL(fmt.Sprintf("/data/futures/um/daily/fundingRate/%s/%s-fundingRate-%s-%s-%s.zip",
+		symbol, symbol, year, month, day))
+
+	archive, err := downloader.spoolURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	records, warnings, err := c.parser.parseFundingRateZip(ctx, archive, archive.size, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+
+	return &FundingRateResult{
+		Symbol:      symbol,
+		Date:        fmt.Sprintf("%s-%s-%s", year, month, day),
+		RecordCount: len(records),
+		Records:     records,
+		Warnings:    warnings,
+		SourceURL:   url,
+	}, nil
+}
+
+// ParseFundingRateZip extracts all CSV files from the zip archive and parses them as funding
+// rate records of symbol. Per-file failures are returned as warnings when the parser runs in
+// partial mode.
+func (p *Parser) ParseFundingRateZip(ctx context.Context, zipData []byte, symbol string) ([]FundingRate, []string, error) {
+	return p.parseFundingRateZip(ctx, bytes.NewReader(zipData), int64(len(zipData)), symbol)
+}
+
+// parseFundingRateZip is ParseFundingRateZip for an archive of the given size read through r
+func (p *Parser) parseFundingRateZip(ctx context.Context, r io.ReaderAt, size int64, symbol string) ([]FundingRate, []string, error) {
+	var (
+		allRecords []FundingRate
+		mu         sync.Mutex
+	)
+
+	warnings, err := p.processCSVFiles(ctx, r, size, func(r io.Reader, _ int64) error {
+		records, err := p.parseFundingRateCSV(ctx, r, symbol)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		allRecords = append(allRecords, records...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return allRecords, warnings, nil
+}
+
+// fundingRateSchema is the layout of funding rate files, parsed by parseFundingRateRecord
+type fundingRateSchema struct{}
+
+func (fundingRateSchema) ColumnCount() int { return 3 }
+
+func (fundingRateSchema) ParseRecord(record []string) (interface{}, error) {
+	return parseFundingRateRecord(record)
+}
+
+// parseFundingRateCSV parses funding rate CSV records one at a time
+func (p *Parser) parseFundingRateCSV(ctx context.Context, r io.Reader, symbol string) ([]FundingRate, error) {
+	// Funding happens a few times a day, so daily files are small
+	records := make([]FundingRate, 0, 8)
+
+	_, err := p.readCSVRows(ctx, r, fixedSchema(fundingRateSchema{}), 0, func(record interface{}) error {
+		rate := record.(FundingRate)
+		rate.Symbol = symbol
+		records = append(records, rate)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// parseFundingRateRecord converts a CSV record into a FundingRate.
+// Columns: funding_time, funding_rate, mark_price
+func parseFundingRateRecord(record []string) (FundingRate, error) {
+	var rate FundingRate
+	var err error
+
+	rate.FundingTime, err = strconv.ParseInt(record[0], 10, 64)
+	if err != nil {
+		return rate, fmt.Errorf("invalid FundingTime: %w", err)
+	}
+
+	rate.FundingRate, err = strconv.ParseFloat(record[1], 64)
+	if err != nil {
+		return rate, fmt.Errorf("invalid FundingRate: %w", err)
+	}
+
+	rate.MarkPrice, err = strconv.ParseFloat(record[2], 64)
+	if err != nil {
+		return rate, fmt.Errorf("invalid MarkPrice: %w", err)
+	}
+
+	return rate, nil
+}
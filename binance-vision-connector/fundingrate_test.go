@@ -0,0 +1,51 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDownloadFundingRate(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-fundingRate-2025-01-01.csv": "funding_time,funding_rate,mark_price\n" +
+			"1735689600000,0.0001,94000.5\n" +
+			"1735718400000,-0.00005,94100.25\n" +
+			"not-a-time,0.0001,94000\n",
+	})
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	result, err := c.DownloadFundingRate(context.Background(), "BTCUSDT", "2025", "1", "1")
+	if err != nil {
+		t.Fatalf("DownloadFundingRate() error = %v", err)
+	}
+
+	if want := "/data/futures/um/daily/fundingRate/BTCUSDT/BTCUSDT-fundingRate-2025-01-01.zip"; path != want {
+		t.Errorf("requested %q, want %q", path, want)
+	}
+	if result.Date != "2025-01-01" || result.SourceURL != server.URL+path {
+		t.Errorf("Date = %q, SourceURL = %q", result.Date, result.SourceURL)
+	}
+	if result.RecordCount != 2 {
+		t.Fatalf("RecordCount = %d, want 2 (malformed rows are skipped)", result.RecordCount)
+	}
+
+	want := FundingRate{Symbol: "BTCUSDT", FundingTime: 1735718400000, FundingRate: -0.00005, MarkPrice: 94100.25}
+	if result.Records[1] != want {
+		t.Errorf("Records[1] = %+v, want %+v", result.Records[1], want)
+	}
+}
@@ -0,0 +1,163 @@
+package binancevisionconnector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// jsonChunkSize is how many encoded bytes of trades are collected before writing them
+const jsonChunkSize = 32 << 10
+
+// tradesKey is the trades key in the JSON encoding of a DownloadResult
+var tradesKey = []byte(`"trades":null`)
+
+// WriteJSON writes the result to w as the same JSON document json.Marshal produces.
+// Trades are encoded in small chunks as they are written, so memory use does not
+// grow with the number of trades.
+func (r *DownloadResult) WriteJSON(w io.Writer) error {
+	_, err := r.WriteTo(w)
+	return err
+}
+
+// WriteTo implements io.WriterTo, writing the result as JSON like WriteJSON
+func (r *DownloadResult) WriteTo(w io.Writer) (int64, error) {
+	// Everything but the trades is small, so encode it with them left out and write
+	// the trades in their place. Keys and string values before the trades cannot
+	// contain an unescaped quote, so the first match is the trades key.
+	withoutTrades := *r
+	withoutTrades.Trades = nil
+	doc, err := json.Marshal(&withoutTrades)
+	if err != nil {
+		return 0, err
+	}
+	at := bytes.Index(doc, tradesKey)
+	if at < 0 {
+		return 0, fmt.Errorf("trades key missing from encoded result")
+	}
+	at += len(tradesKey) - len("null")
+
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write(doc[:at]); err != nil {
+		return cw.n, err
+	}
+	if err := WriteTradesJSON(cw, r.Trades, appendTradeJSON); err != nil {
+		return cw.n, err
+	}
+	_, err = cw.Write(doc[at+len("null"):])
+	return cw.n, err
+}
+
+// WriteTradesJSON writes trades as a JSON array, or null when trades is nil, encoding
+// each trade with appendTrade. Encoded trades are written out in small chunks, so
+// memory use does not grow with the number of trades.
+func WriteTradesJSON(w io.Writer, trades []Trade, appendTrade func(dst []byte, t *Trade) ([]byte, error)) error {
+	if trades == nil {
+		_, err := w.Write([]byte("null"))
+		return err
+	}
+
+	var err error
+	buf := make([]byte, 0, jsonChunkSize+256)
+	buf = append(buf, '[')
+	for i := range trades {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		if buf, err = appendTrade(buf, &trades[i]); err != nil {
+			return err
+		}
+		if len(buf) >= jsonChunkSize {
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	buf = append(buf, ']')
+	_, err = w.Write(buf)
+	return err
+}
+
+// ToJSON converts DownloadResult to JSON. It holds the whole document in memory;
+// use WriteJSON to write a large result to a file or connection.
+func (r *DownloadResult) ToJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// TradeJSONField is one field of the JSON encoding of a Trade
+type TradeJSONField struct {
+	Name   string
+	Append func(dst []byte, t *Trade) []byte
+}
+
+// TradeJSONFields lists the fields of a Trade in the order json.Marshal encodes them
+var TradeJSONFields = []TradeJSONField{
+	{"trade_id", func(dst []byte, t *Trade) []byte { return strconv.AppendInt(dst, t.TradeID, 10) }},
+	{"price", func(dst []byte, t *Trade) []byte { return AppendJSONFloat(dst, t.Price) }},
+	{"quantity", func(dst []byte, t *Trade) []byte { return AppendJSONFloat(dst, t.Quantity) }},
+	{"quote_quantity", func(dst []byte, t *Trade) []byte { return AppendJSONFloat(dst, t.QuoteQuantity) }},
+	{"timestamp", func(dst []byte, t *Trade) []byte { return strconv.AppendInt(dst, t.Timestamp, 10) }},
+	{"is_buyer_maker", func(dst []byte, t *Trade) []byte { return strconv.AppendBool(dst, t.IsBuyerMaker) }},
+	{"is_best_match", func(dst []byte, t *Trade) []byte { return strconv.AppendBool(dst, t.IsBestMatch) }},
+}
+
+// appendTradeJSON appends t encoded as json.Marshal does, which rejects NaN and
+// infinities as they have no JSON representation
+func appendTradeJSON(dst []byte, t *Trade) ([]byte, error) {
+	for _, f := range [...]float64{t.Price, t.Quantity, t.QuoteQuantity} {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return dst, fmt.Errorf("json: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+
+	dst = append(dst, '{')
+	for i, field := range TradeJSONFields {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, '"')
+		dst = append(dst, field.Name...)
+		dst = append(dst, '"', ':')
+		dst = field.Append(dst, t)
+	}
+	return append(dst, '}'), nil
+}
+
+// AppendJSONFloat appends f formatted the same way encoding/json formats float64
+// values. NaN and infinities are appended as strconv formats them.
+func AppendJSONFloat(dst []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
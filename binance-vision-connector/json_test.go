@@ -0,0 +1,62 @@
+package binancevisionconnector
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestDownloadResult_WriteJSON(t *testing.T) {
+	// Enough trades to be written in several chunks
+	var many []Trade
+	for i := 0; i < 2000; i++ {
+		many = append(many, Trade{TradeID: int64(i), Price: 0.1 * float64(i), Quantity: 1e-7, QuoteQuantity: 1e21, Timestamp: 1735430400000, IsBestMatch: i%2 == 0})
+	}
+
+	tests := []struct {
+		name   string
+		result DownloadResult
+	}{
+		{"nil trades", DownloadResult{Symbol: "BTCUSDT", Date: "2025-01-01"}},
+		{"empty trades", DownloadResult{Symbol: "BTCUSDT", Date: "2025-01-01", Trades: []Trade{}}},
+		{"trades and later fields", DownloadResult{
+			Symbol:     "BTCUSDT",
+			Date:       "2025-01-01",
+			TradeCount: 2,
+			Trades:     []Trade{{TradeID: 1, Price: 94512.37, Quantity: 0.00012, QuoteQuantity: 11.34, Timestamp: 1735430400000, IsBuyerMaker: true, IsBestMatch: true}, {TradeID: 2, Price: -0, Quantity: 123456789}},
+			Warnings:   []string{`file "b.csv": "trades":null`},
+			ParseStats: ParseStats{SampleErrors: []string{"line 3: invalid Price"}},
+			SourceURL:  "https://example.com/a?b=<c>&d",
+		}},
+		{"many trades", DownloadResult{Symbol: "BTCUSDT", TradeCount: len(many), Trades: many}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := json.Marshal(&tt.result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			n, err := tt.result.WriteTo(&buf)
+			if err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("WriteTo() wrote\n%s\nwant\n%s", buf.Bytes(), want)
+			}
+			if n != int64(buf.Len()) {
+				t.Errorf("WriteTo() = %d, want %d bytes", n, buf.Len())
+			}
+		})
+	}
+}
+
+func TestDownloadResult_WriteJSON_UnsupportedFloat(t *testing.T) {
+	result := DownloadResult{Trades: []Trade{{Price: math.NaN()}}}
+	if err := result.WriteJSON(&bytes.Buffer{}); err == nil {
+		t.Error("WriteJSON() error = nil, want an error for NaN")
+	}
+}
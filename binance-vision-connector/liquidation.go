@@ -0,0 +1,164 @@
+package binancevisionconnector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Liquidation represents a single forced liquidation order of a USDⓈ-M futures contract
+type Liquidation struct {
+	Symbol             string  `json:"symbol"`
+	Time               int64   `json:"time"`
+	Side               string  `json:"side"` // SELL liquidates a long position, BUY a short one
+	OrderType          string  `json:"order_type"`
+	TimeInForce        string  `json:"time_in_force"`
+	Quantity           float64 `json:"quantity"` // Original order quantity
+	Price              float64 `json:"price"`
+	AveragePrice       float64 `json:"average_price"`
+	Status             string  `json:"status"` // Order status, such as FILLED
+	LastFilledQuantity float64 `json:"last_filled_quantity"`
+	FilledQuantity     float64 `json:"filled_quantity"` // Accumulated filled quantity
+}
+
+// LiquidationResult contains the downloaded liquidation data
+type LiquidationResult struct {
+	Symbol      string        `json:"symbol"`
+	Date        string        `json:"date"`
+	RecordCount int           `json:"record_count"`
+	Records     []Liquidation `json:"records"`
+	Warnings    []string      `json:"warnings,omitempty"`
+	SourceURL   string        `json:"source_url,omitempty"`
+}
+
+// DownloadLiquidations downloads and parses liquidation snapshot data for a given symbol
+// and date. Records are ordered by time.
+func (c *Connector) DownloadLiquidations(ctx context.Context, symbol, year, month, day string) (*LiquidationResult, error) {
+	year, month, day = formatDate(year, month, day)
+	downloader := c.getDownloader()
+	url := downloader.URL(fmt.Sprintf("/data/futures/um/daily/liquidationSnapshot/%s/%s-liquidationSnapshot-%s-%s-%s.zip",
+		symbol, symbol, year, month, day))
+
+	archive, err := downloader.spoolURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	records, warnings, err := c.parser.parseLiquidationZip(ctx, archive, archive.size, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+
+	return &LiquidationResult{
+		Symbol:      symbol,
+		Date:        fmt.Sprintf("%s-%s-%s", year, month, day),
+		RecordCount: len(records),
+		Records:     records,
+		Warnings:    warnings,
+		SourceURL:   url,
+	}, nil
+}
+
+// ParseLiquidationZip extracts all CSV files from the zip archive and parses them as
+// liquidation records of symbol, ordered by time. Per-file failures are returned as
+// warnings when the parser runs in partial mode.
+func (p *Parser) ParseLiquidationZip(ctx context.Context, zipData []byte, symbol string) ([]Liquidation, []string, error) {
+	return p.parseLiquidationZip(ctx, bytes.NewReader(zipData), int64(len(zipData)), symbol)
+}
+
+// parseLiquidationZip is ParseLiquidationZip for an archive of the given size read through r
+func (p *Parser) parseLiquidationZip(ctx context.Context, r io.ReaderAt, size int64, symbol string) ([]Liquidation, []string, error) {
+	var (
+		allRecords []Liquidation
+		mu         sync.Mutex
+	)
+
+	warnings, err := p.processCSVFiles(ctx, r, size, func(r io.Reader, _ int64) error {
+		records, err := p.parseLiquidationCSV(ctx, r, symbol)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		allRecords = append(allRecords, records...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Files finish parsing in any order
+	sort.SliceStable(allRecords, func(i, j int) bool {
+		return allRecords[i].Time < allRecords[j].Time
+	})
+	return allRecords, warnings, nil
+}
+
+// liquidationSchema is the layout of liquidation snapshot files, parsed by parseLiquidationRecord
+type liquidationSchema struct{}
+
+func (liquidationSchema) ColumnCount() int { return 10 }
+
+func (liquidationSchema) ParseRecord(record []string) (interface{}, error) {
+	return parseLiquidationRecord(record)
+}
+
+// parseLiquidationCSV parses liquidation CSV records one at a time
+func (p *Parser) parseLiquidationCSV(ctx context.Context, r io.Reader, symbol string) ([]Liquidation, error) {
+	var records []Liquidation
+
+	_, err := p.readCSVRows(ctx, r, fixedSchema(liquidationSchema{}), 0, func(record interface{}) error {
+		liquidation := record.(Liquidation)
+		liquidation.Symbol = symbol
+		records = append(records, liquidation)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// parseLiquidationRecord converts a CSV record into a Liquidation.
+// Columns: time, side, order_type, time_in_force, original_quantity, price,
+// average_price, order_status, last_fill_quantity, accumulated_fill_quantity
+func parseLiquidationRecord(record []string) (Liquidation, error) {
+	var liquidation Liquidation
+	var err error
+
+	liquidation.Time, err = strconv.ParseInt(record[0], 10, 64)
+	if err != nil {
+		return liquidation, fmt.Errorf("invalid Time: %w", err)
+	}
+
+	liquidation.Side = record[1]
+	liquidation.OrderType = record[2]
+	liquidation.TimeInForce = record[3]
+	liquidation.Status = record[7]
+
+	floats := []struct {
+		name  string
+		value *float64
+		raw   string
+	}{
+		{"Quantity", &liquidation.Quantity, record[4]},
+		{"Price", &liquidation.Price, record[5]},
+		{"AveragePrice", &liquidation.AveragePrice, record[6]},
+		{"LastFilledQuantity", &liquidation.LastFilledQuantity, record[8]},
+		{"FilledQuantity", &liquidation.FilledQuantity, record[9]},
+	}
+	for _, f := range floats {
+		if *f.value, err = strconv.ParseFloat(f.raw, 64); err != nil {
+			return liquidation, fmt.Errorf("invalid %s: %w", f.name, err)
+		}
+	}
+
+	return liquidation, nil
+}
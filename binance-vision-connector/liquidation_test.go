@@ -0,0 +1,60 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDownloadLiquidations(t *testing.T) {
+	const header = "time,side,order_type,time_in_force,original_quantity,price,average_price,order_status,last_fill_quantity,accumulated_fill_quantity\n"
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-liquidationSnapshot-2023-01-01-b.csv": header +
+			"1672574400000,BUY,LIMIT,IOC,0.5,16700.1,16650.0,FILLED,0.5,0.5\n",
+		"BTCUSDT-liquidationSnapshot-2023-01-01-a.csv": header +
+			"1672531200000,SELL,LIMIT,IOC,1.2,16500.5,16520.25,FILLED,0.2,1.2\n" +
+			"1672531200001,SELL,LIMIT,IOC,abc,16500.5,16520.25,FILLED,0.2,1.2\n",
+	})
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	result, err := c.DownloadLiquidations(context.Background(), "BTCUSDT", "2023", "1", "1")
+	if err != nil {
+		t.Fatalf("DownloadLiquidations() error = %v", err)
+	}
+
+	if want := "/data/futures/um/daily/liquidationSnapshot/BTCUSDT/BTCUSDT-liquidationSnapshot-2023-01-01.zip"; path != want {
+		t.Errorf("requested %q, want %q", path, want)
+	}
+	if result.Date != "2023-01-01" || result.SourceURL != server.URL+path {
+		t.Errorf("Date = %q, SourceURL = %q", result.Date, result.SourceURL)
+	}
+	if result.RecordCount != 2 {
+		t.Fatalf("RecordCount = %d, want 2 (malformed rows are skipped)", result.RecordCount)
+	}
+
+	// Records from both files come back in time order
+	want := Liquidation{
+		Symbol: "BTCUSDT", Time: 1672531200000, Side: "SELL", OrderType: "LIMIT", TimeInForce: "IOC",
+		Quantity: 1.2, Price: 16500.5, AveragePrice: 16520.25, Status: "FILLED", LastFilledQuantity: 0.2, FilledQuantity: 1.2,
+	}
+	if result.Records[0] != want {
+		t.Errorf("Records[0] = %+v, want %+v", result.Records[0], want)
+	}
+	if result.Records[1].Side != "BUY" {
+		t.Errorf("Records[1].Side = %q, want BUY", result.Records[1].Side)
+	}
+}
@@ -0,0 +1,121 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+)
+
+// DefaultListingURL is the S3 bucket endpoint that lists the Binance Vision archives
+const DefaultListingURL = "https://s3-ap-northeast-1.amazonaws.com/data.binance.vision"
+
+// maxListingPages bounds how many listing pages a listing follows (1000 keys each)
+const maxListingPages = 100
+
+// listBucketResult is the subset of an S3 ListObjects response used for listing archives.
+// Both V2 (continuation tokens) and V1 (markers) pagination are understood.
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	NextMarker            string `xml:"NextMarker"`
+	Contents              []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+}
+
+// ListAvailableDates returns the dates, as sorted YYYY-MM-DD strings, for which a daily
+// trade archive of symbol has been published. It follows the bucket listing across
+// pages, so symbols with years of history are returned in full.
+func (c *Connector) ListAvailableDates(ctx context.Context, symbol string) ([]string, error) {
+	return listAvailableDates(ctx, c.getDownloader(), symbol)
+}
+
+// listAvailableDates is ListAvailableDates making its requests through downloader
+func listAvailableDates(ctx context.Context, downloader *Downloader, symbol string) ([]string, error) {
+	var dates []string
+	err := listBucket(ctx, downloader, downloader.market.tradesPrefix()+symbol+"/", "", func(listing *listBucketResult) {
+		for _, object := range listing.Contents {
+			// Skip .CHECKSUM files and anything else that is not a trade archive
+			match := archiveNamePattern.FindStringSubmatch(path.Base(object.Key))
+			if match != nil && match[1] == symbol {
+				dates = append(dates, match[2])
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(dates)
+	return dates, nil
+}
+
+// listBucket lists the keys under prefix page by page, passing each page to visit.
+// With a delimiter, keys are grouped into the page's CommonPrefixes instead.
+func listBucket(ctx context.Context, downloader *Downloader, prefix, delimiter string, visit func(*listBucketResult)) error {
+	var continuationToken, marker string
+	for page := 0; ; page++ {
+		if page == maxListingPages {
+			return fmt.Errorf("listing of %s exceeds %d pages", prefix, maxListingPages)
+		}
+
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if delimiter != "" {
+			query.Set("delimiter", delimiter)
+		}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		data, err := downloader.DownloadURLToMemory(ctx, downloader.listingURL+"?"+query.Encode())
+		if err != nil {
+			return fmt.Errorf("failed to list archives: %w", err)
+		}
+
+		var listing listBucketResult
+		if err := xml.Unmarshal(data, &listing); err != nil {
+			return fmt.Errorf("failed to parse archive listing: %w", err)
+		}
+		visit(&listing)
+
+		if !listing.IsTruncated {
+			return nil
+		}
+
+		// V2 listings return a continuation token; V1 listings continue after a marker,
+		// which is the last key (or common prefix) when NextMarker is omitted
+		continuationToken, marker = listing.NextContinuationToken, ""
+		if continuationToken == "" {
+			marker = listing.NextMarker
+			if marker == "" {
+				marker = listing.lastKey()
+			}
+			if marker == "" {
+				return fmt.Errorf("failed to list archives: truncated listing without a continuation token")
+			}
+		}
+	}
+}
+
+// lastKey returns the last key or common prefix of the page, whichever sorts later
+func (l *listBucketResult) lastKey() string {
+	var last string
+	if len(l.Contents) > 0 {
+		last = l.Contents[len(l.Contents)-1].Key
+	}
+	if len(l.CommonPrefixes) > 0 {
+		last = max(last, l.CommonPrefixes[len(l.CommonPrefixes)-1].Prefix)
+	}
+	return last
+}
@@ -0,0 +1,84 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestListAvailableDates(t *testing.T) {
+	const prefix = "data/spot/daily/trades/BTCUSDT/"
+
+	// The listing is split over two pages, with keys out of date order
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.URL.Query().Get("prefix"); got != prefix {
+			t.Errorf("prefix = %q, want %q", got, prefix)
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		switch r.URL.Query().Get("continuation-token") {
+		case "":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <IsTruncated>true</IsTruncated>
+  <NextContinuationToken>page-2</NextContinuationToken>
+  <Contents><Key>%[1]sBTCUSDT-trades-2024-01-02.zip</Key></Contents>
+  <Contents><Key>%[1]sBTCUSDT-trades-2024-01-02.zip.CHECKSUM</Key></Contents>
+  <Contents><Key>%[1]sBTCUSDT-trades-2024-01-03.zip</Key></Contents>
+</ListBucketResult>`, prefix)
+		case "page-2":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <IsTruncated>false</IsTruncated>
+  <Contents><Key>%[1]sBTCUSDT-trades-2024-01-01.zip</Key></Contents>
+  <Contents><Key>%[1]sBTCUSDT-trades-2024-01-01.zip.CHECKSUM</Key></Contents>
+</ListBucketResult>`, prefix)
+		default:
+			http.Error(w, "unknown token", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ListingURL = server.URL + "/"
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	dates, err := c.ListAvailableDates(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("ListAvailableDates() error = %v", err)
+	}
+
+	want := []string{"2024-01-01", "2024-01-02", "2024-01-03"}
+	if !reflect.DeepEqual(dates, want) {
+		t.Errorf("ListAvailableDates() = %v, want %v", dates, want)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestListAvailableDates_InvalidXML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>not a listing"))
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ListingURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	if _, err := c.ListAvailableDates(context.Background(), "BTCUSDT"); err == nil {
+		t.Error("ListAvailableDates() error = nil, want a parse error")
+	}
+}
@@ -0,0 +1,68 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// archiveNamePattern matches trade archive names such as BTCUSDT-trades-2025-01-01.zip,
+// or BTCUSD_PERP-trades-2025-01-01.zip for COIN-M contracts
+var archiveNamePattern = regexp.MustCompile(`^([A-Z0-9]+(?:_[A-Z0-9]+)?)-trades-(\d{4}-\d{2}-\d{2})\.zip$`)
+
+// ParseLocalArchive parses a trade archive that is already on disk, such as one
+// mirrored from Binance Vision, using the same pipeline as DownloadTrades. Symbol
+// and Date are inferred from the standard archive file name and left empty if the
+// file was renamed.
+func (c *Connector) ParseLocalArchive(ctx context.Context, path string) (*DownloadResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	limit := c.getDownloader().maxSize
+	if limit <= 0 {
+		limit = maxDownloadSize
+	}
+	if info.Size() > limit {
+		return nil, fmt.Errorf("%w: file size %d exceeds limit of %d bytes", ErrResponseTooLarge, info.Size(), limit)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer file.Close()
+
+	parseStart := time.Now()
+	parsed, err := c.parser.ParseZipReader(ctx, file, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+	parseTime := time.Since(parseStart)
+
+	result := &DownloadResult{
+		TradeCount:        len(parsed.Trades),
+		Trades:            parsed.Trades,
+		Warnings:          parsed.Warnings,
+		ParseStats:        parsed.Stats,
+		Truncated:         parsed.Stats.TruncatedFiles > 0,
+		DuplicatesRemoved: parsed.DuplicatesRemoved,
+		Timing: Timing{
+			ParseMS: DurationMS(parseTime),
+		},
+		CompressedBytes:   info.Size(),
+		UncompressedBytes: parsed.UncompressedBytes,
+	}
+
+	if match := archiveNamePattern.FindStringSubmatch(filepath.Base(path)); match != nil {
+		result.Symbol = match[1]
+		result.Date = match[2]
+	}
+	result.classifyEmpty()
+
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLocalArchive(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n2,0.6,10,6,1735430400001,false,true\n",
+	})
+
+	tests := []struct {
+		name       string
+		file       string
+		wantSymbol string
+		wantDate   string
+	}{
+		{"standard name", "BTCUSDT-trades-2025-01-01.zip", "BTCUSDT", "2025-01-01"},
+		{"renamed file", "backup.zip", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, zipData, 0o644); err != nil {
+				t.Fatalf("Failed to write archive: %v", err)
+			}
+
+			result, err := NewConnector(0).ParseLocalArchive(context.Background(), path)
+			if err != nil {
+				t.Fatalf("ParseLocalArchive() error = %v", err)
+			}
+			if result.TradeCount != 2 {
+				t.Errorf("TradeCount = %d, want 2", result.TradeCount)
+			}
+			if result.Symbol != tt.wantSymbol || result.Date != tt.wantDate {
+				t.Errorf("Symbol, Date = %q, %q, want %q, %q", result.Symbol, result.Date, tt.wantSymbol, tt.wantDate)
+			}
+		})
+	}
+
+	if _, err := NewConnector(0).ParseLocalArchive(context.Background(), filepath.Join(t.TempDir(), "missing.zip")); err == nil {
+		t.Error("ParseLocalArchive() on a missing file should fail")
+	}
+}
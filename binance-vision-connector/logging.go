@@ -0,0 +1,46 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx carrying logger, which the connector uses
+// for debug logs made while serving calls with that context, such as skipped CSV rows.
+// Without one, slog.Default is used.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// loggerFromContext returns the logger stored by ContextWithLogger, or slog.Default
+func loggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// rowLogger logs skipped CSV rows at debug level. Whether debug logging is enabled is
+// checked once per file, so the per-row cost is negligible when it is off.
+type rowLogger struct {
+	ctx    context.Context
+	logger *slog.Logger // nil when debug logging is disabled
+}
+
+// newRowLogger returns a rowLogger for the logger carried by ctx
+func newRowLogger(ctx context.Context) rowLogger {
+	logger := loggerFromContext(ctx)
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		logger = nil
+	}
+	return rowLogger{ctx: ctx, logger: logger}
+}
+
+// skipped logs that the row at lineNum was skipped for reason
+func (l rowLogger) skipped(lineNum int, reason string) {
+	if l.logger != nil {
+		l.logger.DebugContext(l.ctx, "skipped CSV row", "line", lineNum, "reason", reason)
+	}
+}
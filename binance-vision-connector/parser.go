@@ -0,0 +1,920 @@
+package binancevisionconnector
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// utf8BOM is the byte order mark some tools write at the start of CSV files
+const utf8BOM = "\ufeff"
+
+// ctxCheckInterval is the number of CSV records parsed between context cancellation checks
+const ctxCheckInterval = 1000
+
+// maxSampleErrors limits the number of sample parse errors kept in ParseStats
+const maxSampleErrors = 5
+
+// Parser handles extracting and parsing trade CSV files from zip archives
+type Parser struct {
+	partialOK   bool         // Report per-file failures as warnings instead of failing the archive
+	concurrency int          // Maximum number of CSV files parsed at once (0 = unlimited)
+	sortTrades  bool         // Sort trades after all files are parsed
+	sortBy      TradeSortKey // Sort key used when sortTrades is set
+	maxTrades   int          // Maximum trades parsed per CSV file (0 = unlimited)
+	deduplicate bool         // Drop trades whose TradeID was already seen
+
+	maxUncompressed int64 // Budget for CSV bytes read across all files of an archive (0 = unlimited)
+	maxNested       int64 // Largest nested archive extracted into memory (0 = maxDownloadSize)
+
+	comma      rune // CSV field delimiter
+	lazyQuotes bool // Passed to csv.Reader.LazyQuotes
+
+	maxErrorDetails int // Skipped rows reported with their raw content per archive (0 = none)
+}
+
+// TradeSortKey selects the field trades are ordered by
+type TradeSortKey string
+
+const (
+	SortByTradeID   TradeSortKey = "trade_id"
+	SortByTimestamp TradeSortKey = "timestamp"
+)
+
+// ParseResult contains the trades parsed from an archive
+type ParseResult struct {
+	Trades            []Trade
+	Warnings          []string // Per-file failures, only populated in partial mode
+	Stats             ParseStats
+	UncompressedBytes int64 // Total CSV bytes read from the archive
+	DuplicatesRemoved int   // Trades dropped by deduplication
+}
+
+// ParseStats reports CSV rows that were skipped while parsing
+type ParseStats struct {
+	SkippedShort      int      `json:"skipped_short"`           // Rows with too few columns
+	SkippedParseError int      `json:"skipped_parse_error"`     // Rows with unparseable values
+	SampleErrors      []string `json:"sample_errors,omitempty"` // First few skip reasons
+	TruncatedFiles    int      `json:"truncated_files"`         // Files that had more rows than the per-file trade limit
+	Filtered          int      `json:"filtered,omitempty"`      // Trades dropped by a ContextWithTradeFilter filter or before a trade ID range
+	StoppedFiles      int      `json:"stopped_files,omitempty"` // Files not read to the end because the rest was past a trade ID range
+
+	// ErrorDetails lists the first skipped rows with their raw content, when the
+	// parser is configured to collect them
+	ErrorDetails []ParseErrorDetail `json:"error_details,omitempty"`
+}
+
+// ParseErrorDetail describes one skipped CSV row
+type ParseErrorDetail struct {
+	Line      int    `json:"line"`       // Line number within its CSV file
+	RawRecord string `json:"raw_record"` // The row's fields joined with the CSV delimiter
+	Error     string `json:"error"`
+}
+
+// Skipped returns the number of rows skipped as too short or unparseable
+func (s ParseStats) Skipped() int {
+	return s.SkippedShort + s.SkippedParseError
+}
+
+// addSample records a skip reason if the sample limit has not been reached
+func (s *ParseStats) addSample(lineNum int, reason string) {
+	if len(s.SampleErrors) < maxSampleErrors {
+		s.SampleErrors = append(s.SampleErrors, fmt.Sprintf("line %d: %s", lineNum, reason))
+	}
+}
+
+// merge adds the counts, samples and up to maxDetails error details from other into s
+func (s *ParseStats) merge(other ParseStats, maxDetails int) {
+	s.SkippedShort += other.SkippedShort
+	s.SkippedParseError += other.SkippedParseError
+	s.TruncatedFiles += other.TruncatedFiles
+	s.Filtered += other.Filtered
+	s.StoppedFiles += other.StoppedFiles
+	for _, sample := range other.SampleErrors {
+		if len(s.SampleErrors) >= maxSampleErrors {
+			break
+		}
+		s.SampleErrors = append(s.SampleErrors, sample)
+	}
+	for _, detail := range other.ErrorDetails {
+		if len(s.ErrorDetails) >= maxDetails {
+			break
+		}
+		s.ErrorDetails = append(s.ErrorDetails, detail)
+	}
+}
+
+// NewParser creates a new parser
+func NewParser() *Parser {
+	return &Parser{comma: ','}
+}
+
+// addErrorDetail records a skipped row with its raw content if fewer than the parser's
+// maximum have been recorded. record may be reused by the CSV reader, so it is copied.
+func (p *Parser) addErrorDetail(stats *ParseStats, lineNum int, record []string, reason string) {
+	if len(stats.ErrorDetails) >= p.maxErrorDetails {
+		return
+	}
+	comma := p.comma
+	if comma == 0 {
+		comma = ','
+	}
+	stats.ErrorDetails = append(stats.ErrorDetails, ParseErrorDetail{
+		Line:      lineNum,
+		RawRecord: strings.Join(record, string(comma)),
+		Error:     reason,
+	})
+}
+
+// newCSVReader returns a CSV reader for r that uses the parser's delimiter and quote
+// handling, reuses its record slice and accepts any number of fields per record
+func (p *Parser) newCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	if p.comma != 0 {
+		reader.Comma = p.comma
+	}
+	reader.LazyQuotes = p.lazyQuotes
+	reader.ReuseRecord = true
+	reader.FieldsPerRecord = -1
+	return reader
+}
+
+// validCSVDelimiter reports whether r can separate CSV fields
+func validCSVDelimiter(r rune) bool {
+	return r != '"' && r != '\r' && r != '\n' && r != utf8.RuneError && utf8.ValidRune(r)
+}
+
+// ParseZip extracts all CSV files from the zip archive and parses them concurrently
+func (p *Parser) ParseZip(ctx context.Context, zipData []byte) (*ParseResult, error) {
+	return p.ParseZipReader(ctx, bytes.NewReader(zipData), int64(len(zipData)))
+}
+
+// ParseZipReader is like ParseZip for an archive of the given size read through r,
+// such as an *os.File, so the archive does not have to be loaded into memory
+func (p *Parser) ParseZipReader(ctx context.Context, r io.ReaderAt, size int64) (*ParseResult, error) {
+	var (
+		allTrades []Trade
+		allStats  ParseStats
+		allBytes  int64
+		mu        sync.Mutex
+	)
+
+	warnings, err := p.processCSVFiles(ctx, r, size, func(r io.Reader, fileSize int64) error {
+		cr := &countingReader{r: r}
+		trades, stats, err := p.parseCSVStreaming(ctx, cr, p.maxTrades, fileSize)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		allTrades = append(allTrades, trades...)
+		allStats.merge(stats, p.maxErrorDetails)
+		allBytes += cr.n
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := 0
+	if p.deduplicate {
+		allTrades, duplicates = deduplicateTrades(allTrades)
+	}
+
+	// Files finish in arbitrary order, so restore a deterministic order if requested
+	if p.sortTrades {
+		sortTrades(allTrades, p.sortBy)
+	}
+
+	return &ParseResult{
+		Trades:            allTrades,
+		Warnings:          warnings,
+		Stats:             allStats,
+		UncompressedBytes: allBytes,
+		DuplicatesRemoved: duplicates,
+	}, nil
+}
+
+// Parse parses trades from a Binance Vision trades CSV stream, such as an extracted
+// archive file. Both the spot (7 column) and futures (6 column) layouts are accepted,
+// with or without a header row. A header row naming the columns, as futures files
+// have, is used to find them, so files with reordered columns parse too. Malformed
+// rows are skipped; use Parser.ParseCSV to find out how many.
+func Parse(r io.Reader) ([]Trade, error) {
+	trades, _, err := NewParser().ParseCSV(context.Background(), r)
+	return trades, err
+}
+
+// ParseCSV parses trades from a CSV stream, reporting skipped rows in the returned
+// ParseStats. The parser's per-file trade limit applies.
+func (p *Parser) ParseCSV(ctx context.Context, r io.Reader) ([]Trade, ParseStats, error) {
+	return p.parseCSVStreaming(ctx, r, p.maxTrades, 0)
+}
+
+// StreamZip parses the zip archive like ParseZip but passes each trade to emit as soon
+// as it is parsed instead of collecting them, so the returned result has no Trades.
+// Calls to emit are serialized; trades from different files may interleave. Sorting
+// and deduplication are not applied. If emit returns an error, parsing stops and
+// that error is returned.
+func (p *Parser) StreamZip(ctx context.Context, zipData []byte, emit func(Trade) error) (*ParseResult, error) {
+	return p.StreamZipReader(ctx, bytes.NewReader(zipData), int64(len(zipData)), emit)
+}
+
+// StreamZipReader is like StreamZip for an archive of the given size read through r
+func (p *Parser) StreamZipReader(ctx context.Context, r io.ReaderAt, size int64, emit func(Trade) error) (*ParseResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		allStats ParseStats
+		allBytes int64
+		emitErr  error
+		mu       sync.Mutex
+	)
+
+	serializedEmit := func(trade Trade) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if emitErr != nil {
+			return emitErr
+		}
+		if err := emit(trade); err != nil {
+			emitErr = err
+			cancel()
+			return err
+		}
+		return nil
+	}
+
+	warnings, err := p.processCSVFiles(ctx, r, size, func(r io.Reader, _ int64) error {
+		cr := &countingReader{r: r}
+		stats, err := p.parseCSVRecords(ctx, cr, p.maxTrades, serializedEmit)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		allStats.merge(stats, p.maxErrorDetails)
+		allBytes += cr.n
+		mu.Unlock()
+		return nil
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if emitErr != nil {
+		return nil, emitErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParseResult{
+		Warnings:          warnings,
+		Stats:             allStats,
+		UncompressedBytes: allBytes,
+	}, nil
+}
+
+// CountZipReader counts the trade rows in the archive of the given size read through r
+// without converting them to Trades, so memory use does not grow with the archive.
+// Header rows and rows with too few columns are not counted, but the values in the
+// remaining rows are not validated. The parser's per-file trade limit does not apply.
+func (p *Parser) CountZipReader(ctx context.Context, r io.ReaderAt, size int64) (int, *ParseResult, error) {
+	var (
+		total    int
+		allStats ParseStats
+		allBytes int64
+		mu       sync.Mutex
+	)
+
+	warnings, err := p.processCSVFiles(ctx, r, size, func(r io.Reader, _ int64) error {
+		cr := &countingReader{r: r}
+		count, stats, err := p.countCSVRecords(ctx, cr)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		total += count
+		allStats.merge(stats, p.maxErrorDetails)
+		allBytes += cr.n
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return total, &ParseResult{
+		Warnings:          warnings,
+		Stats:             allStats,
+		UncompressedBytes: allBytes,
+	}, nil
+}
+
+// zipMagic and emptyZipMagic are the signatures a zip archive can start with: a local
+// file header, or the end of central directory record of an archive with no files
+var (
+	zipMagic      = []byte("PK\x03\x04")
+	emptyZipMagic = []byte("PK\x05\x06")
+)
+
+// maxMagicPreview is the number of leading bytes included in ErrNotAZipFile errors
+const maxMagicPreview = 64
+
+// checkZipMagic returns ErrNotAZipFile, including the first bytes of data for
+// debugging, unless data starts with a zip signature
+func checkZipMagic(data []byte) error {
+	if bytes.HasPrefix(data, zipMagic) || bytes.HasPrefix(data, emptyZipMagic) {
+		return nil
+	}
+	return fmt.Errorf("%w: body starts with %q", ErrNotAZipFile, data[:min(len(data), maxMagicPreview)])
+}
+
+// looksLikeHTML reports whether data starts with <!DOCTYPE html or <html, ignoring case,
+// leading whitespace and a byte order mark
+func looksLikeHTML(data []byte) bool {
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF")), " \t\r\n")
+	for _, prefix := range []string{"<!doctype html", "<html"} {
+		if len(data) >= len(prefix) && strings.EqualFold(string(data[:len(prefix)]), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkZipMagicAt is checkZipMagic for a downloaded archive read through r. An HTML
+// page in place of the archive is reported as a *MaintenanceError.
+func checkZipMagicAt(r io.ReaderAt) error {
+	preview := make([]byte, maxMagicPreview)
+	n, err := r.ReadAt(preview, 0)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read zip file: %w", err)
+	}
+	if err := checkZipMagic(preview[:n]); err != nil {
+		if looksLikeHTML(preview[:n]) {
+			return &MaintenanceError{Detail: fmt.Sprintf("body starts with %q", preview[:n])}
+		}
+		return err
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// uncompressedBudget limits the CSV bytes read across all files of one archive.
+// It is shared by the goroutines parsing the files, so the count is atomic.
+type uncompressedBudget struct {
+	limit int64 // 0 = unlimited
+	used  atomic.Int64
+}
+
+// checkDeclared fails early when the sizes declared in the zip headers already exceed the limit
+func (b *uncompressedBudget) checkDeclared(files []*zip.File) error {
+	if b.limit <= 0 {
+		return nil
+	}
+	var declared uint64
+	for _, f := range files {
+		declared += f.UncompressedSize64
+	}
+	if declared > uint64(b.limit) {
+		return fmt.Errorf("%w: CSV files declare %d bytes, limit is %d", ErrUncompressedTooLarge, declared, b.limit)
+	}
+	return nil
+}
+
+// reader wraps r so that reads fail with ErrUncompressedTooLarge once the
+// budget is used up. Headers can understate sizes, so this is the real guard.
+func (b *uncompressedBudget) reader(r io.Reader) io.Reader {
+	if b.limit <= 0 {
+		return r
+	}
+	return &budgetReader{r: r, budget: b}
+}
+
+// budgetReader charges the bytes read through it to an uncompressedBudget
+type budgetReader struct {
+	r      io.Reader
+	budget *uncompressedBudget
+}
+
+func (br *budgetReader) Read(p []byte) (int, error) {
+	n, err := br.r.Read(p)
+	if br.budget.used.Add(int64(n)) > br.budget.limit {
+		return n, fmt.Errorf("%w: more than %d bytes", ErrUncompressedTooLarge, br.budget.limit)
+	}
+	return n, err
+}
+
+// processCSVFiles opens every CSV file in the zip archive and runs parse on each
+// of them concurrently, along with the file's uncompressed size from the zip
+// directory. parse must be safe for concurrent use. In partial mode, per-file
+// failures are returned as warnings unless every file failed.
+func (p *Parser) processCSVFiles(ctx context.Context, r io.ReaderAt, size int64, parse func(r io.Reader, size int64) error) ([]string, error) {
+	if err := checkZipMagicAt(r); err != nil {
+		return nil, err
+	}
+
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zip reader: %w", err)
+	}
+
+	csvFiles, err := p.collectCSVFiles(zipReader, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(csvFiles) == 0 {
+		return nil, fmt.Errorf("%w in the archive (nested zips are searched %d level deep), archive contains: %s",
+			ErrNoCSVFiles, maxArchiveDepth, archiveFileNames(zipReader))
+	}
+
+	budget := &uncompressedBudget{limit: p.maxUncompressed}
+	if err := budget.checkDeclared(csvFiles); err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(csvFiles))
+
+	// Bound the number of files parsed at once since each builds its own slice
+	concurrency := p.concurrency
+	if concurrency <= 0 {
+		concurrency = len(csvFiles)
+	}
+	sem := make(chan struct{}, max(concurrency, 1))
+
+	csvCount := len(csvFiles)
+	for _, file := range csvFiles {
+		wg.Add(1)
+		go func(f *zip.File) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			rc, err := f.Open()
+			if err != nil {
+				errCh <- fmt.Errorf("failed to open file %s: %w", f.Name, err)
+				return
+			}
+			defer rc.Close()
+
+			if err := parse(budget.reader(rc), int64(f.UncompressedSize64)); err != nil {
+				errCh <- fmt.Errorf("failed to parse CSV %s: %w", f.Name, err)
+			}
+		}(file)
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	// Report cancellation as-is rather than as per-file parse errors
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for err := range errCh {
+		// An exhausted budget fails the whole archive, even in partial mode
+		if errors.Is(err, ErrUncompressedTooLarge) {
+			return nil, err
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, nil
+	}
+
+	if !p.partialOK || len(errs) == csvCount {
+		return nil, fmt.Errorf("errors processing CSV files: %v", errs)
+	}
+
+	warnings := make([]string, len(errs))
+	for i, err := range errs {
+		warnings[i] = err.Error()
+	}
+	return warnings, nil
+}
+
+// maxArchiveDepth is how many levels of zips nested inside the archive are searched for CSV files
+const maxArchiveDepth = 1
+
+// collectCSVFiles returns the CSV files in zipReader. Zip entries that are themselves
+// zip archives, as produced by some mirrors that re-archive files, are extracted into
+// memory and searched too, up to maxArchiveDepth levels deep. Nested archives are
+// limited to the parser's maxNested once extracted so a zip bomb cannot exhaust memory.
+func (p *Parser) collectCSVFiles(zipReader *zip.Reader, depth int) ([]*zip.File, error) {
+	var csvFiles []*zip.File
+	for _, file := range zipReader.File {
+		name := strings.ToLower(file.Name)
+		switch {
+		case strings.HasSuffix(name, ".csv"):
+			csvFiles = append(csvFiles, file)
+		case strings.HasSuffix(name, ".zip") && depth < maxArchiveDepth:
+			limit := p.maxNested
+			if limit <= 0 {
+				limit = maxDownloadSize
+			}
+			nested, err := openNestedZip(file, limit)
+			if err != nil {
+				return nil, err
+			}
+			nestedFiles, err := p.collectCSVFiles(nested, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			csvFiles = append(csvFiles, nestedFiles...)
+		}
+	}
+	return csvFiles, nil
+}
+
+// maxListedFiles is the number of file names archiveFileNames lists before truncating
+const maxListedFiles = 20
+
+// archiveFileNames returns a comma separated list of the files in the archive for error messages
+func archiveFileNames(zipReader *zip.Reader) string {
+	if len(zipReader.File) == 0 {
+		return "no files"
+	}
+
+	names := make([]string, 0, min(len(zipReader.File), maxListedFiles))
+	for _, file := range zipReader.File[:min(len(zipReader.File), maxListedFiles)] {
+		names = append(names, file.Name)
+	}
+	list := strings.Join(names, ", ")
+	if extra := len(zipReader.File) - maxListedFiles; extra > 0 {
+		list += fmt.Sprintf(" and %d more", extra)
+	}
+	return list
+}
+
+// openNestedZip reads a zip entry that is itself a zip archive of up to limit bytes into memory
+func openNestedZip(file *zip.File, limit int64) (*zip.Reader, error) {
+	if file.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("%w: nested archive %s is %d bytes", ErrUncompressedTooLarge, file.Name, file.UncompressedSize64)
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open nested archive %s: %w", file.Name, err)
+	}
+	defer rc.Close()
+
+	// The header size can lie, so enforce the limit while reading too
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nested archive %s: %w", file.Name, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: nested archive %s is more than %d bytes", ErrUncompressedTooLarge, file.Name, limit)
+	}
+
+	if err := checkZipMagic(data); err != nil {
+		return nil, fmt.Errorf("nested archive %s: %w", file.Name, err)
+	}
+	nested, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nested archive %s: %w", file.Name, err)
+	}
+	return nested, nil
+}
+
+// Trade slice sizing. A trades CSV row takes roughly avgTradeRowBytes bytes, so the
+// uncompressed file size gives an estimate of the row count. The size comes from the
+// zip header and is not trusted beyond maxEstimatedTradeRows; append grows the rest.
+const (
+	avgTradeRowBytes      = 64
+	defaultTradeCapacity  = 10000
+	maxEstimatedTradeRows = 1 << 16 // Caps the up-front allocation for absurd size claims
+)
+
+// tradeCapacity returns the initial capacity for the trades of a CSV file of the
+// given uncompressed size (0 if unknown), clamped by the uncompressed byte budget
+// (0 = unlimited) and limited by maxTrades (0 = unlimited)
+func tradeCapacity(size, budget int64, maxTrades int) int {
+	capacity := defaultTradeCapacity
+	if budget > 0 && size > budget {
+		size = budget
+	}
+	if size > 0 {
+		capacity = int(min(size/avgTradeRowBytes+1, maxEstimatedTradeRows))
+	}
+	if maxTrades > 0 && maxTrades < capacity {
+		capacity = maxTrades
+	}
+	return capacity
+}
+
+// parseCSVStreaming parses CSV records one at a time to keep memory usage low.
+// maxTrades limits the number of parsed trades (0 = unlimited).
+// The context is checked periodically so cancelled requests stop parsing promptly.
+// Skipped rows are counted in the returned ParseStats. sizeHint is the uncompressed
+// size of the CSV data if known (0 otherwise) and is used to presize the trade slice.
+func (p *Parser) parseCSVStreaming(ctx context.Context, r io.Reader, maxTrades int, sizeHint int64) ([]Trade, ParseStats, error) {
+	trades := make([]Trade, 0, tradeCapacity(sizeHint, p.maxUncompressed, maxTrades))
+
+	stats, err := p.parseCSVRecords(ctx, r, maxTrades, func(trade Trade) error {
+		trades = append(trades, trade)
+		return nil
+	})
+	if err != nil {
+		return nil, stats, err
+	}
+
+	return trades, stats, nil
+}
+
+// parseCSVRecords reads CSV records one at a time and passes each parsed trade to emit.
+// Trades outside the context's trade ID range or rejected by its trade filter are
+// dropped first. It stops after maxTrades trades when maxTrades is positive.
+func (p *Parser) parseCSVRecords(ctx context.Context, r io.Reader, maxTrades int, emit func(Trade) error) (ParseStats, error) {
+	progress := progressFromContext(ctx)
+	keep := tradeFilterFromContext(ctx)
+	ids, ranged := tradeIDRangeFromContext(ctx)
+
+	emitted := 0
+	reported := 0
+	defer func() { progress.addTrades(emitted - reported) }()
+
+	return p.readCSVRows(ctx, r, tradeSchemaFor, maxTrades, func(record interface{}) error {
+		trade := record.(*Trade)
+		if ranged {
+			if trade.TradeID > ids.To {
+				return errStopRows
+			}
+			if trade.TradeID < ids.From {
+				return errRecordFiltered
+			}
+		}
+		if keep != nil && !keep(trade) {
+			return errRecordFiltered
+		}
+		if err := emit(*trade); err != nil {
+			return err
+		}
+		emitted++
+		if emitted%ctxCheckInterval == 0 {
+			progress.addTrades(emitted - reported)
+			reported = emitted
+		}
+		return nil
+	})
+}
+
+// countCSVRecords counts the data rows of a trades CSV stream, skipping a header row
+// and rows too short for the detected layout like parseCSVRecords does
+func (p *Parser) countCSVRecords(ctx context.Context, r io.Reader) (int, ParseStats, error) {
+	count := 0
+	stats, err := p.readCSVRows(ctx, r, func(header []string, columns int) Schema {
+		return rowCountSchema{detectTradeSchema(header, columns)}
+	}, 0, func(interface{}) error {
+		count++
+		return nil
+	})
+	return count, stats, err
+}
+
+// deduplicateTrades removes trades with a TradeID seen earlier in the slice, keeping
+// the first occurrence. It filters in place and returns the number of trades removed.
+func deduplicateTrades(trades []Trade) ([]Trade, int) {
+	seen := make(map[int64]struct{}, len(trades))
+	kept := trades[:0]
+	for _, trade := range trades {
+		if _, ok := seen[trade.TradeID]; ok {
+			continue
+		}
+		seen[trade.TradeID] = struct{}{}
+		kept = append(kept, trade)
+	}
+	return kept, len(trades) - len(kept)
+}
+
+// sortTrades orders trades by the given key. The sort is stable, so trades with
+// equal keys keep their parse order. Unknown keys sort by trade ID.
+func sortTrades(trades []Trade, key TradeSortKey) {
+	if key == SortByTimestamp {
+		sort.SliceStable(trades, func(i, j int) bool {
+			return trades[i].Timestamp < trades[j].Timestamp
+		})
+		return
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].TradeID < trades[j].TradeID
+	})
+}
+
+// tradeSchema describes the column layout of a trades CSV file
+type tradeSchema struct {
+	columnCount int // Minimum number of columns per row
+
+	// Column indexes; -1 means the column is absent
+	tradeID       int
+	price         int
+	quantity      int
+	quoteQuantity int
+	timestamp     int
+	isBuyerMaker  int
+	isBestMatch   int
+}
+
+// spotTradeSchema is the layout of spot trade files:
+// id, price, qty, quote_qty, time, is_buyer_maker, is_best_match
+var spotTradeSchema = tradeSchema{
+	columnCount:   7,
+	tradeID:       0,
+	price:         1,
+	quantity:      2,
+	quoteQuantity: 3,
+	timestamp:     4,
+	isBuyerMaker:  5,
+	isBestMatch:   6,
+}
+
+// futuresTradeSchema is the layout of futures trade files, which omit is_best_match:
+// id, price, qty, quote_qty, time, is_buyer_maker
+var futuresTradeSchema = tradeSchema{
+	columnCount:   6,
+	tradeID:       0,
+	price:         1,
+	quantity:      2,
+	quoteQuantity: 3,
+	timestamp:     4,
+	isBuyerMaker:  5,
+	isBestMatch:   -1,
+}
+
+// detectTradeSchema picks the trade layout named by the header row, if the file has
+// one naming every column, and otherwise the positional layout matching the column count
+func detectTradeSchema(header []string, columns int) *tradeSchema {
+	if schema := headerTradeSchema(header); schema != nil {
+		return schema
+	}
+	if columns == futuresTradeSchema.columnCount {
+		return &futuresTradeSchema
+	}
+	return &spotTradeSchema
+}
+
+// headerTradeSchema builds the layout a header row describes, for mirrors that
+// reorder the columns. Names are matched case-insensitively, ignoring underscores
+// and spaces, and unknown columns are ignored. It returns nil unless every column
+// but is_best_match is named exactly once.
+func headerTradeSchema(header []string) *tradeSchema {
+	if header == nil {
+		return nil
+	}
+	schema := tradeSchema{tradeID: -1, price: -1, quantity: -1, quoteQuantity: -1, timestamp: -1, isBuyerMaker: -1, isBestMatch: -1}
+	for i, name := range header {
+		var column *int
+		switch strings.NewReplacer("_", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(name))) {
+		case "id", "tradeid":
+			column = &schema.tradeID
+		case "price":
+			column = &schema.price
+		case "qty", "quantity":
+			column = &schema.quantity
+		case "quoteqty", "quotequantity", "baseqty":
+			column = &schema.quoteQuantity
+		case "time", "timestamp":
+			column = &schema.timestamp
+		case "isbuyermaker":
+			column = &schema.isBuyerMaker
+		case "isbestmatch":
+			column = &schema.isBestMatch
+		default:
+			continue
+		}
+		if *column >= 0 {
+			return nil
+		}
+		*column = i
+		schema.columnCount = max(schema.columnCount, i+1)
+	}
+
+	for _, column := range []int{schema.tradeID, schema.price, schema.quantity, schema.quoteQuantity, schema.timestamp, schema.isBuyerMaker} {
+		if column < 0 {
+			return nil
+		}
+	}
+	return &schema
+}
+
+// tradeSchemaFor is the readCSVRows schemaFor function of trade files
+func tradeSchemaFor(header []string, columns int) Schema {
+	return &tradeRowSchema{tradeSchema: detectTradeSchema(header, columns)}
+}
+
+// tradeRowSchema parses the rows of one trades file into the same Trade, so passing
+// millions of records through readCSVRows does not allocate for each of them. A
+// record is only valid until the next row is parsed.
+type tradeRowSchema struct {
+	*tradeSchema
+	trade Trade
+}
+
+func (s *tradeRowSchema) ParseRecord(record []string) (interface{}, error) {
+	trade, err := parseTradeRecord(record, s.tradeSchema)
+	if err != nil {
+		return nil, err
+	}
+	s.trade = trade
+	return &s.trade, nil
+}
+
+func (s *tradeSchema) ColumnCount() int {
+	return s.columnCount
+}
+
+func (s *tradeSchema) ParseRecord(record []string) (interface{}, error) {
+	return parseTradeRecord(record, s)
+}
+
+// parseTradeRecord converts a CSV record into a Trade using the given column layout.
+// IsBestMatch defaults to true when the schema has no best-match column.
+func parseTradeRecord(record []string, schema *tradeSchema) (Trade, error) {
+	var trade Trade
+	var err error
+
+	trade.TradeID, err = strconv.ParseInt(record[schema.tradeID], 10, 64)
+	if err != nil {
+		return trade, fmt.Errorf("invalid TradeId: %w", err)
+	}
+
+	trade.Price, err = strconv.ParseFloat(record[schema.price], 64)
+	if err != nil {
+		return trade, fmt.Errorf("invalid Price: %w", err)
+	}
+
+	trade.Quantity, err = strconv.ParseFloat(record[schema.quantity], 64)
+	if err != nil {
+		return trade, fmt.Errorf("invalid Quantity: %w", err)
+	}
+
+	trade.QuoteQuantity, err = strconv.ParseFloat(record[schema.quoteQuantity], 64)
+	if err != nil {
+		return trade, fmt.Errorf("invalid QuoteQuantity: %w", err)
+	}
+
+	trade.Timestamp, err = strconv.ParseInt(record[schema.timestamp], 10, 64)
+	if err != nil {
+		return trade, fmt.Errorf("invalid Timestamp: %w", err)
+	}
+
+	trade.IsBuyerMaker, err = parseBool(record[schema.isBuyerMaker])
+	if err != nil {
+		return trade, fmt.Errorf("invalid IsBuyerMaker: %w", err)
+	}
+
+	trade.IsBestMatch = true
+	if schema.isBestMatch >= 0 {
+		trade.IsBestMatch, err = parseBool(record[schema.isBestMatch])
+		if err != nil {
+			return trade, fmt.Errorf("invalid IsBestMatch: %w", err)
+		}
+	}
+
+	return trade, nil
+}
+
+// parseBool parses boolean values as written by Binance ("true"/"false", "True"/"False")
+func parseBool(s string) (bool, error) {
+	return strconv.ParseBool(strings.TrimSpace(s))
+}
+
+// isNumeric reports whether s consists only of digits
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,820 @@
+package binancevisionconnector
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseTradeRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  []string
+		schema  *tradeSchema
+		want    Trade
+		wantErr bool
+	}{
+		{
+			name:   "spot 7-column record",
+			record: []string{"123456789", "0.001234", "100.0", "0.1234", "1735430400000", "true", "false"},
+			schema: &spotTradeSchema,
+			want:   Trade{TradeID: 123456789, Price: 0.001234, Quantity: 100, QuoteQuantity: 0.1234, Timestamp: 1735430400000, IsBuyerMaker: true, IsBestMatch: false},
+		},
+		{
+			name:   "futures 6-column record defaults IsBestMatch",
+			record: []string{"123456789", "94000.5", "0.010", "940.005", "1735430400000", "false"},
+			schema: &futuresTradeSchema,
+			want:   Trade{TradeID: 123456789, Price: 94000.5, Quantity: 0.01, QuoteQuantity: 940.005, Timestamp: 1735430400000, IsBuyerMaker: false, IsBestMatch: true},
+		},
+		{
+			name:    "invalid price",
+			record:  []string{"123456789", "abc", "100.0", "0.1234", "1735430400000", "true", "true"},
+			schema:  &spotTradeSchema,
+			wantErr: true,
+		},
+		{
+			name:    "invalid best match",
+			record:  []string{"123456789", "0.001234", "100.0", "0.1234", "1735430400000", "true", "maybe"},
+			schema:  &spotTradeSchema,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTradeRecord(tt.record, tt.schema)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTradeRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseTradeRecord() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVStreaming_Schemas(t *testing.T) {
+	tests := []struct {
+		name      string
+		csv       string
+		wantCount int
+		wantBest  bool
+	}{
+		{
+			name:      "spot file",
+			csv:       "1,0.5,10,5,1735430400000,true,false\n2,0.6,10,6,1735430401000,false,false\n",
+			wantCount: 2,
+			wantBest:  false,
+		},
+		{
+			name:      "futures file",
+			csv:       "1,0.5,10,5,1735430400000,true\n2,0.6,10,6,1735430401000,false\n",
+			wantCount: 2,
+			wantBest:  true,
+		},
+		{
+			name:      "futures file with a short first row",
+			csv:       "1,0.5\n2,0.6,10,6,1735430401000,false\n3,0.7,10,7,1735430402000,true\n",
+			wantCount: 2,
+			wantBest:  true,
+		},
+		{
+			name:      "spot file with an unparseable first row",
+			csv:       "1,oops,10,5,1735430400000,true\n2,0.6,10,6,1735430401000,false,false\n",
+			wantCount: 1,
+			wantBest:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades, stats, err := NewParser().parseCSVStreaming(context.Background(), strings.NewReader(tt.csv), 0, 0)
+			if err != nil {
+				t.Fatalf("parseCSVStreaming() error = %v", err)
+			}
+			if len(trades) != tt.wantCount {
+				t.Fatalf("parseCSVStreaming() parsed %d trades, want %d (stats %+v)", len(trades), tt.wantCount, stats)
+			}
+			for _, trade := range trades {
+				if trade.IsBestMatch != tt.wantBest {
+					t.Errorf("trade %d IsBestMatch = %v, want %v", trade.TradeID, trade.IsBestMatch, tt.wantBest)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSVStreaming_HeaderColumnOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		csv      string
+		wantBest bool
+	}{
+		{
+			name:     "standard futures header",
+			csv:      "id,price,qty,quote_qty,time,is_buyer_maker\n7,0.5,10,5,1735430400000,true\n",
+			wantBest: true,
+		},
+		{
+			name:     "reordered columns",
+			csv:      "time,is_buyer_maker,price,id,quote_qty,qty\n1735430400000,true,0.5,7,5,10\n",
+			wantBest: true,
+		},
+		{
+			name:     "reordered spot columns with extra column",
+			csv:      "Price,Qty,Symbol,Trade ID,Quote Qty,Timestamp,Is Best Match,Is Buyer Maker\n0.5,10,BTCUSDT,7,5,1735430400000,false,true\n",
+			wantBest: false,
+		},
+		{
+			name:     "unrecognized header falls back to positions",
+			csv:      "a,b,c,d,e,f,g\n7,0.5,10,5,1735430400000,true,false\n",
+			wantBest: false,
+		},
+	}
+
+	want := Trade{TradeID: 7, Price: 0.5, Quantity: 10, QuoteQuantity: 5, Timestamp: 1735430400000, IsBuyerMaker: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades, stats, err := NewParser().parseCSVStreaming(context.Background(), strings.NewReader(tt.csv), 0, 0)
+			if err != nil {
+				t.Fatalf("parseCSVStreaming() error = %v", err)
+			}
+			if len(trades) != 1 {
+				t.Fatalf("parseCSVStreaming() parsed %d trades, want 1 (stats %+v)", len(trades), stats)
+			}
+			want.IsBestMatch = tt.wantBest
+			if trades[0] != want {
+				t.Errorf("trade = %+v, want %+v", trades[0], want)
+			}
+		})
+	}
+}
+
+func TestHeaderTradeSchema_Incomplete(t *testing.T) {
+	for _, header := range [][]string{
+		nil,
+		{"id", "price", "qty", "quote_qty", "time"},
+		{"id", "price", "qty", "quote_qty", "time", "is_buyer_maker", "price"},
+	} {
+		if schema := headerTradeSchema(header); schema != nil {
+			t.Errorf("headerTradeSchema(%q) = %+v, want nil", header, schema)
+		}
+	}
+}
+
+func TestParseCSVStreaming_DelimiterAndQuotes(t *testing.T) {
+	tests := []struct {
+		name       string
+		comma      rune
+		lazyQuotes bool
+		csv        string
+		wantCount  int
+		wantErr    bool
+	}{
+		{"semicolons", ';', false, "1;0.5;10;5;1735430400000;true;true\n2;0.6;10;6;1735430401000;false;true\n", 2, false},
+		{"tabs", '\t', false, "1\t0.5\t10\t5\t1735430400000\ttrue\ttrue\n", 1, false},
+		{"commas with a semicolon delimiter", ';', false, "1,0.5,10,5,1735430400000,true,true\n", 0, false},
+		{"stray quote", ',', false, "1,0.5\",10,5,1735430400000,true,true\n", 0, true},
+		{"stray quote with lazy quotes", ',', true, "1,0.5,10,5,1735430400000,true,true\n2,0.6,10,6\",1735430401000,false,true\n", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser()
+			p.comma = tt.comma
+			p.lazyQuotes = tt.lazyQuotes
+			trades, stats, err := p.parseCSVStreaming(context.Background(), strings.NewReader(tt.csv), 0, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCSVStreaming() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(trades) != tt.wantCount {
+				t.Errorf("parseCSVStreaming() parsed %d trades, want %d (stats %+v)", len(trades), tt.wantCount, stats)
+			}
+		})
+	}
+}
+
+func TestParseCSVStreaming_LogsSkippedRowsAtDebug(t *testing.T) {
+	const csvData = "1,0.5,10,5,1735430400000,true,true\n2,abc,10,5,1735430400001,true,true\n3,0.5\n"
+
+	tests := []struct {
+		name      string
+		level     slog.Level
+		wantLines int
+	}{
+		{"debug", slog.LevelDebug, 2},
+		{"info", slog.LevelInfo, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: tt.level}))
+			ctx := ContextWithLogger(context.Background(), logger)
+
+			if _, _, err := NewParser().parseCSVStreaming(ctx, strings.NewReader(csvData), 0, 0); err != nil {
+				t.Fatalf("parseCSVStreaming() error = %v", err)
+			}
+
+			if got := strings.Count(buf.String(), "skipped CSV row"); got != tt.wantLines {
+				t.Errorf("logged %d skipped rows, want %d:\n%s", got, tt.wantLines, buf.String())
+			}
+			if tt.wantLines > 0 && !strings.Contains(buf.String(), "line=2") {
+				t.Errorf("log does not include the line number:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestParseZip_ErrorDetails(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"a.csv": "1,0.5,10,5,1735430400000,true,true\n2,abc,10,5,1735430400001,true,true\n3,0.5\n",
+		"b.csv": "4,0.5,10,5,1735430400002,true,true\n5,0.5,10,5,bad,true,true\n",
+	})
+
+	tests := []struct {
+		name        string
+		maxDetails  int
+		wantDetails int
+	}{
+		{"disabled", 0, 0},
+		{"capped across files", 2, 2},
+		{"all skipped rows", 10, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser()
+			p.maxErrorDetails = tt.maxDetails
+			result, err := p.ParseZip(context.Background(), zipData)
+			if err != nil {
+				t.Fatalf("ParseZip() error = %v", err)
+			}
+
+			details := result.Stats.ErrorDetails
+			if len(details) != tt.wantDetails {
+				t.Fatalf("got %d error details, want %d: %+v", len(details), tt.wantDetails, details)
+			}
+			for _, detail := range details {
+				switch detail.Line {
+				case 2:
+					if detail.RawRecord != "2,abc,10,5,1735430400001,true,true" && detail.RawRecord != "5,0.5,10,5,bad,true,true" {
+						t.Errorf("line 2 RawRecord = %q", detail.RawRecord)
+					}
+				case 3:
+					if detail.RawRecord != "3,0.5" || !strings.Contains(detail.Error, "expected 7 columns") {
+						t.Errorf("line 3 detail = %+v", detail)
+					}
+				default:
+					t.Errorf("unexpected detail %+v", detail)
+				}
+				if detail.Error == "" {
+					t.Errorf("detail without error: %+v", detail)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSVStreaming_HeaderDetection(t *testing.T) {
+	const rows = "123456789,0.5,10,5,1735430400000,true,true\n123456790,0.6,10,6,1735430401000,false,true\n"
+
+	tests := []struct {
+		name      string
+		csv       string
+		wantFirst int64
+	}{
+		{"headerless file keeps the first trade", rows, 123456789},
+		{"header row is skipped", "trade_id,price,qty,quote_qty,time,is_buyer_maker,is_best_match\n" + rows, 123456789},
+		{"headerless file with BOM keeps the first trade", "\ufeff" + rows, 123456789},
+		{"header row with BOM is skipped", "\ufeffid,price,qty,quote_qty,time,is_buyer_maker,is_best_match\n" + rows, 123456789},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades, stats, err := NewParser().parseCSVStreaming(context.Background(), strings.NewReader(tt.csv), 0, 0)
+			if err != nil {
+				t.Fatalf("parseCSVStreaming() error = %v", err)
+			}
+			if len(trades) != 2 {
+				t.Fatalf("parseCSVStreaming() parsed %d trades, want 2 (stats %+v)", len(trades), stats)
+			}
+			if trades[0].TradeID != tt.wantFirst {
+				t.Errorf("first TradeID = %d, want %d", trades[0].TradeID, tt.wantFirst)
+			}
+			if stats.SkippedShort+stats.SkippedParseError != 0 {
+				t.Errorf("unexpected skipped rows: %+v", stats)
+			}
+		})
+	}
+}
+
+func TestSortTrades(t *testing.T) {
+	newTrades := func() []Trade {
+		return []Trade{
+			{TradeID: 3, Timestamp: 100},
+			{TradeID: 1, Timestamp: 300},
+			{TradeID: 2, Timestamp: 100},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		key     TradeSortKey
+		wantIDs []int64
+	}{
+		{"by trade id", SortByTradeID, []int64{1, 2, 3}},
+		{"by timestamp keeps ties stable", SortByTimestamp, []int64{3, 2, 1}},
+		{"unknown key sorts by trade id", "", []int64{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades := newTrades()
+			sortTrades(trades, tt.key)
+			for i, want := range tt.wantIDs {
+				if trades[i].TradeID != want {
+					t.Fatalf("sortTrades(%q) order = %+v, want ids %v", tt.key, trades, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSVStreaming_MaxTrades(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id,price,qty,quote_qty,time,is_buyer_maker,is_best_match\n")
+	for id := 1; id <= 10; id++ {
+		fmt.Fprintf(&sb, "%d,0.5,10,5,1735430400000,true,true\n", id)
+	}
+	csvData := sb.String()
+
+	tests := []struct {
+		name          string
+		maxTrades     int
+		wantCount     int
+		wantTruncated int
+	}{
+		{"no limit", 0, 10, 0},
+		{"limit below row count", 3, 3, 1},
+		{"limit equal to row count", 10, 10, 0},
+		{"limit above row count", 20, 10, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades, stats, err := NewParser().parseCSVStreaming(context.Background(), strings.NewReader(csvData), tt.maxTrades, 0)
+			if err != nil {
+				t.Fatalf("parseCSVStreaming() error = %v", err)
+			}
+			if len(trades) != tt.wantCount {
+				t.Fatalf("parseCSVStreaming() parsed %d trades, want %d", len(trades), tt.wantCount)
+			}
+			if trades[len(trades)-1].TradeID != int64(tt.wantCount) {
+				t.Errorf("last trade id = %d, want %d", trades[len(trades)-1].TradeID, tt.wantCount)
+			}
+			if stats.TruncatedFiles != tt.wantTruncated {
+				t.Errorf("TruncatedFiles = %d, want %d", stats.TruncatedFiles, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestDeduplicateTrades(t *testing.T) {
+	trades := []Trade{
+		{TradeID: 1, Price: 1},
+		{TradeID: 2, Price: 2},
+		{TradeID: 1, Price: 99},
+		{TradeID: 3, Price: 3},
+		{TradeID: 2, Price: 99},
+	}
+
+	got, removed := deduplicateTrades(trades)
+	if removed != 2 {
+		t.Errorf("deduplicateTrades() removed %d, want 2", removed)
+	}
+	want := []Trade{{TradeID: 1, Price: 1}, {TradeID: 2, Price: 2}, {TradeID: 3, Price: 3}}
+	if len(got) != len(want) {
+		t.Fatalf("deduplicateTrades() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("deduplicateTrades()[%d] = %+v, want %+v (first occurrence)", i, got[i], want[i])
+		}
+	}
+}
+
+// createTestZip builds a zip archive containing the given files
+func createTestZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestStreamZip(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"part1.csv": "1,0.5,10,5,1735430400000,true,true\n2,0.5,10,5,1735430400001,true,true\n",
+		"part2.csv": "3,0.5,10,5,1735430400002,true,true\nbad,row\n",
+	})
+
+	t.Run("emits every trade", func(t *testing.T) {
+		seen := make(map[int64]bool)
+		result, err := NewParser().StreamZip(context.Background(), zipData, func(trade Trade) error {
+			seen[trade.TradeID] = true
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamZip() error = %v", err)
+		}
+		if len(seen) != 3 {
+			t.Errorf("StreamZip() emitted %d trades, want 3", len(seen))
+		}
+		if result.Trades != nil {
+			t.Errorf("StreamZip() result has %d trades, want none", len(result.Trades))
+		}
+		if result.Stats.SkippedShort != 1 {
+			t.Errorf("SkippedShort = %d, want 1", result.Stats.SkippedShort)
+		}
+	})
+
+	t.Run("stops on emit error", func(t *testing.T) {
+		errStop := errors.New("client gone")
+		emitted := 0
+		_, err := NewParser().StreamZip(context.Background(), zipData, func(trade Trade) error {
+			emitted++
+			return errStop
+		})
+		if !errors.Is(err, errStop) {
+			t.Fatalf("StreamZip() error = %v, want %v", err, errStop)
+		}
+		if emitted != 1 {
+			t.Errorf("emit called %d times after failing, want 1", emitted)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	csvData := "id,price,qty,quote_qty,time,is_buyer_maker,is_best_match\n" +
+		"1,0.5,10,5,1735430400000,true,true\n" +
+		"2,oops,10,5,1735430400001,true,true\n" +
+		"3,0.7,10,7,1735430400002,false,true\n"
+
+	trades, err := Parse(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(trades) != 2 || trades[0].TradeID != 1 || trades[1].TradeID != 3 {
+		t.Errorf("Parse() = %+v, want trades 1 and 3", trades)
+	}
+
+	_, stats, err := NewParser().ParseCSV(context.Background(), strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("ParseCSV() error = %v", err)
+	}
+	if stats.SkippedParseError != 1 {
+		t.Errorf("ParseCSV() SkippedParseError = %d, want 1", stats.SkippedParseError)
+	}
+}
+
+func TestParse_Cancelled(t *testing.T) {
+	var csvData strings.Builder
+	rows := 5 * ctxCheckInterval
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&csvData, "%d,0.5,10,5,1735430400000,true,true\n", i)
+	}
+
+	t.Run("ParseCSV", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, _, err := NewParser().ParseCSV(ctx, strings.NewReader(csvData.String())); !errors.Is(err, context.Canceled) {
+			t.Errorf("ParseCSV() error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("StreamZip stops promptly", func(t *testing.T) {
+		zipData := createTestZip(t, map[string]string{"trades.csv": csvData.String()})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		emitted := 0
+		_, err := NewParser().StreamZip(ctx, zipData, func(Trade) error {
+			emitted++
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("StreamZip() error = %v, want context.Canceled", err)
+		}
+		if emitted > ctxCheckInterval {
+			t.Errorf("StreamZip() emitted %d trades after cancellation, want at most %d", emitted, ctxCheckInterval)
+		}
+	})
+}
+
+func TestParseZip_NotAZipFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"html maintenance page", []byte("<html><body>Down for maintenance</body></html>"), ErrNotAZipFile},
+		{"empty body", nil, ErrNotAZipFile},
+		{"valid archive", createTestZip(t, map[string]string{"a.csv": "1,0.5,10,5,1735430400000,true,true\n"}), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser().ParseZip(context.Background(), tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseZip() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(tt.data) > 0 && !strings.Contains(err.Error(), "<html>") {
+				t.Errorf("ParseZip() error %q should include the start of the body", err)
+			}
+		})
+	}
+}
+
+func TestParseZip_NestedArchives(t *testing.T) {
+	const row = "1,0.5,10,5,1735430400000,true,true\n"
+	inner := string(createTestZip(t, map[string]string{"BTCUSDT-trades-2025-01-01.csv": row}))
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		maxNested  int64
+		wantTrades int
+		wantErr    string
+	}{
+		{"nested zip", map[string]string{"BTCUSDT-trades-2025-01-01.zip": inner}, 0, 1, ""},
+		{"nested zip alongside csv", map[string]string{"inner.zip": inner, "outer.csv": row}, 0, 2, ""},
+		{"beyond depth limit", map[string]string{"outer.zip": string(createTestZip(t, map[string]string{"inner.zip": inner}))}, 0, 0, "no CSV files found"},
+		{"nested entry is not a zip", map[string]string{"broken.zip": "<html>"}, 0, 0, "not a zip file"},
+		{"no csv at all", map[string]string{"readme.txt": "hello"}, 0, 0, "no CSV files found"},
+		{"nested zip within the limit", map[string]string{"inner.zip": inner}, int64(len(inner)), 1, ""},
+		{"nested zip over the limit", map[string]string{"inner.zip": inner}, int64(len(inner)) - 1, 0, ErrUncompressedTooLarge.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser()
+			p.maxNested = tt.maxNested
+			result, err := p.ParseZip(context.Background(), createTestZip(t, tt.files))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseZip() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseZip() error = %v", err)
+			}
+			if len(result.Trades) != tt.wantTrades {
+				t.Errorf("ParseZip() returned %d trades, want %d", len(result.Trades), tt.wantTrades)
+			}
+		})
+	}
+}
+
+func TestParseZip_PartialOK(t *testing.T) {
+	good := "1,0.5,10,5,1735430400000,true,true\n2,0.5,10,5,1735430400001,true,true\n"
+	bad := "3,\"0.5,10,5,1735430400002,true,true\n"
+
+	tests := []struct {
+		name         string
+		files        map[string]string
+		partialOK    bool
+		wantTrades   int
+		wantWarnings int
+		wantErr      bool
+	}{
+		{"all files parse", map[string]string{"a.csv": good}, true, 2, 0, false},
+		{"failed file fails the archive", map[string]string{"a.csv": good, "b.csv": bad}, false, 0, 0, true},
+		{"failed file becomes a warning", map[string]string{"a.csv": good, "b.csv": bad}, true, 2, 1, false},
+		{"every file failed", map[string]string{"b.csv": bad, "c.csv": bad}, true, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser()
+			p.partialOK = tt.partialOK
+			result, err := p.ParseZip(context.Background(), createTestZip(t, tt.files))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ParseZip() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseZip() error = %v", err)
+			}
+			if len(result.Trades) != tt.wantTrades || len(result.Warnings) != tt.wantWarnings {
+				t.Fatalf("ParseZip() returned %d trades and warnings %q, want %d trades and %d warnings",
+					len(result.Trades), result.Warnings, tt.wantTrades, tt.wantWarnings)
+			}
+			for _, warning := range result.Warnings {
+				if !strings.Contains(warning, "b.csv") {
+					t.Errorf("warning %q does not name the failed file", warning)
+				}
+			}
+		})
+	}
+}
+
+func TestParseZip_SkippedRowStats(t *testing.T) {
+	row := "1,0.5,10,5,1735430400000,true,true\n"
+	zipData := createTestZip(t, map[string]string{
+		"a.csv": row + "2,0.5\n" + "3,oops,10,5,1735430400000,true,true\n",
+		"b.csv": strings.Repeat("4,0.5\n", 3) + strings.Repeat("5,0.5,10,5,1735430400000,maybe,true\n", 2) + row,
+	})
+
+	result, err := NewParser().ParseZip(context.Background(), zipData)
+	if err != nil {
+		t.Fatalf("ParseZip() error = %v", err)
+	}
+	stats := result.Stats
+	if stats.SkippedShort != 4 || stats.SkippedParseError != 3 || stats.Skipped() != 7 {
+		t.Errorf("Stats = %d short, %d parse errors, %d skipped, want 4, 3 and 7",
+			stats.SkippedShort, stats.SkippedParseError, stats.Skipped())
+	}
+	if len(stats.SampleErrors) != maxSampleErrors {
+		t.Errorf("SampleErrors has %d entries, want the first %d", len(stats.SampleErrors), maxSampleErrors)
+	}
+	for _, sample := range stats.SampleErrors {
+		if !strings.HasPrefix(sample, "line ") {
+			t.Errorf("sample %q does not start with its line number", sample)
+		}
+	}
+	if len(result.Trades) != 2 {
+		t.Errorf("ParseZip() returned %d trades, want 2", len(result.Trades))
+	}
+}
+
+func TestProcessCSVFiles_Concurrency(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 6; i++ {
+		files[fmt.Sprintf("part%d.csv", i)] = "1,0.5,10,5,1735430400000,true,true\n"
+	}
+	zipData := createTestZip(t, files)
+
+	for _, limit := range []int{1, 2} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			var active, peak atomic.Int32
+			p := &Parser{concurrency: limit}
+			_, err := p.processCSVFiles(context.Background(), bytes.NewReader(zipData), int64(len(zipData)), func(r io.Reader, size int64) error {
+				n := active.Add(1)
+				defer active.Add(-1)
+				for {
+					old := peak.Load()
+					if n <= old || peak.CompareAndSwap(old, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("processCSVFiles() error = %v", err)
+			}
+			if got := peak.Load(); got > int32(limit) {
+				t.Errorf("%d files parsed at once, want at most %d", got, limit)
+			}
+		})
+	}
+}
+
+func TestParseZip_NoCSVFiles(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.zip.CHECKSUM": "abc",
+		"README.txt":                             "hello",
+	})
+
+	_, err := NewParser().ParseZip(context.Background(), zipData)
+	if !errors.Is(err, ErrNoCSVFiles) {
+		t.Fatalf("ParseZip() error = %v, want ErrNoCSVFiles", err)
+	}
+	for _, name := range []string{"BTCUSDT-trades-2025-01-01.zip.CHECKSUM", "README.txt"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("ParseZip() error = %v, want it to list %s", err, name)
+		}
+	}
+}
+
+func TestParseZip_UncompressedBudget(t *testing.T) {
+	row := "1,0.5,10,5,1735430400000,true,true\n"
+	zipData := createTestZip(t, map[string]string{
+		"part1.csv": strings.Repeat(row, 100),
+		"part2.csv": strings.Repeat(row, 100),
+	})
+	total := int64(2 * 100 * len(row))
+
+	tests := []struct {
+		name      string
+		limit     int64
+		partialOK bool
+		wantErr   bool
+	}{
+		{"unlimited", 0, false, false},
+		{"within budget", total, false, false},
+		{"over budget", total - 1, false, true},
+		{"over budget in partial mode", total / 2, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{maxUncompressed: tt.limit, partialOK: tt.partialOK}
+			_, err := p.ParseZip(context.Background(), zipData)
+			if tt.wantErr != errors.Is(err, ErrUncompressedTooLarge) {
+				t.Fatalf("ParseZip() error = %v, want ErrUncompressedTooLarge: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("ParseZip() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestBudgetReader_UnderstatedHeader(t *testing.T) {
+	// The declared sizes pass the header check, so the reader must catch the overrun
+	budget := &uncompressedBudget{limit: 10}
+	_, err := io.ReadAll(budget.reader(strings.NewReader(strings.Repeat("x", 11))))
+	if !errors.Is(err, ErrUncompressedTooLarge) {
+		t.Errorf("ReadAll() error = %v, want ErrUncompressedTooLarge", err)
+	}
+}
+
+func TestTradeCapacity(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int64
+		budget    int64
+		maxTrades int
+		want      int
+	}{
+		{"unknown size", 0, 0, 0, defaultTradeCapacity},
+		{"unknown size with limit", 0, 0, 100, 100},
+		{"small file", 640, 0, 0, 11},
+		{"large file", 64 << 20, 0, 0, maxEstimatedTradeRows},
+		{"limited by maxTrades", 64 << 20, 0, 5000, 5000},
+		{"capped", 1 << 40, 0, 0, maxEstimatedTradeRows},
+		{"clamped by budget", 1 << 40, 6400, 0, 101},
+		{"within budget", 640, 1 << 20, 0, 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tradeCapacity(tt.size, tt.budget, tt.maxTrades); got != tt.want {
+				t.Errorf("tradeCapacity(%d, %d, %d) = %d, want %d", tt.size, tt.budget, tt.maxTrades, got, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkParseCSVStreaming compares parsing with and without a size hint; the
+// sized run should report far fewer allocated bytes since the slice grows fewer times.
+func BenchmarkParseCSVStreaming(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(&sb, "%d,42000.50,0.001,21.0005,1700000000000,true,true\n", i)
+	}
+	csvData := sb.String()
+
+	for _, bm := range []struct {
+		name     string
+		sizeHint int64
+	}{
+		{"unsized", 0},
+		{"sized", int64(len(csvData))},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			p := NewParser()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := p.parseCSVStreaming(context.Background(), strings.NewReader(csvData), 0, bm.sizeHint); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
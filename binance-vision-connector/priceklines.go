@@ -0,0 +1,203 @@
+package binancevisionconnector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// KlineIntervals lists the kline intervals Binance Vision publishes daily archives for
+var KlineIntervals = []string{"1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d"}
+
+// ValidKlineInterval reports whether interval is one of KlineIntervals
+func ValidKlineInterval(interval string) bool {
+	return slices.Contains(KlineIntervals, interval)
+}
+
+// Price kline datasets of USDⓈ-M futures. All use the kline schema, but their
+// volume and trade count columns are always zero, so only prices are parsed.
+const (
+	MarkPriceKlines    = "markPriceKlines"
+	IndexPriceKlines   = "indexPriceKlines"
+	PremiumIndexKlines = "premiumIndexKlines"
+)
+
+// PriceKline is one candle of a mark price, index price or premium index kline archive
+type PriceKline struct {
+	OpenTime  int64   `json:"open_time"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	CloseTime int64   `json:"close_time"`
+}
+
+// PriceKlineResult contains the downloaded price klines
+type PriceKlineResult struct {
+	Symbol      string       `json:"symbol"`
+	Dataset     string       `json:"dataset"` // MarkPriceKlines, IndexPriceKlines or PremiumIndexKlines
+	Interval    string       `json:"interval"`
+	Date        string       `json:"date"`
+	RecordCount int          `json:"record_count"`
+	Klines      []PriceKline `json:"klines"`
+	Warnings    []string     `json:"warnings,omitempty"`
+	SourceURL   string       `json:"source_url,omitempty"`
+}
+
+// DownloadMarkPriceKlines downloads and parses mark price klines for a symbol, interval and date
+func (c *Connector) DownloadMarkPriceKlines(ctx context.Context, symbol, interval, year, month, day string) (*PriceKlineResult, error) {
+	return c.downloadPriceKlines(ctx, MarkPriceKlines, symbol, interval, year, month, day)
+}
+
+// DownloadIndexPriceKlines downloads and parses index price klines for a symbol, interval and date
+func (c *Connector) DownloadIndexPriceKlines(ctx context.Context, symbol, interval, year, month, day string) (*PriceKlineResult, error) {
+	return c.downloadPriceKlines(ctx, IndexPriceKlines, symbol, interval, year, month, day)
+}
+
+// DownloadPremiumIndexKlines downloads and parses premium index klines for a symbol, interval
+// and date. Their prices are the premium of the mark price over the index price, as a fraction.
+func (c *Connector) DownloadPremiumIndexKlines(ctx context.Context, symbol, interval, year, month, day string) (*PriceKlineResult, error) {
+	return c.downloadPriceKlines(ctx, PremiumIndexKlines, symbol, interval, year, month, day)
+}
+
+// downloadPriceKlines downloads the price kline dataset archive and parses it
+func (c *Connector) downloadPriceKlines(ctx context.Context, dataset, symbol, interval, year, month, day string) (*PriceKlineResult, error) {
+	if !ValidKlineInterval(interval) {
+		return nil, fmt.Errorf("invalid kline interval: %q", interval)
+	}
+
+	year, month, day = formatDate(year, month, day)
+	downloader := c.getDownloader()
+	url := downloader.URL(fmt.Sprintf("/data/futures/um/daily/%s/%s/%s/%s-%s-%s-%s-%s.zip",
+		dataset, symbol, interval, symbol, interval, year, month, day))
+
+	archive, err := downloader.spoolURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	klines, warnings, err := c.parser.parsePriceKlineZip(ctx, archive, archive.size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+
+	return &PriceKlineResult{
+		Symbol:      symbol,
+		Dataset:     dataset,
+		Interval:    interval,
+		Date:        fmt.Sprintf("%s-%s-%s", year, month, day),
+		RecordCount: len(klines),
+		Klines:      klines,
+		Warnings:    warnings,
+		SourceURL:   url,
+	}, nil
+}
+
+// ParsePriceKlineZip extracts all CSV files from the zip archive and parses them as
+// mark or index price klines, ordered by open time. Per-file failures are returned as
+// warnings when the parser runs in partial mode.
+func (p *Parser) ParsePriceKlineZip(ctx context.Context, zipData []byte) ([]PriceKline, []string, error) {
+	return p.parsePriceKlineZip(ctx, bytes.NewReader(zipData), int64(len(zipData)))
+}
+
+// parsePriceKlineZip is ParsePriceKlineZip for an archive of the given size read through r
+func (p *Parser) parsePriceKlineZip(ctx context.Context, r io.ReaderAt, size int64) ([]PriceKline, []string, error) {
+	var (
+		allKlines []PriceKline
+		mu        sync.Mutex
+	)
+
+	warnings, err := p.processCSVFiles(ctx, r, size, func(r io.Reader, _ int64) error {
+		klines, err := p.parsePriceKlineCSV(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		allKlines = append(allKlines, klines...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Files finish in any order, but candles are only useful in time order
+	sort.SliceStable(allKlines, func(i, j int) bool {
+		return allKlines[i].OpenTime < allKlines[j].OpenTime
+	})
+
+	return allKlines, warnings, nil
+}
+
+// priceKlineSchema is the layout of mark and index price kline files, parsed by
+// parsePriceKlineRecord
+type priceKlineSchema struct{}
+
+func (priceKlineSchema) ColumnCount() int { return 7 }
+
+func (priceKlineSchema) ParseRecord(record []string) (interface{}, error) {
+	return parsePriceKlineRecord(record)
+}
+
+// parsePriceKlineCSV parses price kline CSV records one at a time
+func (p *Parser) parsePriceKlineCSV(ctx context.Context, r io.Reader) ([]PriceKline, error) {
+	// A day of 1m candles is the largest daily file
+	klines := make([]PriceKline, 0, 1440)
+
+	_, err := p.readCSVRows(ctx, r, fixedSchema(priceKlineSchema{}), 0, func(record interface{}) error {
+		klines = append(klines, record.(PriceKline))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return klines, nil
+}
+
+// parsePriceKlineRecord converts a kline CSV record into a PriceKline.
+// Columns: open_time, open, high, low, close, volume, close_time[, quote_volume, count,
+// taker_buy_volume, taker_buy_quote_volume, ignore]
+func parsePriceKlineRecord(record []string) (PriceKline, error) {
+	var kline PriceKline
+	var err error
+
+	kline.OpenTime, err = strconv.ParseInt(record[0], 10, 64)
+	if err != nil {
+		return kline, fmt.Errorf("invalid OpenTime: %w", err)
+	}
+
+	kline.Open, err = strconv.ParseFloat(record[1], 64)
+	if err != nil {
+		return kline, fmt.Errorf("invalid Open: %w", err)
+	}
+
+	kline.High, err = strconv.ParseFloat(record[2], 64)
+	if err != nil {
+		return kline, fmt.Errorf("invalid High: %w", err)
+	}
+
+	kline.Low, err = strconv.ParseFloat(record[3], 64)
+	if err != nil {
+		return kline, fmt.Errorf("invalid Low: %w", err)
+	}
+
+	kline.Close, err = strconv.ParseFloat(record[4], 64)
+	if err != nil {
+		return kline, fmt.Errorf("invalid Close: %w", err)
+	}
+
+	kline.CloseTime, err = strconv.ParseInt(record[6], 10, 64)
+	if err != nil {
+		return kline, fmt.Errorf("invalid CloseTime: %w", err)
+	}
+
+	return kline, nil
+}
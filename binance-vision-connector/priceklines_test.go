@@ -0,0 +1,69 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDownloadPriceKlines(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-1h-2025-01-01.csv": "open_time,open,high,low,close,volume,close_time,quote_volume,count,taker_buy_volume,taker_buy_quote_volume,ignore\n" +
+			"1735693200000,94010.1,94200,93900.5,94100,0,1735696799999,0,60,0,0,0\n" +
+			"1735689600000,94000.5,94050,93950,94010.1,0,1735693199999,0,60,0,0,0\n" +
+			"1735696800000,bad,94300,94000,94250,0,1735700399999,0,60,0,0,0\n",
+	})
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		download func(ctx context.Context, symbol, interval, year, month, day string) (*PriceKlineResult, error)
+		wantPath string
+	}{
+		{"mark price", c.DownloadMarkPriceKlines, "/data/futures/um/daily/markPriceKlines/BTCUSDT/1h/BTCUSDT-1h-2025-01-01.zip"},
+		{"index price", c.DownloadIndexPriceKlines, "/data/futures/um/daily/indexPriceKlines/BTCUSDT/1h/BTCUSDT-1h-2025-01-01.zip"},
+		{"premium index", c.DownloadPremiumIndexKlines, "/data/futures/um/daily/premiumIndexKlines/BTCUSDT/1h/BTCUSDT-1h-2025-01-01.zip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.download(context.Background(), "BTCUSDT", "1h", "2025", "1", "1")
+			if err != nil {
+				t.Fatalf("download error = %v", err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("requested %q, want %q", path, tt.wantPath)
+			}
+			if result.Date != "2025-01-01" || result.SourceURL != server.URL+tt.wantPath {
+				t.Errorf("Date = %q, SourceURL = %q", result.Date, result.SourceURL)
+			}
+			if result.RecordCount != 2 {
+				t.Fatalf("RecordCount = %d, want 2 (malformed rows are skipped)", result.RecordCount)
+			}
+
+			// Candles are ordered by open time
+			want := PriceKline{OpenTime: 1735689600000, Open: 94000.5, High: 94050, Low: 93950, Close: 94010.1, CloseTime: 1735693199999}
+			if result.Klines[0] != want {
+				t.Errorf("Klines[0] = %+v, want %+v", result.Klines[0], want)
+			}
+		})
+	}
+
+	if _, err := c.DownloadMarkPriceKlines(context.Background(), "BTCUSDT", "7m", "2025", "1", "1"); err == nil {
+		t.Error("Expected an error for an unsupported interval")
+	}
+}
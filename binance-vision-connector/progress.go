@@ -0,0 +1,81 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Progress reports how far the downloads and parsing made with one context have got
+type Progress struct {
+	BytesDownloaded int64 `json:"bytes_downloaded"`
+	BytesTotal      int64 `json:"bytes_total,omitempty"` // Archive size, when the server sent Content-Length
+	TradesParsed    int64 `json:"trades_parsed"`
+}
+
+type progressKey struct{}
+
+// ContextWithProgress returns a copy of ctx carrying report, which the connector calls
+// with the accumulated progress of calls made with that context: as archive bytes
+// arrive and every ctxCheckInterval rows parsed. report is called with a lock held, so
+// calls never overlap, but they come from download and parser goroutines and report
+// must return quickly. Cached results are returned without any progress.
+func ContextWithProgress(ctx context.Context, report func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, &progressTracker{report: report})
+}
+
+// progressTracker accumulates the progress reported to a ContextWithProgress callback.
+// A nil tracker ignores updates, so callers need not check for one.
+type progressTracker struct {
+	mu       sync.Mutex
+	progress Progress
+	report   func(Progress)
+}
+
+// progressFromContext returns the tracker stored by ContextWithProgress, or nil
+func progressFromContext(ctx context.Context) *progressTracker {
+	tracker, _ := ctx.Value(progressKey{}).(*progressTracker)
+	return tracker
+}
+
+// update applies change to the progress and reports the result
+func (t *progressTracker) update(change func(*Progress)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	change(&t.progress)
+	t.report(t.progress)
+}
+
+// addBytes counts n more downloaded bytes. Bytes downloaded again after a failed
+// attempt are counted again.
+func (t *progressTracker) addBytes(n int64) {
+	t.update(func(p *Progress) { p.BytesDownloaded += n })
+}
+
+// setTotal records the expected size of the archive being downloaded
+func (t *progressTracker) setTotal(total int64) {
+	t.update(func(p *Progress) { p.BytesTotal = total })
+}
+
+// addTrades counts n more parsed trades
+func (t *progressTracker) addTrades(n int) {
+	if n == 0 {
+		return
+	}
+	t.update(func(p *Progress) { p.TradesParsed += int64(n) })
+}
+
+// progressWriter counts the bytes written through it as downloaded
+type progressWriter struct {
+	w       io.Writer
+	tracker *progressTracker
+}
+
+func (pw progressWriter) Write(p []byte) (int, error) {
+	n, err := pw.w.Write(p)
+	pw.tracker.addBytes(int64(n))
+	return n, err
+}
@@ -0,0 +1,67 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestContextWithProgress(t *testing.T) {
+	var csv strings.Builder
+	for i := 0; i < 2500; i++ {
+		fmt.Fprintf(&csv, "%d,0.5,10,5,%d,true,true\n", i, 1735430400000+int64(i))
+	}
+	zipData := createTestZip(t, map[string]string{"BTCUSDT-trades-2025-01-01.csv": csv.String()})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(zipData)))
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	var reports []Progress
+	ctx := ContextWithProgress(context.Background(), func(p Progress) {
+		reports = append(reports, p)
+	})
+	if _, err := c.DownloadTrades(ctx, "BTCUSDT", "2025", "01", "01"); err != nil {
+		t.Fatalf("DownloadTrades() error = %v", err)
+	}
+
+	if len(reports) == 0 {
+		t.Fatal("no progress reported")
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i].BytesDownloaded < reports[i-1].BytesDownloaded || reports[i].TradesParsed < reports[i-1].TradesParsed {
+			t.Errorf("progress went backwards: %+v after %+v", reports[i], reports[i-1])
+		}
+	}
+
+	// Trades are reported every ctxCheckInterval rows and once more at the end of the file
+	last := reports[len(reports)-1]
+	if last.BytesDownloaded != int64(len(zipData)) || last.BytesTotal != int64(len(zipData)) {
+		t.Errorf("bytes = %d of %d, want %d of %d", last.BytesDownloaded, last.BytesTotal, len(zipData), len(zipData))
+	}
+	if last.TradesParsed != 2500 {
+		t.Errorf("TradesParsed = %d, want 2500", last.TradesParsed)
+	}
+	var tradeReports int
+	for i := 1; i < len(reports); i++ {
+		if reports[i].TradesParsed != reports[i-1].TradesParsed {
+			tradeReports++
+		}
+	}
+	if tradeReports != 3 {
+		t.Errorf("trade progress reported %d times, want 3", tradeReports)
+	}
+}
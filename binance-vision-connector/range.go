@@ -0,0 +1,117 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// rangeLookahead is the number of days StreamRange downloads ahead of the day it streams
+const rangeLookahead = 2
+
+// prefetchedDay is a day's trade archive downloaded ahead of time by StreamRange
+type prefetchedDay struct {
+	year, month, day string
+	downloader       *Downloader
+	archive          *spooledArchive // nil if err is set
+	downloadTime     time.Duration
+	err              error
+}
+
+// StreamRange streams the trades of every day from from to to (inclusive) to emit,
+// one day after another. Each daily archive is already in trade order, so trades
+// arrive in global timestamp order while only one day is parsed at a time. Up to
+// rangeLookahead following days are downloaded while a day is being streamed.
+//
+// Days without an archive are skipped; the returned per-day results, which have
+// TradeCount set but no Trades as with StreamTrades, show which days were streamed.
+// Any other failure, or an error from emit, stops the stream and is returned along
+// with the results of the days streamed before it. Every download shares ctx, so its
+// deadline bounds the whole range rather than each day, and a caller whose deadline
+// passes still learns which days completed.
+func (c *Connector) StreamRange(ctx context.Context, symbol string, from, to time.Time, emit func(Trade) error) ([]*DownloadResult, error) {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s is after %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	// Each day gets a channel its download is delivered on. The buffer of pending
+	// days bounds how far the downloads run ahead of the day being streamed.
+	pending := make(chan chan prefetchedDay, rangeLookahead)
+	go func() {
+		defer close(pending)
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			ready := make(chan prefetchedDay, 1)
+			select {
+			case pending <- ready:
+			case <-ctx.Done():
+				return
+			}
+			go func() {
+				ready <- c.prefetchDay(ctx, symbol, day)
+			}()
+		}
+	}()
+
+	// Remove the spool files of days downloaded ahead but never streamed
+	defer func() {
+		cancel()
+		for ready := range pending {
+			if prefetched := <-ready; prefetched.archive != nil {
+				prefetched.archive.Close()
+			}
+		}
+	}()
+
+	var results []*DownloadResult
+	for ready := range pending {
+		prefetched := <-ready
+		date := fmt.Sprintf("%s-%s-%s", prefetched.year, prefetched.month, prefetched.day)
+		if prefetched.err == nil && ctx.Err() != nil {
+			// The deadline passed while an earlier day streamed
+			prefetched.archive.Close()
+			prefetched.err = ctx.Err()
+		}
+		if errors.Is(prefetched.err, ErrArchiveNotFound) {
+			continue
+		}
+		if prefetched.err != nil {
+			return results, fmt.Errorf("%s: %w", date, prefetched.err)
+		}
+
+		result, err := c.streamArchive(ctx, c.parser, prefetched.downloader, prefetched.archive, symbol,
+			prefetched.year, prefetched.month, prefetched.day, prefetched.downloadTime, emit)
+		prefetched.archive.Close()
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", date, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// prefetchDay downloads the trade archive of symbol for day into a spool file
+func (c *Connector) prefetchDay(ctx context.Context, symbol string, day time.Time) prefetchedDay {
+	prefetched := prefetchedDay{
+		year:       day.Format("2006"),
+		month:      day.Format("01"),
+		day:        day.Format("02"),
+		downloader: c.getDownloader(),
+	}
+
+	date := fmt.Sprintf("%s-%s-%s", prefetched.year, prefetched.month, prefetched.day)
+	if prefetched.err = c.checkSymbolIndex(symbol, date); prefetched.err != nil {
+		return prefetched
+	}
+
+	start := time.Now()
+	prefetched.archive, prefetched.err = prefetched.downloader.spoolURL(ctx,
+		prefetched.downloader.TradesURL(symbol, prefetched.year, prefetched.month, prefetched.day))
+	prefetched.downloadTime = time.Since(start)
+	return prefetched
+}
@@ -0,0 +1,178 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRangeServer serves a one-file trade archive for each day in days, with trade IDs
+// numbered from the day of month times 100, and 404 for any other day
+func newRangeServer(t *testing.T, days ...string) *httptest.Server {
+	t.Helper()
+	archives := make(map[string][]byte)
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var csv strings.Builder
+		for i := 0; i < 3; i++ {
+			id := date.Day()*100 + i
+			fmt.Fprintf(&csv, "%d,0.5,10,5,%d,true,true\n", id, date.UnixMilli()+int64(i))
+		}
+		archives[fmt.Sprintf("BTCUSDT-trades-%s.zip", day)] = createTestZip(t, map[string]string{"trades.csv": csv.String()})
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := archives[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+}
+
+func TestStreamRange(t *testing.T) {
+	// 2025-01-03 is missing and skipped
+	server := newRangeServer(t, "2025-01-01", "2025-01-02", "2025-01-04")
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.SpoolDir = t.TempDir()
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	var ids []int64
+	var lastTimestamp int64
+	results, err := c.StreamRange(context.Background(), "BTCUSDT",
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 4, 12, 0, 0, 0, time.UTC),
+		func(trade Trade) error {
+			if trade.Timestamp < lastTimestamp {
+				t.Errorf("trade %d at %d arrived after %d", trade.TradeID, trade.Timestamp, lastTimestamp)
+			}
+			lastTimestamp = trade.Timestamp
+			ids = append(ids, trade.TradeID)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("StreamRange() error = %v", err)
+	}
+
+	want := []int64{100, 101, 102, 200, 201, 202, 400, 401, 402}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("streamed trade IDs = %v, want %v", ids, want)
+	}
+
+	var dates []string
+	for _, result := range results {
+		dates = append(dates, result.Date)
+		if result.TradeCount != 3 {
+			t.Errorf("%s TradeCount = %d, want 3", result.Date, result.TradeCount)
+		}
+	}
+	if got := strings.Join(dates, ","); got != "2025-01-01,2025-01-02,2025-01-04" {
+		t.Errorf("result dates = %s, want 2025-01-01,2025-01-02,2025-01-04", got)
+	}
+}
+
+func TestStreamRange_EmitErrorStops(t *testing.T) {
+	server := newRangeServer(t, "2025-01-01", "2025-01-02", "2025-01-03", "2025-01-04", "2025-01-05")
+	defer server.Close()
+
+	spoolDir := t.TempDir()
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.SpoolDir = spoolDir
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	errStop := errors.New("stop")
+	streamed := 0
+	_, err = c.StreamRange(context.Background(), "BTCUSDT",
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		func(trade Trade) error {
+			streamed++
+			if trade.TradeID == 201 {
+				return errStop
+			}
+			return nil
+		})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("StreamRange() error = %v, want errStop", err)
+	}
+	if streamed != 5 {
+		t.Errorf("streamed %d trades, want 5", streamed)
+	}
+
+	// Days downloaded ahead of the failure must not leave spool files behind
+	entries, err := os.ReadDir(spoolDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("spool directory has %d leftover files", len(entries))
+	}
+}
+
+func TestStreamRange_DeadlineBoundsRange(t *testing.T) {
+	server := newRangeServer(t, "2025-01-01", "2025-01-02", "2025-01-03", "2025-01-04", "2025-01-05")
+	defer server.Close()
+
+	spoolDir := t.TempDir()
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	config.SpoolDir = spoolDir
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	results, err := c.StreamRange(ctx, "BTCUSDT",
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		func(trade Trade) error {
+			if trade.TradeID == 200 {
+				// Outlast the deadline once the first day is complete
+				<-ctx.Done()
+			}
+			return nil
+		})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("StreamRange() error = %v, want context.DeadlineExceeded", err)
+	}
+	if len(results) == 0 || len(results) > 2 || results[0].Date != "2025-01-01" {
+		t.Errorf("got %d results, want the days completed before the deadline", len(results))
+	}
+
+	entries, err := os.ReadDir(spoolDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("spool directory has %d leftover files", len(entries))
+	}
+}
+
+func TestStreamRange_InvalidRange(t *testing.T) {
+	c := NewConnector(time.Second)
+	_, err := c.StreamRange(context.Background(), "BTCUSDT",
+		time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		func(Trade) error { return nil })
+	if err == nil {
+		t.Error("StreamRange() error = nil, want an invalid range error")
+	}
+}
@@ -0,0 +1,48 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterNilIsUnlimited(t *testing.T) {
+	limiter := newRateLimiter(0, 0)
+	if limiter != nil {
+		t.Fatalf("newRateLimiter(0, 0) = %v, want nil", limiter)
+	}
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() on nil limiter error = %v", err)
+	}
+}
+
+func TestRateLimiterBurstThenWait(t *testing.T) {
+	limiter := newRateLimiter(20, 2)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// Two tokens are available immediately, the third takes ~50ms at 20 req/s
+	if elapsed < 30*time.Millisecond {
+		t.Errorf("third request was not delayed: elapsed %v", elapsed)
+	}
+}
+
+func TestRateLimiterRespectsContext(t *testing.T) {
+	limiter := newRateLimiter(0.1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,121 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TradeSample selects the first Head or the last Tail trades of a day. Exactly one of
+// them must be positive.
+type TradeSample struct {
+	Head int
+	Tail int
+}
+
+// SampleTrades downloads the trade archive for a symbol and date and returns only the
+// sampled trades, in file order, along with the number of trades the day has. A head
+// sample stops parsing once it has Head trades and counts the remaining rows without
+// parsing them, like CountTrades; with a trade filter or the monthly fallback the day
+// is parsed through instead, so the total counts only the trades kept. A tail sample
+// parses the whole day, keeping only the last Tail trades in a ring buffer, so memory
+// stays bounded however busy the day was. Like StreamTrades, results are not cached
+// and trades are neither sorted nor deduplicated.
+func (c *Connector) SampleTrades(ctx context.Context, symbol, year, month, day string, sample TradeSample) (*DownloadResult, int, error) {
+	if sample.Head < 0 || sample.Tail < 0 || (sample.Head > 0) == (sample.Tail > 0) {
+		return nil, 0, errors.New("exactly one of Head and Tail must be positive")
+	}
+
+	y, m, d := formatDate(year, month, day)
+	if err := c.checkSymbolIndex(symbol, fmt.Sprintf("%s-%s-%s", y, m, d)); err != nil {
+		return nil, 0, err
+	}
+
+	downloader := c.getDownloader()
+	downloadStart := time.Now()
+	ctx, archive, monthly, err := c.spoolTrades(ctx, downloader, symbol, y, m, d)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer archive.Close()
+	downloadTime := time.Since(downloadStart)
+
+	// Stopping early leaves the total to a row count, which cannot apply filters
+	parser := c.parser
+	stopEarly := sample.Head > 0 && !hasTradeFilter(ctx) &&
+		(c.parser.maxTrades == 0 || c.parser.maxTrades >= sample.Head)
+	if stopEarly {
+		head := *c.parser
+		head.maxTrades = sample.Head
+		parser = &head
+	}
+
+	var trades []Trade
+	ring := newTradeRing(sample.Tail)
+	result, err := c.streamArchive(ctx, parser, downloader, archive, symbol, y, m, d, downloadTime, func(trade Trade) error {
+		if sample.Tail > 0 {
+			ring.add(trade)
+		} else if len(trades) < sample.Head {
+			trades = append(trades, trade)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if monthly {
+		result.MonthlyFallback = true
+		result.SourceURL = downloader.MonthlyTradesURL(symbol, y, m)
+	}
+
+	total := result.TradeCount
+	if stopEarly {
+		// The sample, not MaxTradesPerFile, stopped parsing
+		result.Truncated = false
+		result.ParseStats.TruncatedFiles = 0
+		result.VWAP = 0 // It would only cover the sample
+		if len(trades) == sample.Head {
+			total, _, err = c.parser.CountZipReader(ctx, archive, archive.size)
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to count trades: %w", err)
+			}
+		}
+	}
+
+	if sample.Tail > 0 {
+		trades = ring.trades()
+	}
+	result.Trades = trades
+	result.TradeCount = len(trades)
+	return result, total, nil
+}
+
+// tradeRing keeps the last size trades added to it. The buffer grows as trades are
+// added, so a large size costs nothing on a quiet day.
+type tradeRing struct {
+	buf  []Trade
+	size int
+	next int // Index the next trade is written to once buf is full
+}
+
+func newTradeRing(size int) *tradeRing {
+	return &tradeRing{size: size}
+}
+
+func (r *tradeRing) add(trade Trade) {
+	if r.size <= 0 {
+		return
+	}
+	if len(r.buf) < r.size {
+		r.buf = append(r.buf, trade)
+		return
+	}
+	r.buf[r.next] = trade
+	r.next = (r.next + 1) % len(r.buf)
+}
+
+// trades returns the kept trades, oldest first
+func (r *tradeRing) trades() []Trade {
+	return append(append([]Trade(nil), r.buf[r.next:]...), r.buf[:r.next]...)
+}
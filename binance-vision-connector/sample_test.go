@@ -0,0 +1,78 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSampleTrades(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735689600000,true,true\n" +
+			"2,0.5,10,5,1735689600001,false,true\n" +
+			"3,0.5,10,5,1735689600002,true,true\n" +
+			"4,0.5,10,5,1735689600003,false,true\n" +
+			"5,0.5,10,5,1735689600004,false,true\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		sample    TradeSample
+		keep      TradeFilter
+		wantIDs   []int64
+		wantTotal int
+	}{
+		{"head", TradeSample{Head: 2}, nil, []int64{1, 2}, 5},
+		{"head of the whole day", TradeSample{Head: 10}, nil, []int64{1, 2, 3, 4, 5}, 5},
+		{"head with a filter", TradeSample{Head: 2}, TakerTrades, []int64{2, 4}, 3},
+		{"tail", TradeSample{Tail: 2}, nil, []int64{4, 5}, 5},
+		{"tail of the whole day", TradeSample{Tail: 10}, nil, []int64{1, 2, 3, 4, 5}, 5},
+		{"tail with a filter", TradeSample{Tail: 1}, MakerTrades, []int64{3}, 2},
+		{"huge tail", TradeSample{Tail: 1 << 30}, nil, []int64{1, 2, 3, 4, 5}, 5}, // The ring only grows with the trades kept
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.keep != nil {
+				ctx = ContextWithTradeFilter(ctx, tt.keep)
+			}
+			result, total, err := c.SampleTrades(ctx, "BTCUSDT", "2025", "01", "01", tt.sample)
+			if err != nil {
+				t.Fatalf("SampleTrades() error = %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", total, tt.wantTotal)
+			}
+			if result.TradeCount != len(tt.wantIDs) || len(result.Trades) != len(tt.wantIDs) {
+				t.Fatalf("got %d trades with TradeCount %d, want %d", len(result.Trades), result.TradeCount, len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if result.Trades[i].TradeID != id {
+					t.Errorf("trade %d: ID %d, want %d", i, result.Trades[i].TradeID, id)
+				}
+			}
+			if result.Truncated {
+				t.Error("Truncated = true, want false")
+			}
+		})
+	}
+
+	for _, sample := range []TradeSample{{}, {Head: 1, Tail: 1}, {Head: -1}} {
+		if _, _, err := c.SampleTrades(context.Background(), "BTCUSDT", "2025", "01", "01", sample); err == nil {
+			t.Errorf("SampleTrades(%+v) error = nil, want an error", sample)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Schema describes the CSV layout of a dataset. The shared row loop, readCSVRows,
+// skips rows with fewer than ColumnCount columns and turns the others into records
+// with ParseRecord, so a new dataset only needs its column mapping.
+type Schema interface {
+	// ColumnCount is the minimum number of columns of a data row
+	ColumnCount() int
+	// ParseRecord converts a row of at least ColumnCount columns into a record of the
+	// dataset, such as a Trade
+	ParseRecord(record []string) (interface{}, error)
+}
+
+// fixedSchema returns a schemaFor function for datasets with a single layout
+func fixedSchema(schema Schema) func(header []string, columns int) Schema {
+	return func([]string, int) Schema { return schema }
+}
+
+// rowCountSchema accepts the rows its Schema would parse without parsing them, for
+// counting rows cheaply
+type rowCountSchema struct {
+	Schema
+}
+
+func (rowCountSchema) ParseRecord([]string) (interface{}, error) {
+	return nil, nil
+}
+
+// readCSVRows reads CSV rows one at a time and passes each record parsed with the
+// schema to emit. A header row is skipped if present; data rows always start with a
+// number, so they are never mistaken for one. schemaFor picks the schema from the
+// header row, nil when the file has none, and a data row's column count, for
+// datasets whose files come in several layouts. It is asked again for each row until
+// one parses, so a malformed first row does not decide the layout of the file.
+// Short rows and rows ParseRecord rejects are counted in the returned stats and
+// skipped. emit may return errRecordFiltered to drop a record, which is counted in
+// stats.Filtered, or errStopRows to stop reading, which is counted in
+// stats.StoppedFiles. It stops after maxRecords records when maxRecords is positive.
+// The context is checked periodically so cancelled requests stop parsing promptly.
+func (p *Parser) readCSVRows(ctx context.Context, r io.Reader, schemaFor func(header []string, columns int) Schema, maxRecords int, emit func(record interface{}) error) (ParseStats, error) {
+	reader := p.newCSVReader(r)
+
+	var stats ParseStats
+	var schema Schema
+	var header []string
+	rows := newRowLogger(ctx)
+
+	emitted := 0
+	lineNum := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, fmt.Errorf("failed to read CSV record at line %d: %w", lineNum+1, err)
+		}
+		lineNum++
+
+		if lineNum%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+		}
+
+		if lineNum == 1 && len(record) > 0 {
+			// A BOM written by some tools would otherwise make the first column look
+			// non-numeric, and the first row would be dropped as a header
+			record[0] = strings.TrimPrefix(record[0], utf8BOM)
+
+			if !isNumeric(strings.TrimSpace(record[0])) {
+				// Records may be reused by the reader, so keep a copy
+				header = append([]string(nil), record...)
+				continue
+			}
+		}
+
+		// Keep the layout of the first row that parses
+		rowSchema := schema
+		if rowSchema == nil {
+			rowSchema = schemaFor(header, len(record))
+		}
+
+		if len(record) < rowSchema.ColumnCount() {
+			reason := fmt.Sprintf("expected %d columns, got %d", rowSchema.ColumnCount(), len(record))
+			stats.SkippedShort++
+			stats.addSample(lineNum, reason)
+			p.addErrorDetail(&stats, lineNum, record, reason)
+			rows.skipped(lineNum, reason)
+			continue
+		}
+
+		parsed, err := rowSchema.ParseRecord(record)
+		if err != nil {
+			stats.SkippedParseError++
+			stats.addSample(lineNum, err.Error())
+			p.addErrorDetail(&stats, lineNum, record, err.Error())
+			rows.skipped(lineNum, err.Error())
+			continue
+		}
+		schema = rowSchema
+		if err := emit(parsed); err == errRecordFiltered {
+			stats.Filtered++
+			continue
+		} else if err == errStopRows {
+			stats.StoppedFiles++
+			break
+		} else if err != nil {
+			return stats, err
+		}
+		emitted++
+
+		if maxRecords > 0 && emitted >= maxRecords {
+			// Only count the file as truncated if rows remain after the limit
+			if _, err := reader.Read(); err != io.EOF {
+				stats.TruncatedFiles++
+			}
+			break
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,79 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// pairSchema parses rows of two integers, for testing readCSVRows with a schema of its own
+type pairSchema struct{}
+
+func (pairSchema) ColumnCount() int { return 2 }
+
+func (pairSchema) ParseRecord(record []string) (interface{}, error) {
+	a, err := strconv.Atoi(record[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid a: %w", err)
+	}
+	b, err := strconv.Atoi(record[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid b: %w", err)
+	}
+	return [2]int{a, b}, nil
+}
+
+func TestReadCSVRows(t *testing.T) {
+	tests := []struct {
+		name          string
+		csv           string
+		maxRecords    int
+		want          string
+		wantShort     int
+		wantParseErr  int
+		wantTruncated int
+	}{
+		{"plain rows", "1,2\n3,4\n", 0, "[[1 2] [3 4]]", 0, 0, 0},
+		{"header and BOM skipped", utf8BOM + "a,b\n1,2\n", 0, "[[1 2]]", 0, 0, 0},
+		{"short and invalid rows counted", "1,2\n3\n4,x\n5,6\n", 0, "[[1 2] [5 6]]", 1, 1, 0},
+		{"stops at maxRecords", "1,2\n3,4\n5,6\n", 2, "[[1 2] [3 4]]", 0, 0, 1},
+		{"maxRecords at the end is not truncation", "1,2\n3,4\n", 2, "[[1 2] [3 4]]", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][2]int
+			stats, err := NewParser().readCSVRows(context.Background(), strings.NewReader(tt.csv), fixedSchema(pairSchema{}), tt.maxRecords,
+				func(record interface{}) error {
+					got = append(got, record.([2]int))
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("readCSVRows() error = %v", err)
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("records = %v, want %s", got, tt.want)
+			}
+			if stats.SkippedShort != tt.wantShort || stats.SkippedParseError != tt.wantParseErr || stats.TruncatedFiles != tt.wantTruncated {
+				t.Errorf("stats = %+v, want %d short, %d parse errors, %d truncated", stats, tt.wantShort, tt.wantParseErr, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestReadCSVRows_DetectsSchemaFromFirstDataRow(t *testing.T) {
+	// A futures trade file has 6 columns, so a 7 column row is not short, and a 5 column one is
+	csvData := "1,0.5,10,5,1735430400000,true\n2,0.5,10,5,1735430400001,false,true\n3,0.5,10,5,1735430400002\n"
+	trades, stats, err := NewParser().parseCSVStreaming(context.Background(), strings.NewReader(csvData), 0, 0)
+	if err != nil {
+		t.Fatalf("parseCSVStreaming() error = %v", err)
+	}
+	if len(trades) != 2 || stats.SkippedShort != 1 {
+		t.Errorf("parsed %d trades with %d short rows, want 2 and 1", len(trades), stats.SkippedShort)
+	}
+	if !trades[0].IsBestMatch || trades[1].TradeID != 2 {
+		t.Errorf("trades = %+v", trades)
+	}
+}
@@ -0,0 +1,53 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup deduplicates concurrent downloads of the same day: the first caller for
+// a key runs the download and callers arriving while it is in flight wait for its
+// result instead of fetching the archive again. The zero value is ready to use.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a download in progress; result and err are set before done is closed
+type flight struct {
+	done   chan struct{}
+	result *DownloadResult
+	err    error
+}
+
+// do runs download for key unless a call for key is already in flight, in which case
+// it waits for that call's result. shared reports whether the result came from
+// another caller. A waiting caller whose ctx is done stops waiting and returns ctx.Err();
+// the download itself carries on for the others.
+func (g *flightGroup) do(ctx context.Context, key string, download func() (*DownloadResult, error)) (result *DownloadResult, shared bool, err error) {
+	g.mu.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.result, true, f.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	f.result, f.err = download()
+	return f.result, false, f.err
+}
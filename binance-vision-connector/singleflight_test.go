@@ -0,0 +1,112 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadTrades_SharesConcurrentDownloads(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n",
+	})
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	connector, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan *DownloadResult, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := connector.DownloadTrades(context.Background(), "BTCUSDT", "2025", "01", "01")
+			if err != nil {
+				t.Errorf("DownloadTrades() error = %v", err)
+				return
+			}
+			results <- result
+		}()
+	}
+
+	// Let every caller join the download before the archive arrives
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("upstream requests = %d, want 1", got)
+	}
+	seen := make(map[*DownloadResult]bool)
+	for result := range results {
+		if result.TradeCount != 1 {
+			t.Errorf("TradeCount = %d, want 1", result.TradeCount)
+		}
+		if seen[result] {
+			t.Errorf("callers share the same *DownloadResult")
+		}
+		seen[result] = true
+	}
+	if len(seen) != callers {
+		t.Errorf("got %d results, want %d", len(seen), callers)
+	}
+}
+
+func TestFlightGroup_WaiterContext(t *testing.T) {
+	var g flightGroup
+	release := make(chan struct{})
+	leaderDone := make(chan error)
+	go func() {
+		_, _, err := g.do(context.Background(), "key", func() (*DownloadResult, error) {
+			<-release
+			return &DownloadResult{}, nil
+		})
+		leaderDone <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// A waiter that gives up does not affect the download in flight
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, shared, err := g.do(ctx, "key", func() (*DownloadResult, error) {
+		t.Error("waiter ran its own download")
+		return nil, nil
+	})
+	if !shared || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waiter got shared = %v, error = %v, want true, %v", shared, err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	if err := <-leaderDone; err != nil {
+		t.Errorf("leader error = %v", err)
+	}
+
+	// Once the flight has landed, the next call downloads again
+	ran := false
+	if _, shared, _ := g.do(context.Background(), "key", func() (*DownloadResult, error) {
+		ran = true
+		return &DownloadResult{}, nil
+	}); shared || !ran {
+		t.Errorf("call after the flight: shared = %v, ran = %v, want false, true", shared, ran)
+	}
+}
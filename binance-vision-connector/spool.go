@@ -0,0 +1,164 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// spooledArchive is a downloaded archive kept in a temporary file, so archive/zip can
+// read it with random access without the whole archive being held in memory
+type spooledArchive struct {
+	file         *os.File
+	size         int64
+	lastModified time.Time // Last-Modified of the upstream response, if it sent one
+}
+
+// ReadAt implements io.ReaderAt over the spooled file
+func (a *spooledArchive) ReadAt(p []byte, off int64) (int, error) {
+	return a.file.ReadAt(p, off)
+}
+
+// Close closes and removes the temporary file
+func (a *spooledArchive) Close() error {
+	err := a.file.Close()
+	if removeErr := os.Remove(a.file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// reset discards the spooled bytes so the download can start over
+func (a *spooledArchive) reset() error {
+	if err := a.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to reset spool file: %w", err)
+	}
+	if _, err := a.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to reset spool file: %w", err)
+	}
+	a.size = 0
+	return nil
+}
+
+// resumePoint describes how an interrupted download can continue
+type resumePoint struct {
+	ranges    bool   // The server accepts Range requests for the archive
+	validator string // Strong ETag or Last-Modified of the partial body, sent as If-Range
+}
+
+// spoolURL downloads the archive at url into a temporary file in the downloader's
+// spool directory. The caller must Close the returned archive to remove the file.
+//
+// If the body is cut off mid-transfer, up to d.retries further requests are made.
+// Servers that answered with Accept-Ranges: bytes are asked only for the missing
+// bytes; otherwise, or if the archive changed meanwhile, the download restarts.
+func (d *Downloader) spoolURL(ctx context.Context, url string) (*spooledArchive, error) {
+	if err := d.slots.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer d.slots.release()
+
+	file, err := os.CreateTemp(d.spoolDir, "binance-vision-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	archive := &spooledArchive{file: file}
+
+	var resume *resumePoint
+	for attempt := 0; ; attempt++ {
+		next, err := d.spoolAttempt(ctx, url, archive, resume)
+		if err == nil {
+			return archive, nil
+		}
+		if next == nil || attempt >= d.retries || ctx.Err() != nil {
+			archive.Close()
+			return nil, err
+		}
+		resume = next
+	}
+}
+
+// spoolAttempt makes one request for the archive, appending to the bytes already
+// spooled when resume allows it and starting over otherwise. On failure it returns
+// a non-nil resumePoint if the body was interrupted and the download may be retried.
+func (d *Downloader) spoolAttempt(ctx context.Context, url string, archive *spooledArchive, resume *resumePoint) (*resumePoint, error) {
+	header := http.Header{}
+	if resume != nil && resume.ranges && archive.size > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", archive.size))
+		if resume.validator != "" {
+			header.Set("If-Range", resume.validator)
+		}
+	} else if err := archive.reset(); err != nil {
+		return nil, err
+	}
+
+	resp, err := d.get(ctx, url, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK && archive.size > 0:
+		// The server ignored the range or the archive changed, so this is the whole body
+		if err := archive.reset(); err != nil {
+			return nil, err
+		}
+	case resp.StatusCode == http.StatusPartialContent:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", archive.size)) {
+			return &resumePoint{}, fmt.Errorf("failed to resume download: unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+	}
+
+	body, remaining, err := d.archiveBody(resp, archive.size)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	// Copy one byte past the limit to detect bodies that would be truncated
+	offset := archive.size
+	var dst io.Writer = archive.file
+	if tracker := progressFromContext(ctx); tracker != nil {
+		if resp.ContentLength >= 0 {
+			tracker.setTotal(offset + resp.ContentLength)
+		}
+		dst = progressWriter{w: archive.file, tracker: tracker}
+	}
+	n, err := io.Copy(dst, io.LimitReader(body, remaining+1))
+	d.recordDownload(n)
+	archive.size += n
+	if err != nil {
+		return resumePointFor(resp), fmt.Errorf("failed to read zip file: %w", err)
+	}
+	if n > remaining {
+		return nil, fmt.Errorf("%w: body exceeds limit of %d bytes", ErrResponseTooLarge, offset+remaining)
+	}
+
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		archive.lastModified = lastModified.UTC()
+	}
+	return nil, nil
+}
+
+// resumePointFor returns how a download interrupted while reading resp can continue.
+// Ranges count bytes of the encoded body, so they are only used for unencoded archives.
+func resumePointFor(resp *http.Response) *resumePoint {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if resp.Header.Get("Accept-Ranges") != "bytes" || resp.Uncompressed || (encoding != "" && encoding != "identity") {
+		return &resumePoint{}
+	}
+
+	validator := resp.Header.Get("ETag")
+	if strings.HasPrefix(validator, "W/") {
+		validator = "" // If-Range requires a strong validator
+	}
+	if validator == "" {
+		validator = resp.Header.Get("Last-Modified")
+	}
+	return &resumePoint{ranges: true, validator: validator}
+}
@@ -0,0 +1,143 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestDownloadTrades_SpoolsToTempFile(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n"})
+
+	tests := []struct {
+		name    string
+		body    []byte
+		maxSize int64
+		wantErr error
+	}{
+		{"valid archive", zipData, 0, nil},
+		{"not a zip file", []byte("<html>maintenance</html>"), 0, ErrNotAZipFile},
+		{"too large", zipData, 16, ErrResponseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Flushing before writing hides the length, so the limit is hit while spooling
+				w.(http.Flusher).Flush()
+				w.Write(tt.body)
+			}))
+			defer server.Close()
+
+			spoolDir := t.TempDir()
+			config := DefaultConfig()
+			config.BaseURL = server.URL
+			config.MaxResponseSize = tt.maxSize
+			config.SpoolDir = spoolDir
+			c, err := NewConnectorWithConfig(config)
+			if err != nil {
+				t.Fatalf("NewConnectorWithConfig() error = %v", err)
+			}
+
+			result, err := c.DownloadTrades(context.Background(), "BTCUSDT", "2025", "01", "01")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DownloadTrades() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (result.TradeCount != 1 || result.CompressedBytes != int64(len(zipData))) {
+				t.Errorf("TradeCount = %d, CompressedBytes = %d", result.TradeCount, result.CompressedBytes)
+			}
+
+			// The spool file is removed whether or not parsing succeeded
+			entries, err := os.ReadDir(spoolDir)
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("spool directory has %d leftover files, want 0", len(entries))
+			}
+		})
+	}
+}
+
+func TestDownloadTrades_ResumesInterruptedDownload(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n"})
+	half := len(zipData) / 2
+
+	tests := []struct {
+		name       string
+		ranges     bool
+		retries    int
+		wantRanges []string // Range header of each request
+		wantErr    bool
+	}{
+		{"resumed with a range", true, 1, []string{"", fmt.Sprintf("bytes=%d-", half)}, false},
+		{"restarted without range support", false, 1, []string{"", ""}, false},
+		{"no retries", true, 0, []string{""}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ranges = append(ranges, r.Header.Get("Range"))
+				if tt.ranges {
+					w.Header().Set("Accept-Ranges", "bytes")
+					w.Header().Set("ETag", `"v1"`)
+				}
+
+				if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+					if r.Header.Get("If-Range") != `"v1"` {
+						t.Errorf("If-Range = %q, want the ETag", r.Header.Get("If-Range"))
+					}
+					w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", half, len(zipData)-1, len(zipData)))
+					w.WriteHeader(http.StatusPartialContent)
+					w.Write(zipData[half:])
+					return
+				}
+
+				// The first response is cut off halfway through the body
+				w.Header().Set("Content-Length", strconv.Itoa(len(zipData)))
+				if len(ranges) == 1 {
+					w.Write(zipData[:half])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler)
+				}
+				w.Write(zipData)
+			}))
+			defer server.Close()
+
+			var downloaded int64
+			config := DefaultConfig()
+			config.BaseURL = server.URL
+			config.DownloadRetries = tt.retries
+			config.OnArchiveDownloaded = func(n int64) { downloaded += n }
+			c, err := NewConnectorWithConfig(config)
+			if err != nil {
+				t.Fatalf("NewConnectorWithConfig() error = %v", err)
+			}
+
+			result, err := c.DownloadTrades(context.Background(), "BTCUSDT", "2025", "01", "01")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DownloadTrades() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(ranges, tt.wantRanges) {
+				t.Errorf("Range headers = %q, want %q", ranges, tt.wantRanges)
+			}
+			if err != nil {
+				return
+			}
+			if result.TradeCount != 1 || result.CompressedBytes != int64(len(zipData)) {
+				t.Errorf("TradeCount = %d, CompressedBytes = %d", result.TradeCount, result.CompressedBytes)
+			}
+			if tt.ranges && downloaded != int64(len(zipData)) {
+				t.Errorf("bytes downloaded = %d, want %d (only the missing bytes are fetched again)", downloaded, len(zipData))
+			}
+		})
+	}
+}
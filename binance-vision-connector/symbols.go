@@ -0,0 +1,207 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// symbolRefreshConcurrency bounds the symbols whose dates are listed at once during a refresh
+const symbolRefreshConcurrency = 4
+
+// symbolRefreshRate is the listing requests per second a refresh may make. Refreshes
+// are paced by a limiter of their own rather than RequestsPerSecond, and take no
+// MaxConcurrentDownloads slots, so the thousands of requests they make never queue
+// user downloads.
+const symbolRefreshRate = 4
+
+// symbolIndexGrace is how long after a refresh a symbol missing from the index may
+// still publish archives for earlier dates, because it was listed in the meantime
+const symbolIndexGrace = 72 * time.Hour
+
+// SymbolInfo describes the daily trade archives published for a symbol
+type SymbolInfo struct {
+	Symbol    string `json:"symbol"`
+	FirstDate string `json:"first_date"` // YYYY-MM-DD of the earliest archive
+	LastDate  string `json:"last_date"`  // YYYY-MM-DD of the latest archive at the last refresh
+}
+
+// symbolIndex is the result of the last successful RefreshSymbols
+type symbolIndex struct {
+	mu        sync.RWMutex
+	symbols   map[string]SymbolInfo // nil until the first refresh
+	refreshed time.Time
+}
+
+// ListSymbols returns the sorted symbols that have a daily trades directory in the
+// configured market
+func (c *Connector) ListSymbols(ctx context.Context) ([]string, error) {
+	return listSymbols(ctx, c.getDownloader())
+}
+
+// listSymbols is ListSymbols making its requests through downloader
+func listSymbols(ctx context.Context, downloader *Downloader) ([]string, error) {
+	prefix := downloader.market.tradesPrefix()
+
+	var symbols []string
+	err := listBucket(ctx, downloader, prefix, "/", func(listing *listBucketResult) {
+		for _, common := range listing.CommonPrefixes {
+			if symbol := path.Base(strings.TrimPrefix(common.Prefix, prefix)); symbol != "" && symbol != "." {
+				symbols = append(symbols, symbol)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// RefreshSymbols rebuilds the symbol index from the bucket listing: every symbol is
+// listed with the dates of its first and latest trade archive. Symbols whose dates
+// fail to list keep their previous entry and are reported in the returned error,
+// but the rest of the index is still updated. Requests are paced by a limiter of the
+// refresh's own, at symbolRefreshRate, instead of the one shared with downloads.
+func (c *Connector) RefreshSymbols(ctx context.Context) error {
+	downloader := c.getDownloader().clone()
+	downloader.limiter = c.refreshLimiter
+	downloader.slots = nil
+
+	symbols, err := listSymbols(ctx, downloader)
+	if err != nil {
+		return fmt.Errorf("failed to list symbols: %w", err)
+	}
+
+	c.symbols.mu.RLock()
+	previous := c.symbols.symbols
+	c.symbols.mu.RUnlock()
+
+	var (
+		mu       sync.Mutex
+		index    = make(map[string]SymbolInfo, len(symbols))
+		failed   int
+		firstErr error
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, symbolRefreshConcurrency)
+	)
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			dates, err := listAvailableDates(ctx, downloader, symbol)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				if info, ok := previous[symbol]; ok {
+					index[symbol] = info
+				}
+			case len(dates) > 0:
+				index[symbol] = SymbolInfo{Symbol: symbol, FirstDate: dates[0], LastDate: dates[len(dates)-1]}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// A cancelled refresh would mark every symbol as failed, so keep the old index
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.symbols.mu.Lock()
+	c.symbols.symbols = index
+	c.symbols.refreshed = time.Now()
+	c.symbols.mu.Unlock()
+
+	if failed > 0 {
+		return fmt.Errorf("failed to list dates of %d of %d symbols: %w", failed, len(symbols), firstErr)
+	}
+	return nil
+}
+
+// RunSymbolRefresh refreshes the symbol index immediately and then every interval
+// until ctx is cancelled. Failed refreshes are logged and retried at the next tick.
+func (c *Connector) RunSymbolRefresh(ctx context.Context, interval time.Duration) {
+	logger := loggerFromContext(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if err := c.RefreshSymbols(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Error("symbol index refresh failed", "duration", time.Since(start), "error", err)
+		} else {
+			symbols, _ := c.Symbols()
+			logger.Info("symbol index refreshed", "duration", time.Since(start), "symbols", len(symbols))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Symbols returns the indexed symbols sorted by name and when the index was last
+// refreshed. Both are zero until RefreshSymbols has succeeded once.
+func (c *Connector) Symbols() ([]SymbolInfo, time.Time) {
+	c.symbols.mu.RLock()
+	defer c.symbols.mu.RUnlock()
+
+	if c.symbols.symbols == nil {
+		return nil, time.Time{}
+	}
+
+	symbols := make([]SymbolInfo, 0, len(c.symbols.symbols))
+	for _, info := range c.symbols.symbols {
+		symbols = append(symbols, info)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		return symbols[i].Symbol < symbols[j].Symbol
+	})
+	return symbols, c.symbols.refreshed
+}
+
+// checkSymbolIndex returns ErrArchiveNotFound for a trade archive the symbol index
+// proves cannot exist, so the download is not attempted. Archives are never
+// backfilled, so dates before a symbol's first archive are refused. Unknown symbols
+// are only refused for dates well before the refresh, since a newly listed symbol
+// may have appeared since. Without an index every request passes.
+func (c *Connector) checkSymbolIndex(symbol, date string) error {
+	c.symbols.mu.RLock()
+	defer c.symbols.mu.RUnlock()
+
+	if c.symbols.symbols == nil {
+		return nil
+	}
+
+	info, ok := c.symbols.symbols[symbol]
+	if ok && date < info.FirstDate {
+		return fmt.Errorf("%w: %s archives start on %s", ErrArchiveNotFound, symbol, info.FirstDate)
+	}
+	if !ok {
+		day, err := time.Parse("2006-01-02", date)
+		if err == nil && day.Add(symbolIndexGrace).Before(c.symbols.refreshed) {
+			return fmt.Errorf("%w: %s is not a known symbol", ErrArchiveNotFound, symbol)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSymbolListingServer serves a bucket listing with the given symbols and archive
+// dates; the symbol directories are split over two pages
+func newSymbolListingServer(t *testing.T, dates map[string][]string) *httptest.Server {
+	t.Helper()
+	const root = "data/spot/daily/trades/"
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		prefix := query.Get("prefix")
+		w.Header().Set("Content-Type", "application/xml")
+
+		if prefix == root {
+			if query.Get("delimiter") != "/" {
+				t.Errorf("symbol listing delimiter = %q, want /", query.Get("delimiter"))
+			}
+			fmt.Fprint(w, `<ListBucketResult>`)
+			if query.Get("continuation-token") == "" {
+				fmt.Fprintf(w, `<IsTruncated>true</IsTruncated><NextContinuationToken>page-2</NextContinuationToken>
+<CommonPrefixes><Prefix>%sETHUSDT/</Prefix></CommonPrefixes>`, root)
+			} else {
+				fmt.Fprintf(w, `<IsTruncated>false</IsTruncated>
+<CommonPrefixes><Prefix>%[1]sBTCUSDT/</Prefix></CommonPrefixes>
+<CommonPrefixes><Prefix>%[1]sEMPTYUSDT/</Prefix></CommonPrefixes>`, root)
+			}
+			fmt.Fprint(w, `</ListBucketResult>`)
+			return
+		}
+
+		symbol := strings.TrimSuffix(strings.TrimPrefix(prefix, root), "/")
+		fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated>`)
+		for _, date := range dates[symbol] {
+			fmt.Fprintf(w, `<Contents><Key>%s%s-trades-%s.zip</Key></Contents>`, prefix, symbol, date)
+		}
+		fmt.Fprint(w, `</ListBucketResult>`)
+	}))
+}
+
+func TestRefreshSymbols(t *testing.T) {
+	server := newSymbolListingServer(t, map[string][]string{
+		"BTCUSDT": {"2017-08-17", "2024-01-01", "2020-05-05"},
+		"ETHUSDT": {"2017-08-17"},
+	})
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ListingURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	if symbols, refreshed := c.Symbols(); symbols != nil || !refreshed.IsZero() {
+		t.Fatalf("Symbols() before refresh = %v, %v, want nothing", symbols, refreshed)
+	}
+
+	if err := c.RefreshSymbols(context.Background()); err != nil {
+		t.Fatalf("RefreshSymbols() error = %v", err)
+	}
+
+	// Symbols without archives are left out
+	symbols, refreshed := c.Symbols()
+	want := []SymbolInfo{
+		{Symbol: "BTCUSDT", FirstDate: "2017-08-17", LastDate: "2024-01-01"},
+		{Symbol: "ETHUSDT", FirstDate: "2017-08-17", LastDate: "2017-08-17"},
+	}
+	if !reflect.DeepEqual(symbols, want) {
+		t.Errorf("Symbols() = %+v, want %+v", symbols, want)
+	}
+	if refreshed.IsZero() {
+		t.Error("Symbols() refresh time is zero after a refresh")
+	}
+}
+
+// TestRefreshSymbols_OwnBudget checks that a refresh neither waits for nor takes the
+// rate limiter tokens and download slots of user downloads
+func TestRefreshSymbols_OwnBudget(t *testing.T) {
+	server := newSymbolListingServer(t, map[string][]string{"BTCUSDT": {"2017-08-17"}})
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ListingURL = server.URL
+	config.RequestsPerSecond = 0.001
+	config.Burst = 1
+	config.MaxConcurrentDownloads = 1
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	// Use up the only token and hold the only slot, as a busy download would
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.downloader.limiter.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.downloader.slots.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer c.downloader.slots.release()
+
+	if err := c.RefreshSymbols(ctx); err != nil {
+		t.Fatalf("RefreshSymbols() error = %v", err)
+	}
+	if symbols, _ := c.Symbols(); len(symbols) != 1 {
+		t.Errorf("Symbols() = %+v, want BTCUSDT", symbols)
+	}
+	if active, _ := c.DownloadQueue(); active != 1 {
+		t.Errorf("DownloadQueue() active = %d, want only the held slot", active)
+	}
+}
+
+func TestCheckSymbolIndex(t *testing.T) {
+	server := newSymbolListingServer(t, map[string][]string{"BTCUSDT": {"2017-08-17", "2024-01-01"}})
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ListingURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	// Without an index nothing is refused
+	if err := c.checkSymbolIndex("NOPEUSDT", "2018-01-01"); err != nil {
+		t.Fatalf("checkSymbolIndex() without an index error = %v", err)
+	}
+
+	if err := c.RefreshSymbols(context.Background()); err != nil {
+		t.Fatalf("RefreshSymbols() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		symbol  string
+		date    string
+		wantErr bool
+	}{
+		{"within range", "BTCUSDT", "2020-01-01", false},
+		{"after last date", "BTCUSDT", "2030-01-01", false},
+		{"before first date", "BTCUSDT", "2017-08-16", true},
+		{"unknown symbol", "NOPEUSDT", "2018-01-01", true},
+		{"unknown symbol after refresh", "NOPEUSDT", "2999-01-01", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.checkSymbolIndex(tt.symbol, tt.date)
+			if tt.wantErr != errors.Is(err, ErrArchiveNotFound) {
+				t.Errorf("checkSymbolIndex(%s, %s) error = %v, want ErrArchiveNotFound: %v", tt.symbol, tt.date, err, tt.wantErr)
+			}
+		})
+	}
+}
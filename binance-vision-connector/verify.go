@@ -0,0 +1,91 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+)
+
+// VerifyReport is a data quality report of one daily trade archive: how many rows
+// parsed, how many were skipped and whether the archive matches its .CHECKSUM file
+type VerifyReport struct {
+	Symbol            string     `json:"symbol"`
+	Date              string     `json:"date"`
+	ParsedCount       int        `json:"parsed_count"`
+	SkippedCount      int        `json:"skipped_count"`      // Rows skipped as too short or unparseable
+	Bytes             int64      `json:"bytes"`              // Size of the downloaded archive
+	UncompressedBytes int64      `json:"uncompressed_bytes"` // Total size of the CSV files in the archive
+	ChecksumOK        bool       `json:"checksum_ok"`
+	ExpectedChecksum  string     `json:"expected_checksum,omitempty"` // SHA256 published in the .CHECKSUM file
+	ActualChecksum    string     `json:"actual_checksum"`             // SHA256 of the downloaded archive
+	ChecksumError     string     `json:"checksum_error,omitempty"`    // Why the published checksum could not be fetched
+	Truncated         bool       `json:"truncated"`                   // MaxTradesPerFile stopped parsing, so counts are incomplete
+	Warnings          []string   `json:"warnings,omitempty"`          // Per-file failures when PartialOK is set
+	ParseStats        ParseStats `json:"parse_stats"`
+	ParseMS           float64    `json:"parse_ms"`
+	SourceURL         string     `json:"source_url,omitempty"`
+}
+
+// VerifyTrades downloads the trade archive for a symbol and date, parses every row
+// without keeping the trades, and checks the archive against its .CHECKSUM file.
+// A missing or malformed checksum file is reported in ChecksumError rather than
+// failing the call, since the counts are still useful; a missing archive fails it.
+func (c *Connector) VerifyTrades(ctx context.Context, symbol, year, month, day string) (*VerifyReport, error) {
+	year, month, day = formatDate(year, month, day)
+	if err := c.checkSymbolIndex(symbol, fmt.Sprintf("%s-%s-%s", year, month, day)); err != nil {
+		return nil, err
+	}
+
+	downloader := c.getDownloader()
+	url := downloader.TradesURL(symbol, year, month, day)
+
+	archive, err := downloader.spoolURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	report := &VerifyReport{
+		Symbol:    symbol,
+		Date:      fmt.Sprintf("%s-%s-%s", year, month, day),
+		Bytes:     archive.size,
+		SourceURL: url,
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(archive, 0, archive.size)); err != nil {
+		return nil, fmt.Errorf("failed to hash archive: %w", err)
+	}
+	report.ActualChecksum = hex.EncodeToString(hash.Sum(nil))
+
+	if expected, err := downloadChecksum(ctx, downloader, url+".CHECKSUM"); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		report.ChecksumError = err.Error()
+	} else {
+		report.ExpectedChecksum = expected
+		report.ChecksumOK = expected == report.ActualChecksum
+	}
+
+	parseStart := time.Now()
+	parsed, err := c.parser.StreamZipReader(ctx, archive, archive.size, func(Trade) error {
+		report.ParsedCount++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zip file: %w", err)
+	}
+	report.ParseMS = DurationMS(time.Since(parseStart))
+
+	report.SkippedCount = parsed.Stats.Skipped()
+	report.UncompressedBytes = parsed.UncompressedBytes
+	report.Truncated = parsed.Stats.TruncatedFiles > 0
+	report.Warnings = parsed.Warnings
+	report.ParseStats = parsed.Stats
+
+	return report, nil
+}
@@ -0,0 +1,92 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyTrades(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,0.5,10,5,1735430400000,true,true\n" +
+			"2,0.5\n" +
+			"3,abc,10,5,1735430400001,true,true\n" +
+			"4,0.5,10,5,1735430400002,false,true\n",
+	})
+	sum := sha256.Sum256(zipData)
+	digest := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name            string
+		checksum        string // Served .CHECKSUM content; empty means 404
+		wantOK          bool
+		wantChecksumErr bool
+	}{
+		{"checksum matches", digest + "  BTCUSDT-trades-2025-01-01.zip\n", true, false},
+		{"checksum mismatch", strings.Repeat("0", 64) + "  BTCUSDT-trades-2025-01-01.zip\n", false, false},
+		{"checksum missing", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, ".CHECKSUM") {
+					if tt.checksum == "" {
+						http.NotFound(w, r)
+						return
+					}
+					w.Write([]byte(tt.checksum))
+					return
+				}
+				w.Write(zipData)
+			}))
+			defer server.Close()
+
+			config := DefaultConfig()
+			config.BaseURL = server.URL
+			c, err := NewConnectorWithConfig(config)
+			if err != nil {
+				t.Fatalf("NewConnectorWithConfig() error = %v", err)
+			}
+
+			report, err := c.VerifyTrades(context.Background(), "BTCUSDT", "2025", "1", "1")
+			if err != nil {
+				t.Fatalf("VerifyTrades() error = %v", err)
+			}
+
+			if report.ParsedCount != 2 || report.SkippedCount != 2 {
+				t.Errorf("ParsedCount = %d, SkippedCount = %d, want 2 and 2", report.ParsedCount, report.SkippedCount)
+			}
+			if report.Bytes != int64(len(zipData)) || report.ActualChecksum != digest {
+				t.Errorf("Bytes = %d, ActualChecksum = %s, want %d and %s", report.Bytes, report.ActualChecksum, len(zipData), digest)
+			}
+			if report.ChecksumOK != tt.wantOK || (report.ChecksumError != "") != tt.wantChecksumErr {
+				t.Errorf("ChecksumOK = %v, ChecksumError = %q, want %v and error %v", report.ChecksumOK, report.ChecksumError, tt.wantOK, tt.wantChecksumErr)
+			}
+			if report.Date != "2025-01-01" {
+				t.Errorf("Date = %q, want 2025-01-01", report.Date)
+			}
+		})
+	}
+}
+
+func TestVerifyTrades_ArchiveNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	if _, err := c.VerifyTrades(context.Background(), "BTCUSDT", "2025", "01", "01"); !errors.Is(err, ErrArchiveNotFound) {
+		t.Errorf("VerifyTrades() error = %v, want ErrArchiveNotFound", err)
+	}
+}
@@ -0,0 +1,72 @@
+package binancevisionconnector
+
+import "context"
+
+// VWAPResult reports the volume-weighted average price of a day's trades
+type VWAPResult struct {
+	Symbol      string     `json:"symbol"`
+	Date        string     `json:"date"`
+	VWAP        float64    `json:"vwap"`         // Zero when the day has no volume
+	Volume      float64    `json:"volume"`       // Sum of the base asset quantities
+	QuoteVolume float64    `json:"quote_volume"` // Sum of price times quantity
+	TradeCount  int        `json:"trade_count"`
+	Warnings    []string   `json:"warnings,omitempty"` // Per-file failures when PartialOK is set
+	ParseStats  ParseStats `json:"parse_stats"`
+	SourceURL   string     `json:"source_url,omitempty"`
+}
+
+// vwapAccumulator sums the trades needed for a volume-weighted average price in one pass
+type vwapAccumulator struct {
+	quoteVolume float64 // sum(price*quantity)
+	volume      float64 // sum(quantity)
+}
+
+func (a *vwapAccumulator) add(t Trade) {
+	a.quoteVolume += t.Price * t.Quantity
+	a.volume += t.Quantity
+}
+
+// value returns the VWAP, or zero if no volume was added
+func (a *vwapAccumulator) value() float64 {
+	if a.volume == 0 {
+		return 0
+	}
+	return a.quoteVolume / a.volume
+}
+
+// TradesVWAP returns the volume-weighted average price of trades, or zero if their
+// total quantity is zero
+func TradesVWAP(trades []Trade) float64 {
+	var acc vwapAccumulator
+	for _, t := range trades {
+		acc.add(t)
+	}
+	return acc.value()
+}
+
+// ComputeVWAP downloads the trade archive for a symbol and date and computes its
+// volume-weighted average price while streaming, so no trades are held in memory.
+// MaxTradesPerFile applies as with StreamTrades, and results are not cached.
+func (c *Connector) ComputeVWAP(ctx context.Context, symbol, year, month, day string) (*VWAPResult, error) {
+	// StreamTrades already computes the VWAP, so only the volume is summed here
+	var volume float64
+	result, err := c.StreamTrades(ctx, symbol, year, month, day, func(trade Trade) error {
+		volume += trade.Quantity
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &VWAPResult{
+		Symbol:      symbol,
+		Date:        result.Date,
+		VWAP:        result.VWAP,
+		Volume:      volume,
+		QuoteVolume: result.VWAP * volume,
+		TradeCount:  result.TradeCount,
+		Warnings:    result.Warnings,
+		ParseStats:  result.ParseStats,
+		SourceURL:   result.SourceURL,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package binancevisionconnector
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTradesVWAP(t *testing.T) {
+	tests := []struct {
+		name   string
+		trades []Trade
+		want   float64
+	}{
+		{"no trades", nil, 0},
+		{"zero volume", []Trade{{Price: 10, Quantity: 0}}, 0},
+		{"single trade", []Trade{{Price: 10, Quantity: 2}}, 10},
+		{"weighted by quantity", []Trade{{Price: 10, Quantity: 3}, {Price: 20, Quantity: 1}}, 12.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TradesVWAP(tt.trades); got != tt.want {
+				t.Errorf("TradesVWAP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeVWAP(t *testing.T) {
+	zipData := createTestZip(t, map[string]string{
+		"BTCUSDT-trades-2025-01-01.csv": "1,100,1,100,1735430400000,true,true\n" +
+			"2,110,3,330,1735430400001,false,true\n" +
+			"3,90,0.5,45,1735430400002,true,true\n",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(zipData)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.BaseURL = server.URL
+	c, err := NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("NewConnectorWithConfig() error = %v", err)
+	}
+
+	result, err := c.ComputeVWAP(context.Background(), "BTCUSDT", "2025", "1", "1")
+	if err != nil {
+		t.Fatalf("ComputeVWAP() error = %v", err)
+	}
+
+	// (100*1 + 110*3 + 90*0.5) / 4.5
+	if want := 475 / 4.5; math.Abs(result.VWAP-want) > 1e-9 {
+		t.Errorf("VWAP = %v, want %v", result.VWAP, want)
+	}
+	if result.Volume != 4.5 || result.QuoteVolume != 475 || result.TradeCount != 3 {
+		t.Errorf("Volume = %v, QuoteVolume = %v, TradeCount = %d, want 4.5, 475, 3", result.Volume, result.QuoteVolume, result.TradeCount)
+	}
+	if result.Date != "2025-01-01" || result.SourceURL != c.TradesURL("BTCUSDT", "2025", "01", "01") {
+		t.Errorf("Date = %q, SourceURL = %q", result.Date, result.SourceURL)
+	}
+
+	// The streaming summary carries the same price
+	streamed, err := c.StreamTrades(context.Background(), "BTCUSDT", "2025", "1", "1", func(Trade) error { return nil })
+	if err != nil {
+		t.Fatalf("StreamTrades() error = %v", err)
+	}
+	if streamed.VWAP != result.VWAP {
+		t.Errorf("StreamTrades() VWAP = %v, want %v", streamed.VWAP, result.VWAP)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+	"binance-vision-connector/handlers"
+)
+
+// runDownloadCommand implements the "download" subcommand, which fetches one day of
+// trades through the connector, or parses a local archive given with -file, and
+// writes the JSON result to a file or stdout. Symbols are validated against access
+// like the HTTP endpoints do. It returns the process exit code.
+func runDownloadCommand(c *binancevisionconnector.Connector, access handlers.SymbolAccess, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	symbol := fs.String("symbol", "", "Trading pair symbol, e.g. BTCUSDT (required unless -file is set)")
+	date := fs.String("date", "", "Trade date as YYYY-MM-DD (required unless -file is set)")
+	out := fs.String("out", "-", "Output file path, or - for stdout")
+	file := fs.String("file", "", "Parse this local archive instead of downloading (not allowed with -symbol and -date)")
+	timeout := fs.Duration("timeout", config.Timeout, "Download and parse timeout")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var result *binancevisionconnector.DownloadResult
+	var err error
+	if *file != "" {
+		if *symbol != "" || *date != "" {
+			fmt.Fprintln(stderr, "download: -file cannot be combined with -symbol or -date")
+			fs.Usage()
+			return 2
+		}
+		result, err = c.ParseLocalArchive(ctx, *file)
+	} else {
+		*symbol = strings.TrimSpace(*symbol)
+		if *symbol == "" || *date == "" {
+			fmt.Fprintln(stderr, "download: -symbol and -date, or -file, are required")
+			fs.Usage()
+			return 2
+		}
+		if err := access.Validate(*symbol); err != nil {
+			fmt.Fprintf(stderr, "download: %v\n", err)
+			return 2
+		}
+		*symbol = strings.ToUpper(*symbol)
+
+		day, parseErr := time.Parse("2006-01-02", *date)
+		if parseErr != nil {
+			fmt.Fprintf(stderr, "download: invalid date %q (expected YYYY-MM-DD)\n", *date)
+			return 2
+		}
+
+		result, err = c.DownloadTrades(ctx, *symbol, day.Format("2006"), day.Format("01"), day.Format("02"))
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "download: %v\n", err)
+		return 1
+	}
+
+	if *out == "-" {
+		err = json.NewEncoder(stdout).Encode(result)
+	} else {
+		err = writeResultFile(*out, result)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "download: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// writeResultFile writes result as JSON to a new file at path. Errors closing the
+// file are reported, since they can mean the data never reached the disk.
+func writeResultFile(path string, result *binancevisionconnector.DownloadResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := json.NewEncoder(f).Encode(result); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
@@ -2,8 +2,19 @@ module binance-vision-connector
 
 go 1.25.5
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/joho/godotenv v1.5.1
+	github.com/parquet-go/parquet-go v0.25.1
+)
 
-require binance-vision-connector/binance-vision-connector v0.0.0-00010101000000-000000000000 // indirect
+require binance-vision-connector/binance-vision-connector v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
 
 replace binance-vision-connector/binance-vision-connector => ./binance-vision-connector
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// errSymbolNotAllowed is returned for symbols a SymbolAccess policy refuses to serve
+var errSymbolNotAllowed = errors.New("symbol not allowed")
+
+// SymbolAccess restricts which symbols the server serves. A symbol is allowed when it is
+// not denied and the allowlist is empty or contains it. The zero value allows every symbol.
+type SymbolAccess struct {
+	allowed     map[string]bool
+	denied      map[string]bool
+	quoteAssets []string // Suffixes every symbol must end in; nil accepts any alphanumeric symbol
+	contracts   bool     // Accept COIN-M contract symbols such as BTCUSD_PERP
+}
+
+// DefaultQuoteAssets are the quote assets strict mode accepts unless others are configured
+var DefaultQuoteAssets = []string{"USDT", "USDC", "FDUSD", "TUSD", "BUSD", "DAI", "BTC", "ETH", "BNB", "EUR", "TRY", "BRL", "JPY"}
+
+// defaultCOINMQuoteAssets are the quote assets of COIN-M contracts, which all settle in USD
+var defaultCOINMQuoteAssets = []string{"USD"}
+
+// DefaultQuoteAssetsFor returns the quote assets strict mode accepts for market unless
+// others are configured
+func DefaultQuoteAssetsFor(market binancevisionconnector.Market) []string {
+	if market == binancevisionconnector.MarketCOINM {
+		return defaultCOINMQuoteAssets
+	}
+	return DefaultQuoteAssets
+}
+
+// NewSymbolAccess creates a policy from allowed and denied symbol lists. Symbols are
+// matched case-insensitively and blank entries are ignored.
+func NewSymbolAccess(allowed, denied []string) SymbolAccess {
+	return SymbolAccess{
+		allowed: symbolSet(allowed),
+		denied:  symbolSet(denied),
+	}
+}
+
+// symbolSet returns the uppercase symbols as a set, or nil if there are none
+func symbolSet(symbols []string) map[string]bool {
+	var set map[string]bool
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" {
+			continue
+		}
+		if set == nil {
+			set = make(map[string]bool)
+		}
+		set[symbol] = true
+	}
+	return set
+}
+
+// Validate checks that symbol is well formed and that the policy allows it, as the
+// HTTP endpoints do
+func (a SymbolAccess) Validate(symbol string) error {
+	return validateSymbol(symbol, a)
+}
+
+// WithQuoteAssets returns a copy of the policy in strict mode, where a symbol must be a
+// base asset followed by one of quoteAssets. Without quote assets it stays lenient.
+func (a SymbolAccess) WithQuoteAssets(quoteAssets []string) SymbolAccess {
+	a.quoteAssets = nil
+	for _, quote := range quoteAssets {
+		if quote = strings.ToUpper(strings.TrimSpace(quote)); quote != "" {
+			a.quoteAssets = append(a.quoteAssets, quote)
+		}
+	}
+	return a
+}
+
+// WithMarket returns a copy of the policy for symbols of market. COIN-M symbols name a
+// contract with a suffix, such as BTCUSD_PERP or BTCUSD_240628, which is accepted there.
+func (a SymbolAccess) WithMarket(market binancevisionconnector.Market) SymbolAccess {
+	a.contracts = market == binancevisionconnector.MarketCOINM
+	return a
+}
+
+// checkQuoteAsset rejects symbols without a known quote asset suffix in strict mode,
+// naming the expected quote assets so the caller can correct a typo
+func (a SymbolAccess) checkQuoteAsset(symbol string) error {
+	if a.quoteAssets == nil {
+		return nil
+	}
+	// A contract's quote asset ends its pair, before the contract suffix
+	symbol, _, _ = strings.Cut(strings.ToUpper(symbol), "_")
+	for _, quote := range a.quoteAssets {
+		if len(symbol) > len(quote) && strings.HasSuffix(symbol, quote) {
+			return nil
+		}
+	}
+	return codedErrorf(CodeInvalidSymbol, "invalid symbol: %s (must be a base asset followed by a quote asset: %s)", symbol, strings.Join(a.quoteAssets, ", "))
+}
+
+// check returns errSymbolNotAllowed if the policy refuses symbol
+func (a SymbolAccess) check(symbol string) error {
+	symbol = strings.ToUpper(symbol)
+	if a.denied[symbol] || (a.allowed != nil && !a.allowed[symbol]) {
+		return codedErrorf(CodeSymbolNotAllowed, "%w: %s is not served by this server", errSymbolNotAllowed, symbol)
+	}
+	return nil
+}
+
+// validationErrorStatus maps a request validation error to an HTTP status: 403 for
+// symbols the access policy refuses, 400 otherwise
+func validationErrorStatus(err error) int {
+	if errors.Is(err, errSymbolNotAllowed) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
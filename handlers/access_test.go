@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestSymbolAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		denied  []string
+		symbol  string
+		wantErr bool
+	}{
+		{"no lists", nil, nil, "BTCUSDT", false},
+		{"allowed", []string{"BTCUSDT", "ETHUSDT"}, nil, "BTCUSDT", false},
+		{"allowed in any case", []string{" btcusdt "}, nil, "BtcUsdt", false},
+		{"not in allowlist", []string{"BTCUSDT"}, nil, "ETHUSDT", true},
+		{"denied", nil, []string{"LUNAUSDT"}, "lunausdt", true},
+		{"not denied", nil, []string{"LUNAUSDT"}, "BTCUSDT", false},
+		{"denied wins over allowed", []string{"BTCUSDT"}, []string{"BTCUSDT"}, "BTCUSDT", true},
+		{"blank allowlist entries allow all", []string{"", " "}, nil, "BTCUSDT", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewSymbolAccess(tt.allowed, tt.denied).check(tt.symbol)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("check(%q) error = %v, wantErr %v", tt.symbol, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSymbolAccess_QuoteAssets(t *testing.T) {
+	tests := []struct {
+		name        string
+		quoteAssets []string
+		symbol      string
+		wantErr     bool
+	}{
+		{"lenient accepts any suffix", nil, "GIBBERISH", false},
+		{"known quote asset", DefaultQuoteAssets, "BTCUSDT", false},
+		{"lowercase symbol", DefaultQuoteAssets, "ethbtc", false},
+		{"leveraged token", DefaultQuoteAssets, "BTCUPUSDT", false},
+		{"unknown quote asset", DefaultQuoteAssets, "BTCUSTD", true},
+		{"quote asset without base", DefaultQuoteAssets, "USDT", true},
+		{"configured quote assets in any case", []string{" usdt "}, "BTCUSDT", false},
+		{"not a configured quote asset", []string{"USDT"}, "ETHBTC", true},
+		{"blank quote assets stay lenient", []string{"", " "}, "GIBBERISH", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSymbol(tt.symbol, SymbolAccess{}.WithQuoteAssets(tt.quoteAssets))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateSymbol(%q) error = %v, wantErr %v", tt.symbol, err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.quoteAssets[0]) {
+				t.Errorf("error %q does not name the expected quote assets", err)
+			}
+		})
+	}
+}
+
+func TestSymbolAccess_Market(t *testing.T) {
+	tests := []struct {
+		name        string
+		market      binancevisionconnector.Market
+		quoteAssets []string
+		symbol      string
+		wantErr     bool
+	}{
+		{"spot rejects contracts", binancevisionconnector.MarketSpot, nil, "BTCUSD_PERP", true},
+		{"perpetual", binancevisionconnector.MarketCOINM, nil, "BTCUSD_PERP", false},
+		{"quarterly contract", binancevisionconnector.MarketCOINM, nil, "ethusd_240628", false},
+		{"plain symbol", binancevisionconnector.MarketCOINM, nil, "BTCUSD", false},
+		{"unknown contract suffix", binancevisionconnector.MarketCOINM, nil, "BTCUSD_NEXT", true},
+		{"empty suffix", binancevisionconnector.MarketCOINM, nil, "BTCUSD_", true},
+		{"two suffixes", binancevisionconnector.MarketCOINM, nil, "BTCUSD_PERP_PERP", true},
+		{"quote asset before the suffix", binancevisionconnector.MarketCOINM, []string{"USD"}, "BTCUSD_PERP", false},
+		{"unknown quote asset before the suffix", binancevisionconnector.MarketCOINM, []string{"USDT"}, "BTCUSD_PERP", true},
+		{"default quote assets", binancevisionconnector.MarketCOINM, DefaultQuoteAssetsFor(binancevisionconnector.MarketCOINM), "BTCUSD_PERP", false},
+		{"default quote assets for a quarterly contract", binancevisionconnector.MarketCOINM, DefaultQuoteAssetsFor(binancevisionconnector.MarketCOINM), "ETHUSD_240628", false},
+		{"default quote assets reject a typo", binancevisionconnector.MarketCOINM, DefaultQuoteAssetsFor(binancevisionconnector.MarketCOINM), "BTCUDS_PERP", true},
+		{"spot default quote assets", binancevisionconnector.MarketSpot, DefaultQuoteAssetsFor(binancevisionconnector.MarketSpot), "BTCUSDT", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := SymbolAccess{}.WithMarket(tt.market).WithQuoteAssets(tt.quoteAssets)
+			if err := validateSymbol(tt.symbol, access); (err != nil) != tt.wantErr {
+				t.Errorf("validateSymbol(%q) error = %v, wantErr %v", tt.symbol, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDownloadHandler_ForbiddenSymbol(t *testing.T) {
+	h := &DownloadHandler{
+		Metrics: &RequestMetrics{},
+		Symbols: NewSymbolAccess([]string{"BTCUSDT"}, nil),
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"disallowed symbol", "/download?SYMBOL=ETHUSDT&YYYY=2025&MM=12&DD=28", http.StatusForbidden},
+		{"malformed symbol", "/download?SYMBOL=ETH-USDT&YYYY=2025&MM=12&DD=28", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.Handle(w, httptest.NewRequest("GET", tt.target, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// arrowBatchSize is the number of trades per record batch. A batch is written as soon
+// as it is full, so only one is held in memory however busy the day was.
+const arrowBatchSize = 64 * 1024
+
+// Arrow type, message header and other enum values from Schema.fbs and Message.fbs
+const (
+	arrowMetadataV5 = 4 // MetadataVersion
+
+	arrowHeaderSchema      = 1 // MessageHeader
+	arrowHeaderRecordBatch = 3
+
+	arrowTypeInt           = 2 // Type
+	arrowTypeFloatingPoint = 3
+	arrowTypeBool          = 6
+	arrowTypeTimestamp     = 10
+
+	arrowDouble      = 2 // Precision
+	arrowMillisecond = 1 // TimeUnit
+)
+
+// arrowContinuation starts every encapsulated IPC message
+const arrowContinuation = 0xFFFFFFFF
+
+// arrowColumn buffers the values of one column of the current record batch. Arrow lays
+// out fixed-width values little-endian and booleans bit packed LSB first, exactly like
+// Parquet's PLAIN encoding, so the values are built with appendParquetValue.
+type arrowColumn struct {
+	field  string
+	name   string // Field name in the schema, following the view's naming
+	values []byte
+}
+
+// arrowWriter writes trades as an Arrow IPC stream: a schema message, one record batch
+// message per arrowBatchSize trades and an end-of-stream marker. Every field is
+// non-nullable, so no validity bitmaps are written.
+type arrowWriter struct {
+	w       io.Writer
+	columns []*arrowColumn
+	rows    int // Rows in the current record batch
+	err     error
+}
+
+// newArrowWriter writes the schema message and returns a writer for the view's fields.
+// Timestamps are always epoch milliseconds, typed as a UTC timestamp.
+func newArrowWriter(w io.Writer, view TradeView) *arrowWriter {
+	aw := &arrowWriter{w: w}
+	var fields fbTables
+	for _, field := range view.fieldsOrAll() {
+		name := field
+		if view.CamelCase {
+			name = tradeFieldCamelNames[field]
+		}
+		aw.columns = append(aw.columns, &arrowColumn{field: field, name: name})
+		fields = append(fields, arrowField(field, name))
+	}
+
+	aw.writeMessage(arrowHeaderSchema, fbTable{1: fbRef(fields)}, nil)
+	return aw
+}
+
+// arrowField returns the Field table of a trade field
+func arrowField(field, name string) fbTable {
+	var typ byte
+	var typeTable fbTable
+	switch parquetColumnTypes[field] {
+	case parquetBoolean:
+		typ, typeTable = arrowTypeBool, fbTable{}
+	case parquetDouble:
+		typ, typeTable = arrowTypeFloatingPoint, fbTable{0: fbInt16(arrowDouble)}
+	default:
+		typ, typeTable = arrowTypeInt, fbTable{0: fbInt32(64), 1: fbBool(true)}
+	}
+	if field == "timestamp" {
+		typ, typeTable = arrowTypeTimestamp, fbTable{0: fbInt16(arrowMillisecond), 1: fbRef(fbString("UTC"))}
+	}
+
+	return fbTable{
+		0: fbRef(fbString(name)),
+		2: fbUint8(typ),
+		3: fbRef(typeTable),
+		5: fbRef(fbTables{}), // children, which readers require even when empty
+	}
+}
+
+// Write adds a trade, writing the record batch out once it is full
+func (aw *arrowWriter) Write(t *binancevisionconnector.Trade) error {
+	for _, column := range aw.columns {
+		column.values = appendParquetValue(column.values, column.field, t, aw.rows)
+	}
+	aw.rows++
+	if aw.rows == arrowBatchSize {
+		aw.flushRecordBatch()
+	}
+	return aw.err
+}
+
+// Close writes the last record batch and the end-of-stream marker. It does not close
+// the underlying writer.
+func (aw *arrowWriter) Close() error {
+	if aw.rows > 0 {
+		aw.flushRecordBatch()
+	}
+	aw.write(binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, arrowContinuation), 0))
+	return aw.err
+}
+
+// flushRecordBatch writes one record batch message and resets the column buffers.
+// Each column has an empty validity buffer followed by its values, padded to 8 bytes.
+func (aw *arrowWriter) flushRecordBatch() {
+	var body, nodes, buffers []byte
+	for _, column := range aw.columns {
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(aw.rows)) // length
+		nodes = binary.LittleEndian.AppendUint64(nodes, 0)               // null_count
+
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body))) // validity
+		buffers = binary.LittleEndian.AppendUint64(buffers, 0)
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body))) // values
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(column.values)))
+		body = append(body, column.values...)
+		body = fbPad(body, 8)
+		column.values = column.values[:0]
+	}
+
+	aw.writeMessage(arrowHeaderRecordBatch, fbTable{
+		0: fbInt64(int64(aw.rows)),
+		1: fbRef(fbStructs(nodes)),
+		2: fbRef(fbStructs(buffers)),
+	}, body)
+	aw.rows = 0
+}
+
+// writeMessage writes an encapsulated message: the continuation marker, the size of
+// the Message flatbuffer, the flatbuffer padded to 8 bytes and the body
+func (aw *arrowWriter) writeMessage(headerType byte, header fbTable, body []byte) {
+	metadata := fbFinish(fbTable{
+		0: fbInt16(arrowMetadataV5),
+		1: fbUint8(headerType),
+		2: fbRef(header),
+		3: fbInt64(int64(len(body))),
+	})
+	prefix := binary.LittleEndian.AppendUint32(nil, arrowContinuation)
+	prefix = binary.LittleEndian.AppendUint32(prefix, uint32(len(metadata)))
+	aw.write(prefix)
+	aw.write(metadata)
+	aw.write(body)
+}
+
+// write writes p, remembering the first error so callers can check it once
+func (aw *arrowWriter) write(p []byte) {
+	if aw.err != nil {
+		return
+	}
+	_, aw.err = aw.w.Write(p)
+}
+
+// The fb types describe the subset of FlatBuffers needed for Arrow metadata, which
+// fbFinish serializes front to back: every table is preceded by its vtable and
+// followed by the objects it references, so all references point forward.
+type (
+	// fbTable maps field IDs to values; missing fields take their default
+	fbTable map[int]fbValue
+	// fbTables is a vector of tables
+	fbTables []fbTable
+	// fbStructs is a vector of 16-byte structs, such as FieldNode and Buffer, already encoded
+	fbStructs []byte
+	// fbString is a string
+	fbString string
+)
+
+// fbValue is an inline table field: a little-endian scalar, or a reference to a
+// table, vector or string when ref is set
+type fbValue struct {
+	size   int
+	scalar uint64
+	ref    interface{}
+}
+
+func fbBool(v bool) fbValue {
+	if v {
+		return fbValue{size: 1, scalar: 1}
+	}
+	return fbValue{size: 1}
+}
+
+func fbUint8(v byte) fbValue      { return fbValue{size: 1, scalar: uint64(v)} }
+func fbInt16(v int16) fbValue     { return fbValue{size: 2, scalar: uint64(uint16(v))} }
+func fbInt32(v int32) fbValue     { return fbValue{size: 4, scalar: uint64(uint32(v))} }
+func fbInt64(v int64) fbValue     { return fbValue{size: 8, scalar: uint64(v)} }
+func fbRef(v interface{}) fbValue { return fbValue{size: 4, ref: v} }
+
+// fbFinish serializes root as a flatbuffer padded to a multiple of 8 bytes
+func fbFinish(root fbTable) []byte {
+	buf := make([]byte, 4) // Offset of the root table
+	buf = fbAppendTable(buf, root, 0)
+	return fbPad(buf, 8)
+}
+
+// fbAppendTable appends t, preceded by its vtable and followed by the objects it
+// references, and points the uoffset at ref to it
+func fbAppendTable(buf []byte, t fbTable, ref int) []byte {
+	ids := make([]int, 0, len(t))
+	maxID := -1
+	for id := range t {
+		ids = append(ids, id)
+		maxID = max(maxID, id)
+	}
+	// Largest fields first keeps every field aligned with little padding
+	sort.Slice(ids, func(i, j int) bool {
+		if t[ids[i]].size != t[ids[j]].size {
+			return t[ids[i]].size > t[ids[j]].size
+		}
+		return ids[i] < ids[j]
+	})
+
+	// Lay out the table: its soffset to the vtable, then the fields
+	fieldOffsets := make([]uint16, maxID+1)
+	size := 4
+	for _, id := range ids {
+		field := t[id]
+		for size%field.size != 0 {
+			size++
+		}
+		fieldOffsets[id] = uint16(size)
+		size += field.size
+	}
+
+	// The vtable goes right before the table, which starts 8-byte aligned
+	vtableSize := 4 + 2*len(fieldOffsets)
+	for (len(buf)+vtableSize)%8 != 0 {
+		buf = append(buf, 0)
+	}
+	vtable := len(buf)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(vtableSize))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(size))
+	for _, offset := range fieldOffsets {
+		buf = binary.LittleEndian.AppendUint16(buf, offset)
+	}
+
+	table := len(buf)
+	binary.LittleEndian.PutUint32(buf[ref:], uint32(table-ref))
+	buf = append(buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(buf[table:], uint32(int32(table-vtable)))
+	for _, id := range ids {
+		field := t[id]
+		at := buf[table+int(fieldOffsets[id]):]
+		switch field.size {
+		case 1:
+			at[0] = byte(field.scalar)
+		case 2:
+			binary.LittleEndian.PutUint16(at, uint16(field.scalar))
+		case 4:
+			binary.LittleEndian.PutUint32(at, uint32(field.scalar))
+		case 8:
+			binary.LittleEndian.PutUint64(at, field.scalar)
+		}
+	}
+
+	// Referenced objects follow in field ID order
+	sort.Ints(ids)
+	for _, id := range ids {
+		if field := t[id]; field.ref != nil {
+			buf = fbAppendRef(buf, field.ref, table+int(fieldOffsets[id]))
+		}
+	}
+	return buf
+}
+
+// fbAppendRef appends a referenced object and points the uoffset at ref to it
+func fbAppendRef(buf []byte, v interface{}, ref int) []byte {
+	switch v := v.(type) {
+	case fbTable:
+		return fbAppendTable(buf, v, ref)
+	case fbString:
+		buf = fbPad(buf, 4)
+		binary.LittleEndian.PutUint32(buf[ref:], uint32(len(buf)-ref))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(v)))
+		return append(append(buf, v...), 0)
+	case fbStructs:
+		// The length precedes the elements, which must be 8-byte aligned
+		for (len(buf)+4)%8 != 0 {
+			buf = append(buf, 0)
+		}
+		binary.LittleEndian.PutUint32(buf[ref:], uint32(len(buf)-ref))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(v)/16))
+		return append(buf, v...)
+	case fbTables:
+		buf = fbPad(buf, 4)
+		binary.LittleEndian.PutUint32(buf[ref:], uint32(len(buf)-ref))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(v)))
+		elems := len(buf)
+		buf = append(buf, make([]byte, 4*len(v))...)
+		for i, table := range v {
+			buf = fbAppendTable(buf, table, elems+4*i)
+		}
+		return buf
+	}
+	return buf
+}
+
+// fbPad appends zero bytes until the length of buf is a multiple of align
+func fbPad(buf []byte, align int) []byte {
+	for len(buf)%align != 0 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// fbTableReader reads the fields of a flatbuffer table, to check the metadata
+// arrowWriter produces
+type fbTableReader struct {
+	buf []byte
+	pos int
+}
+
+func fbRootTable(buf []byte) fbTableReader {
+	return fbTableReader{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field returns the position of field id, or false when it is absent
+func (t fbTableReader) field(id int) (int, bool) {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if 4+2*id >= int(binary.LittleEndian.Uint16(t.buf[vtable:])) {
+		return 0, false
+	}
+	offset := int(binary.LittleEndian.Uint16(t.buf[vtable+4+2*id:]))
+	return t.pos + offset, offset != 0
+}
+
+func (t fbTableReader) scalar(id, size int) uint64 {
+	at, ok := t.field(id)
+	if !ok {
+		return 0
+	}
+	var b [8]byte
+	copy(b[:], t.buf[at:at+size])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// deref follows the uoffset of field id to the object it references
+func (t fbTableReader) deref(id int) int {
+	at, ok := t.field(id)
+	if !ok {
+		return -1
+	}
+	return at + int(binary.LittleEndian.Uint32(t.buf[at:]))
+}
+
+func (t fbTableReader) table(id int) fbTableReader {
+	return fbTableReader{buf: t.buf, pos: t.deref(id)}
+}
+
+func (t fbTableReader) str(id int) string {
+	at := t.deref(id)
+	n := int(binary.LittleEndian.Uint32(t.buf[at:]))
+	return string(t.buf[at+4 : at+4+n])
+}
+
+// vector returns the position of the first element and the length of vector field id
+func (t fbTableReader) vector(id int) (int, int) {
+	at := t.deref(id)
+	return at + 4, int(binary.LittleEndian.Uint32(t.buf[at:]))
+}
+
+func (t fbTableReader) tableAt(elem int) fbTableReader {
+	return fbTableReader{buf: t.buf, pos: elem + int(binary.LittleEndian.Uint32(t.buf[elem:]))}
+}
+
+// readArrowMessages splits an Arrow IPC stream into its Message flatbuffers and bodies
+func readArrowMessages(t *testing.T, data []byte) (metadata []fbTableReader, bodies [][]byte) {
+	t.Helper()
+	for {
+		if len(data) < 8 || binary.LittleEndian.Uint32(data) != arrowContinuation {
+			t.Fatalf("message does not start with the continuation marker: % x", data[:min(8, len(data))])
+		}
+		size := int(binary.LittleEndian.Uint32(data[4:]))
+		if size == 0 {
+			if len(data) != 8 {
+				t.Errorf("%d bytes after the end-of-stream marker", len(data)-8)
+			}
+			return metadata, bodies
+		}
+		if size%8 != 0 {
+			t.Errorf("metadata size %d is not a multiple of 8", size)
+		}
+		message := fbRootTable(data[8 : 8+size])
+		bodyLength := int(message.scalar(3, 8))
+		metadata = append(metadata, message)
+		bodies = append(bodies, data[8+size:8+size+bodyLength])
+		data = data[8+size+bodyLength:]
+	}
+}
+
+func TestArrowWriter(t *testing.T) {
+	var buf bytes.Buffer
+	aw := newArrowWriter(&buf, TradeView{Fields: []string{"trade_id", "price", "timestamp", "is_buyer_maker"}, CamelCase: true})
+	for i := 0; i < 10; i++ {
+		trade := &binancevisionconnector.Trade{TradeID: int64(100 + i), Price: float64(i) / 2, Timestamp: 1735430400000 + int64(i), IsBuyerMaker: i%3 == 0}
+		if err := aw.Write(trade); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	messages, bodies := readArrowMessages(t, buf.Bytes())
+	if len(messages) != 2 {
+		t.Fatalf("found %d messages, want a schema and one record batch", len(messages))
+	}
+
+	// The schema names the fields after the view and types each of them
+	schema := messages[0]
+	if schema.scalar(0, 2) != arrowMetadataV5 || schema.scalar(1, 1) != arrowHeaderSchema {
+		t.Fatalf("first message has version %d and header type %d, want a V5 schema", schema.scalar(0, 2), schema.scalar(1, 1))
+	}
+	fieldsAt, numFields := schema.table(2).vector(1)
+	wantFields := []struct {
+		name string
+		typ  uint64
+	}{{"tradeId", arrowTypeInt}, {"price", arrowTypeFloatingPoint}, {"timestamp", arrowTypeTimestamp}, {"isBuyerMaker", arrowTypeBool}}
+	if numFields != len(wantFields) {
+		t.Fatalf("schema has %d fields, want %d", numFields, len(wantFields))
+	}
+	for i, want := range wantFields {
+		field := schema.tableAt(fieldsAt + 4*i)
+		if name, typ := field.str(0), field.scalar(2, 1); name != want.name || typ != want.typ {
+			t.Errorf("field %d = %s of type %d, want %s of type %d", i, name, typ, want.name, want.typ)
+		}
+		if _, children := field.vector(5); children != 0 {
+			t.Errorf("field %s has %d children", want.name, children)
+		}
+	}
+	if ts := schema.tableAt(fieldsAt + 8).table(3); ts.scalar(0, 2) != arrowMillisecond || ts.str(1) != "UTC" {
+		t.Errorf("timestamp has unit %d in %q, want milliseconds in UTC", ts.scalar(0, 2), ts.str(1))
+	}
+
+	// The record batch has an empty validity buffer and the values of each column
+	batchMessage := messages[1]
+	if batchMessage.scalar(1, 1) != arrowHeaderRecordBatch {
+		t.Fatalf("second message has header type %d, want a record batch", batchMessage.scalar(1, 1))
+	}
+	batch := batchMessage.table(2)
+	if length := batch.scalar(0, 8); length != 10 {
+		t.Errorf("record batch length = %d, want 10", length)
+	}
+	nodesAt, numNodes := batch.vector(1)
+	buffersAt, numBuffers := batch.vector(2)
+	if numNodes != 4 || numBuffers != 8 {
+		t.Fatalf("record batch has %d nodes and %d buffers, want 4 and 8", numNodes, numBuffers)
+	}
+	if nodesAt%8 != 0 || buffersAt%8 != 0 {
+		t.Errorf("struct vectors at %d and %d are not 8-byte aligned", nodesAt, buffersAt)
+	}
+	column := func(i int) []byte {
+		buffer := batch.buf[buffersAt+16*(2*i+1):]
+		offset, length := binary.LittleEndian.Uint64(buffer), binary.LittleEndian.Uint64(buffer[8:])
+		if offset%8 != 0 {
+			t.Errorf("column %d starts at unaligned offset %d", i, offset)
+		}
+		return bodies[1][offset : offset+length]
+	}
+	for i := 0; i < 10; i++ {
+		if got := int64(binary.LittleEndian.Uint64(column(0)[8*i:])); got != int64(100+i) {
+			t.Errorf("tradeId[%d] = %d, want %d", i, got, 100+i)
+		}
+		if got := math.Float64frombits(binary.LittleEndian.Uint64(column(1)[8*i:])); got != float64(i)/2 {
+			t.Errorf("price[%d] = %v, want %v", i, got, float64(i)/2)
+		}
+	}
+	// Rows 0, 3, 6 and 9 are buyer maker, bit packed LSB first
+	if want := []byte{0x49, 0x02}; !bytes.Equal(column(3), want) {
+		t.Errorf("isBuyerMaker = % x, want % x", column(3), want)
+	}
+}
+
+func TestArrowWriter_NoTrades(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newArrowWriter(&buf, TradeView{}).Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	messages, _ := readArrowMessages(t, buf.Bytes())
+	if len(messages) != 1 {
+		t.Errorf("found %d messages, want only the schema", len(messages))
+	}
+}
+
+// arrowInteropScript reads an Arrow IPC stream with pyarrow and prints its schema and
+// rows as JSON, with timestamps as epoch milliseconds
+const arrowInteropScript = `
+import json, sys
+import pyarrow as pa
+table = pa.ipc.open_stream(open(sys.argv[1], "rb")).read_all()
+rows = {}
+for field, col in zip(table.schema, table.columns):
+    if pa.types.is_timestamp(field.type):
+        col = col.cast(pa.int64())
+    rows[field.name] = col.to_pylist()
+print(json.dumps({"types": [str(t) for t in table.schema.types], "batches": len(table.to_batches()), "rows": rows}))
+`
+
+// TestArrowWriter_Interop reads the stream back with pyarrow, across more than one
+// record batch. It is skipped where python3 or pyarrow is not installed.
+func TestArrowWriter_Interop(t *testing.T) {
+	if err := exec.Command("python3", "-c", "import pyarrow").Run(); err != nil {
+		t.Skip("python3 with pyarrow is not available")
+	}
+
+	const n = arrowBatchSize + 10
+	var buf bytes.Buffer
+	aw := newArrowWriter(&buf, TradeView{Fields: []string{"trade_id", "price", "timestamp", "is_buyer_maker"}})
+	for i := 0; i < n; i++ {
+		trade := &binancevisionconnector.Trade{TradeID: int64(i), Price: float64(i) / 4, Timestamp: 1735430400000 + int64(i), IsBuyerMaker: i%3 == 0}
+		if err := aw.Write(trade); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "trades.arrow")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("python3", "-c", arrowInteropScript, path).Output()
+	if err != nil {
+		t.Fatalf("pyarrow failed to read the stream: %v", err)
+	}
+
+	var got struct {
+		Types   []string
+		Batches int
+		Rows    struct {
+			TradeID      []int64   `json:"trade_id"`
+			Price        []float64 `json:"price"`
+			Timestamp    []int64   `json:"timestamp"`
+			IsBuyerMaker []bool    `json:"is_buyer_maker"`
+		}
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("decoding pyarrow output: %v", err)
+	}
+	wantTypes := []string{"int64", "double", "timestamp[ms, tz=UTC]", "bool"}
+	if len(got.Types) != len(wantTypes) {
+		t.Fatalf("schema types = %v, want %v", got.Types, wantTypes)
+	}
+	for i := range wantTypes {
+		if got.Types[i] != wantTypes[i] {
+			t.Errorf("schema types = %v, want %v", got.Types, wantTypes)
+			break
+		}
+	}
+	if got.Batches != 2 {
+		t.Errorf("pyarrow read %d record batches, want 2", got.Batches)
+	}
+	if len(got.Rows.TradeID) != n || len(got.Rows.Price) != n || len(got.Rows.Timestamp) != n || len(got.Rows.IsBuyerMaker) != n {
+		t.Fatalf("pyarrow read %d rows, want %d", len(got.Rows.TradeID), n)
+	}
+	for i := 0; i < n; i++ {
+		if got.Rows.TradeID[i] != int64(i) || got.Rows.Price[i] != float64(i)/4 ||
+			got.Rows.Timestamp[i] != 1735430400000+int64(i) || got.Rows.IsBuyerMaker[i] != (i%3 == 0) {
+			t.Fatalf("row %d = %d, %v, %d, %v", i, got.Rows.TradeID[i], got.Rows.Price[i], got.Rows.Timestamp[i], got.Rows.IsBuyerMaker[i])
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader is the request header checked by RequireAPIKey
+const APIKeyHeader = "X-API-Key"
+
+// APIKeys is the set of keys RequireAPIKey accepts. The zero value accepts every
+// request, so authentication is off unless keys are configured.
+type APIKeys struct {
+	digests [][sha256.Size]byte // SHA-256 of each key, so comparisons take the same time for any length
+}
+
+// NewAPIKeys creates a key set from keys, ignoring blank entries
+func NewAPIKeys(keys []string) APIKeys {
+	var set APIKeys
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			set.digests = append(set.digests, sha256.Sum256([]byte(key)))
+		}
+	}
+	return set
+}
+
+// Enabled reports whether any keys are configured
+func (k APIKeys) Enabled() bool {
+	return len(k.digests) > 0
+}
+
+// valid reports whether key is one of the configured keys. Every key is compared
+// in constant time, so the response time does not reveal which one nearly matched.
+func (k APIKeys) valid(key string) bool {
+	digest := sha256.Sum256([]byte(key))
+	match := 0
+	for _, candidate := range k.digests {
+		match |= subtle.ConstantTimeCompare(digest[:], candidate[:])
+	}
+	return match == 1
+}
+
+// requestAPIKey returns the key sent in the X-API-Key header or, failing that, as an
+// Authorization: Bearer token
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// RequireAPIKey rejects requests without one of keys with 401. With no keys
+// configured every request is passed through.
+func RequireAPIKey(next http.HandlerFunc, keys APIKeys) http.HandlerFunc {
+	if !keys.Enabled() {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := requestAPIKey(r)
+		if key == "" || !keys.valid(key) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			message := "Invalid API key"
+			if key == "" {
+				message = "Missing API key: send it in the X-API-Key header or as an Authorization: Bearer token"
+			}
+			WriteJSONResponse(w, http.StatusUnauthorized, APIResponse{
+				Success:   false,
+				Error:     message,
+				ErrorCode: CodeUnauthorized,
+			})
+			return
+		}
+		next(w, r)
+	}
+}
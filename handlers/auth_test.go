@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	handler := RequireAPIKey(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, NewAPIKeys([]string{"key-one", " ", "key-two"}))
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"no key", "", "", http.StatusUnauthorized},
+		{"x-api-key", "X-API-Key", "key-two", http.StatusNoContent},
+		{"bearer token", "Authorization", "Bearer key-one", http.StatusNoContent},
+		{"lowercase bearer", "Authorization", "bearer key-one", http.StatusNoContent},
+		{"wrong key", "X-API-Key", "key-three", http.StatusUnauthorized},
+		{"prefix of a key", "X-API-Key", "key", http.StatusUnauthorized},
+		{"basic auth", "Authorization", "Basic a2V5LW9uZTo=", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/download", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("WWW-Authenticate = %q, want Bearer", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireAPIKey_Disabled(t *testing.T) {
+	handler := RequireAPIKey(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, NewAPIKeys(nil))
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/download", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d without configured keys", w.Code, http.StatusNoContent)
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// BatchHandler handles batch download requests
+type BatchHandler struct {
+	Connector    *binancevisionconnector.Connector
+	Timeout      time.Duration
+	Metrics      *RequestMetrics
+	MaxBatchSize int          // Maximum number of items per batch
+	Concurrency  int          // Maximum number of concurrent downloads per batch
+	MinDate      time.Time    // Earliest date accepted (zero = no minimum)
+	Symbols      SymbolAccess // Symbols the server may serve (zero value = all)
+}
+
+// BatchItem represents a single symbol/date combination in a batch request
+type BatchItem struct {
+	Symbol string `json:"symbol"`
+	Year   string `json:"year"`
+	Month  string `json:"month"`
+	Day    string `json:"day"`
+}
+
+// BatchItemResult represents the outcome of a single batch item
+type BatchItemResult struct {
+	Symbol    string                                 `json:"symbol"`
+	Date      string                                 `json:"date"`
+	Success   bool                                   `json:"success"`
+	Error     string                                 `json:"error,omitempty"`
+	ErrorCode string                                 `json:"error_code,omitempty"`
+	Data      *binancevisionconnector.DownloadResult `json:"data,omitempty"`
+}
+
+// Handle handles batch download requests
+func (h *BatchHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var items []BatchItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, requestBodyErrorStatus(err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Invalid request body: %v", err),
+			ErrorCode: requestBodyErrorCode(err),
+		})
+		return
+	}
+
+	if len(items) == 0 {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     "Batch must contain at least one item",
+			ErrorCode: CodeInvalidRequestBody,
+		})
+		return
+	}
+
+	if h.MaxBatchSize > 0 && len(items) > h.MaxBatchSize {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Batch size %d exceeds maximum of %d", len(items), h.MaxBatchSize),
+			ErrorCode: CodeInvalidRequestBody,
+		})
+		return
+	}
+
+	concurrency := h.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	// Create context with timeout for the whole batch. Items share it, so it bounds
+	// the batch as a whole rather than giving each item the full timeout.
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+
+	results := make([]BatchItemResult, len(items))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	// Items start in request order, so a batch cut short by its timeout has
+	// completed its first items rather than an arbitrary subset
+	for i, item := range items {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, item BatchItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = h.downloadItem(ctx, item)
+		}(i, item)
+	}
+
+	wg.Wait()
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	h.Metrics.RecordSuccess()
+
+	message := fmt.Sprintf("Processed %d items: %d succeeded, %d failed", len(results), succeeded, len(results)-succeeded)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		message += " (batch timeout exceeded)"
+	}
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: message,
+		Data:    results,
+	})
+}
+
+// parseBatchItem validates a batch item the way parseDownloadParams validates query
+// parameters. On failure the returned params still hold the symbol, upper-cased once
+// it is valid, so the item can be reported under it.
+func parseBatchItem(item BatchItem, minDate time.Time, access SymbolAccess) (downloadParams, error) {
+	symbolRaw := strings.TrimSpace(item.Symbol)
+	year := strings.TrimSpace(item.Year)
+	month := strings.TrimSpace(item.Month)
+	day := strings.TrimSpace(item.Day)
+
+	params := downloadParams{Symbol: symbolRaw}
+	if symbolRaw == "" || year == "" || month == "" || day == "" {
+		return params, codedErrorf(CodeMissingParameter, "Missing required fields: symbol, year, month, day")
+	}
+	if err := validateSymbol(symbolRaw, access); err != nil {
+		return params, err
+	}
+	params.Symbol = strings.ToUpper(symbolRaw)
+
+	if err := validateDate(year, month, day); err != nil {
+		return params, err
+	}
+	if err := validateMinDate(year, month, day, minDate); err != nil {
+		return params, err
+	}
+	params.Year, params.Month, params.Day = year, month, day
+	return params, nil
+}
+
+// downloadItem validates and downloads a single batch item
+func (h *BatchHandler) downloadItem(ctx context.Context, item BatchItem) BatchItemResult {
+	params, err := parseBatchItem(item, h.MinDate, h.Symbols)
+	result := BatchItemResult{Symbol: params.Symbol}
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCode = validationErrorCode(err)
+		return result
+	}
+	symbol := params.Symbol
+	year, month, day := formatDate(params.Year, params.Month, params.Day)
+	result.Date = fmt.Sprintf("%s-%s-%s", year, month, day)
+
+	// Items still queued when the batch times out are not started, so the batch
+	// returns the items that completed without waiting on the rest
+	if err := ctx.Err(); err != nil {
+		result.Error = fmt.Sprintf("Not downloaded: batch %v", err)
+		result.ErrorCode = downloadErrorCode(err)
+		return result
+	}
+
+	logger := Logger(ctx).With("symbol", symbol, "date", result.Date)
+	start := time.Now()
+
+	data, err := h.Connector.DownloadTrades(ctx, symbol, year, month, day)
+	if err != nil {
+		logger.Error("batch item download failed", "duration", time.Since(start), "error", err)
+		result.Error = fmt.Sprintf("Failed to download and parse trades: %v", err)
+		result.ErrorCode = downloadErrorCode(err)
+		return result
+	}
+
+	h.Metrics.RecordDownloadSuccess(time.Since(start), data.TradeCount)
+	logger.Info("batch item download succeeded", "duration", time.Since(start), "trade_count", data.TradeCount)
+
+	result.Success = true
+	result.Data = data
+	return result
+}
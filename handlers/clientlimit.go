@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// clientSweepInterval is how often idle client buckets are dropped, bounding the
+// memory used by clients that stopped sending requests
+const clientSweepInterval = time.Minute
+
+// ClientRateLimiter limits the request rate of each client with its own token bucket,
+// so one caller cannot monopolize the server. A nil limiter allows every request.
+type ClientRateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // Tokens added per second
+	burst     float64 // Maximum number of tokens
+	clients   map[string]*clientBucket
+	lastSweep time.Time
+	now       func() time.Time // Replaced in tests
+}
+
+// clientBucket is the token bucket of one client
+type clientBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewClientRateLimiter creates a limiter allowing each client requestsPerMinute with the
+// given burst. It returns nil (unlimited) when requestsPerMinute is not positive.
+func NewClientRateLimiter(requestsPerMinute float64, burst int) *ClientRateLimiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &ClientRateLimiter{
+		rate:    requestsPerMinute / 60,
+		burst:   float64(burst),
+		clients: make(map[string]*clientBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from client's bucket. When none is left it returns false and
+// how long until the next token is added.
+func (l *ClientRateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= clientSweepInterval {
+		l.sweep(now)
+	}
+
+	bucket, ok := l.clients[client]
+	if !ok {
+		bucket = &clientBucket{tokens: l.burst, last: now}
+		l.clients[client] = bucket
+	}
+	bucket.tokens = min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets that have refilled completely, since a new bucket for the
+// same client would start out identical
+func (l *ClientRateLimiter) sweep(now time.Time) {
+	for client, bucket := range l.clients {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.clients, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// clientIdentity identifies the caller by API key when keys are configured (and so
+// already checked by RequireAPIKey), otherwise by remote IP. Keys are hashed so the
+// limiter does not hold them in memory. Client supplied headers such as
+// X-Forwarded-For are not trusted, so behind a proxy all clients share its IP.
+func clientIdentity(r *http.Request, keys APIKeys) string {
+	if keys.Enabled() {
+		if key := requestAPIKey(r); key != "" {
+			sum := sha256.Sum256([]byte(key))
+			return "key:" + hex.EncodeToString(sum[:8])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// LimitClientRate rejects requests from clients that exceeded their rate with 429 and
+// a Retry-After header. A nil limiter passes every request through.
+func LimitClientRate(next http.HandlerFunc, limiter *ClientRateLimiter, keys APIKeys) http.HandlerFunc {
+	if limiter == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ok, retryAfter := limiter.allow(clientIdentity(r, keys)); !ok {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			WriteJSONResponse(w, http.StatusTooManyRequests, APIResponse{
+				Success:   false,
+				Error:     fmt.Sprintf("Too many requests from this client, retry after %d seconds", seconds),
+				ErrorCode: CodeClientRateLimited,
+			})
+			return
+		}
+		next(w, r)
+	}
+}
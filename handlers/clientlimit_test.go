@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewClientRateLimiter(60, 2) // One token per second
+	limiter.now = func() time.Time { return now }
+
+	handler := LimitClientRate(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, limiter, APIKeys{})
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/download", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	// The burst is spent, then the client has to wait for the next token
+	for i := 0; i < 2; i++ {
+		if w := request("10.0.0.1:1234"); w.Code != http.StatusNoContent {
+			t.Fatalf("request %d status = %d, want %d", i+1, w.Code, http.StatusNoContent)
+		}
+	}
+	w := request("10.0.0.1:5678")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status over the limit = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+
+	// Other clients have their own bucket
+	if w := request("10.0.0.2:1234"); w.Code != http.StatusNoContent {
+		t.Errorf("other client status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	now = now.Add(time.Second)
+	if w := request("10.0.0.1:1234"); w.Code != http.StatusNoContent {
+		t.Errorf("status after refill = %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	// Idle clients are dropped once their bucket is full again
+	now = now.Add(clientSweepInterval)
+	request("10.0.0.3:1234")
+	if len(limiter.clients) != 1 {
+		t.Errorf("%d client buckets after sweep, want 1", len(limiter.clients))
+	}
+}
+
+func TestClientIdentity(t *testing.T) {
+	keys := NewAPIKeys([]string{"secret"})
+
+	withKey := httptest.NewRequest(http.MethodGet, "/download", nil)
+	withKey.RemoteAddr = "10.0.0.1:1234"
+	withKey.Header.Set(APIKeyHeader, "secret")
+
+	if got := clientIdentity(withKey, keys); got == "ip:10.0.0.1" || got == "key:secret" {
+		t.Errorf("clientIdentity() with API key = %q, want a hashed key identity", got)
+	}
+
+	// Without configured keys the header is not trusted
+	if got := clientIdentity(withKey, APIKeys{}); got != "ip:10.0.0.1" {
+		t.Errorf("clientIdentity() without configured keys = %q, want ip:10.0.0.1", got)
+	}
+}
+
+func TestLimitClientRate_Disabled(t *testing.T) {
+	if limiter := NewClientRateLimiter(0, 10); limiter != nil {
+		t.Fatal("NewClientRateLimiter(0) should return nil")
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// archiveLastModified returns the end of a day (midnight UTC of the next day), the
+// earliest time Binance can publish its archive. Exported files use it as their
+// modification time when upstream sent no Last-Modified.
+func archiveLastModified(params downloadParams) time.Time {
+	day, err := time.Parse("2006-01-02", params.Date())
+	if err != nil {
+		return time.Time{}
+	}
+	return day.AddDate(0, 0, 1)
+}
+
+// notModifiedSince reports whether an If-Modified-Since precondition shows the client
+// already has the data. It is ignored when If-None-Match is present (RFC 9110 13.1.3).
+func notModifiedSince(r *http.Request, lastModified time.Time) bool {
+	if lastModified.IsZero() || r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+// tradesETag returns a weak ETag identifying a trades response by symbol, date, trade
+// count and every query parameter that shapes the representation. It is weak because
+// JSON responses also include per-request timings.
+func tradesETag(r *http.Request, symbol, date string, tradeCount int, format outputFormat) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%d|%s|%s", symbol, date, tradeCount, format, shapingQuery(r)))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// shapingQuery returns the query parameters that shape a trades response, which is
+// all but timeout, encoded in key order so equivalent queries compare equal
+func shapingQuery(r *http.Request) string {
+	query := url.Values{}
+	for key, values := range r.URL.Query() {
+		if key != "timeout" {
+			query[key] = values
+		}
+	}
+	return query.Encode()
+}
+
+// etagMatches reports whether an If-None-Match header matches etag using the weak
+// comparison If-None-Match calls for
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified sends a 304 response, which must not have a body
+func writeNotModified(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotModified)
+}
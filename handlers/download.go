@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -19,6 +23,22 @@ type DownloadHandler struct {
 	Connector *binancevisionconnector.Connector
 	Timeout   time.Duration
 	Metrics   *RequestMetrics
+	MinDate   time.Time // Earliest date accepted (zero = no minimum)
+	OutputDir string    // Directory ?out= files are written to (empty = file output disabled)
+
+	// MaxTimeout caps the per-request ?timeout= override (zero = Timeout)
+	MaxTimeout time.Duration
+
+	// Concurrency bounds how many symbols of a multi-symbol request, or days of a
+	// /download/days request, download at once
+	Concurrency int
+
+	Symbols SymbolAccess // Symbols the server may serve (zero value = all)
+
+	Responses *ResponseCache // Rendered /download responses (nil = disabled)
+
+	MaxExportDays int // Longest range /export accepts (0 = 366)
+	MaxDays       int // Most DATES /download/days accepts (0 = 50)
 }
 
 // APIResponse represents a standard API response
@@ -27,6 +47,10 @@ type APIResponse struct {
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+
+	ErrorCode string `json:"error_code,omitempty"` // Stable machine-readable error kind, one of the Code constants
+
+	SourceURL string `json:"source_url,omitempty"` // Upstream URL a failed download attempted
 }
 
 // WriteJSONResponse writes a JSON response
@@ -34,119 +58,1031 @@ func WriteJSONResponse(w http.ResponseWriter, statusCode int, response APIRespon
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Failed to encode JSON response: %v", err)
+		slog.Error("failed to encode JSON response", "error", err)
 	}
 }
 
 // Handle handles download requests
 func (h *DownloadHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		WriteJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   "Method not allowed",
-		})
-		return
-	}
-
 	// Track request
+	h.Metrics.Mu.Lock()
 	h.Metrics.TotalRequests++
 	h.Metrics.ActiveRequests++
+	h.Metrics.Mu.Unlock()
 	defer func() {
+		h.Metrics.Mu.Lock()
 		h.Metrics.ActiveRequests--
+		h.Metrics.Mu.Unlock()
 	}()
 
-	// Get query parameters
-	symbolRaw := strings.TrimSpace(r.URL.Query().Get("SYMBOL"))
-	year := strings.TrimSpace(r.URL.Query().Get("YYYY"))
-	month := strings.TrimSpace(r.URL.Query().Get("MM"))
-	day := strings.TrimSpace(r.URL.Query().Get("DD"))
+	// A comma-separated SYMBOL downloads several symbols for the same day
+	if strings.Contains(r.URL.Query().Get("SYMBOL"), ",") {
+		h.handleMultiSymbol(w, r)
+		return
+	}
 
-	// Validate parameters
-	if symbolRaw == "" || year == "" || month == "" || day == "" {
-		h.Metrics.FailedRequests++
+	params, err := parseDownloadParams(r, h.MinDate, h.Symbols)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, validationErrorStatus(err), APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+	symbol, year, month, day := params.Symbol, params.Year, params.Month, params.Day
+
+	pagination, paginated, err := parsePagination(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
 		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Missing required parameters: SYMBOL, YYYY, MM, DD",
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
 		})
 		return
 	}
 
-	// Validate symbol format (before converting to uppercase)
-	if err := validateSymbol(symbolRaw); err != nil {
-		h.Metrics.FailedRequests++
+	sample, sampled, err := parseTradeSample(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
 		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   err.Error(),
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
 		})
 		return
 	}
 
-	// Convert to uppercase after validation
-	symbol := strings.ToUpper(symbolRaw)
+	view, err := parseTradeView(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
 
-	// Validate date format
-	if err := validateDate(year, month, day); err != nil {
-		h.Metrics.FailedRequests++
+	format, err := parseOutputFormat(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+	// The format may come from the Accept header, so every response from here on,
+	// JSON included, depends on it
+	w.Header().Add("Vary", "Accept")
+
+	outPath, toFile, err := parseOutputPath(r, h.OutputDir)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	// Parquet and Arrow are written while the archive is parsed, so there is no result to page through or save
+	if format.streamed() && (paginated || toFile) {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("format=%s cannot be combined with offset, limit or out", format),
+			ErrorCode: CodeInvalidParameter,
+		})
+		return
+	}
+
+	validateOnly, err := parseValidateOnly(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	includeVWAP, err := parseIncludeVWAP(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	// A sample is a page of its own, and the VWAP of the day would need every trade parsed
+	if sampled && (paginated || includeVWAP || format.streamed()) {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     "head and tail cannot be combined with offset, limit, vwap, format=parquet or format=arrow",
+			ErrorCode: CodeInvalidParameter,
+		})
+		return
+	}
+
+	envelope, err := parseEnvelope(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
 		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   err.Error(),
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	keep, err := parseTradeFilter(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	tradeIDs, idRange, err := parseTradeIDRange(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	timeout, err := parseTimeout(r, h.Timeout, h.MaxTimeout)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
 		})
 		return
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
 	defer cancel()
 
-	// Download and parse trades using connector
-	result, err := h.Connector.DownloadTrades(ctx, symbol, year, month, day)
+	if validateOnly {
+		h.validate(ctx, w, params)
+		return
+	}
+
+	// Unwanted trades are dropped while parsing, so they never reach memory
+	if keep != nil {
+		ctx = binancevisionconnector.ContextWithTradeFilter(ctx, keep)
+	}
+	if idRange {
+		ctx = binancevisionconnector.ContextWithTradeIDRange(ctx, tradeIDs)
+	}
+
+	logger := Logger(r.Context()).With("symbol", symbol, "date", params.Date())
+	start := time.Now()
+
+	if format.streamed() {
+		h.handleStreamedFile(ctx, w, format, params, view, logger)
+		return
+	}
+
+	// Identical requests are answered with the bytes rendered the first time; a
+	// miss records the response it sends for the next one
+	var rec *responseRecorder
+	if !toFile {
+		key := responseCacheKey(r, symbol, params.Date(), format)
+		if entry, ok := h.Responses.get(key); ok {
+			h.Metrics.RecordSuccess()
+			h.Metrics.RecordDownloadSuccess(time.Since(start), entry.tradeCount)
+			logger.Info("trade download served from response cache", "duration", time.Since(start))
+			writeCachedResponse(w, r, entry)
+			return
+		}
+		if rec = h.Responses.record(w); rec != nil {
+			w = rec
+			defer h.Responses.store(key, rec)
+		}
+	}
+
+	// Download and parse trades using connector; a sample keeps only some of them
+	var result *binancevisionconnector.DownloadResult
+	var sampleTotal int
+	if sampled {
+		result, sampleTotal, err = h.Connector.SampleTrades(ctx, symbol, year, month, day, sample)
+	} else {
+		result, err = h.Connector.DownloadTrades(ctx, symbol, year, month, day)
+	}
 	if err != nil {
-		h.Metrics.FailedRequests++
-		log.Printf("Error downloading and parsing trades: %v", err)
-		WriteJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to download and parse trades: %v", err),
+		h.Metrics.RecordFailure()
+		logger.Error("trade download failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and parse trades: %v", err),
+			ErrorCode: downloadErrorCode(err),
+			SourceURL: h.Connector.TradesURL(symbol, year, month, day),
 		})
 		return
 	}
 
-	h.Metrics.SuccessfulRequests++
+	h.Metrics.RecordDownloadSuccess(time.Since(start), result.TradeCount)
+	logger.Info("trade download succeeded", "duration", time.Since(start), "trade_count", result.TradeCount)
+	if rec != nil {
+		rec.tradeCount = result.TradeCount
+	}
+
+	// Computed before paging, so it always covers the whole day
+	if includeVWAP {
+		result.VWAP = binancevisionconnector.TradesVWAP(result.Trades)
+	}
+
+	var pageInfo *PageInfo
+	if paginated {
+		pageInfo = paginate(result, pagination)
+	} else if sampled {
+		pageInfo = samplePageInfo(result, sample, sampleTotal)
+	}
+
+	if toFile {
+		written, err := writeTradesFile(h.OutputDir, outPath, result.Trades, view)
+		if err != nil {
+			h.Metrics.RecordFailure()
+			logger.Error("writing trades file failed", "path", outPath, "error", err)
+			WriteJSONResponse(w, http.StatusInternalServerError, APIResponse{
+				Success:   false,
+				Error:     fmt.Sprintf("Failed to write trades: %v", err),
+				ErrorCode: CodeInternalError,
+			})
+			return
+		}
+
+		h.Metrics.RecordSuccess()
+		summary := FileOutputSummary{
+			Path:         filepath.Join(h.OutputDir, outPath),
+			TradeCount:   result.TradeCount,
+			BytesWritten: written,
+		}
+		if !envelope {
+			writeBareJSON(w, http.StatusOK, summary, view)
+			return
+		}
+		writeViewJSONResponse(w, http.StatusOK, APIResponse{
+			Success: true,
+			Message: fmt.Sprintf("Wrote %d trades for %s on %s to %s", summary.TradeCount, symbol, result.Date, summary.Path),
+			Data:    summary,
+		}, view)
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+
+	// Preconditions are only evaluated once the archive is known to exist, against
+	// the time Binance published it
+	etag := tradesETag(r, symbol, result.Date, result.TradeCount, format)
+	w.Header().Set("ETag", etag)
+	if !result.PublishedAt.IsZero() {
+		w.Header().Set("Last-Modified", result.PublishedAt.UTC().Format(http.TimeFormat))
+	}
+	if etagMatches(r.Header.Get("If-None-Match"), etag) || notModifiedSince(r, result.PublishedAt) {
+		writeNotModified(w)
+		return
+	}
+
+	if format != formatJSON {
+		writeTradesResponse(w, format, result.Trades, view)
+		return
+	}
+	if !envelope {
+		writeBareTrades(w, TradeList{Trades: result.Trades, View: view})
+		return
+	}
+
+	message := fmt.Sprintf("Successfully downloaded and parsed %d trades for %s on %s", result.TradeCount, symbol, result.Date)
+	switch {
+	case result.EmptyDay:
+		message = fmt.Sprintf("No trades for %s on %s: the archive holds no trade rows", symbol, result.Date)
+	case result.AllRowsSkipped:
+		message = fmt.Sprintf("No trades for %s on %s: all %d rows were skipped, see parse_stats", symbol, result.Date, result.ParseStats.Skipped())
+	}
+	data := &DownloadResponse{DownloadResult: result, PageInfo: pageInfo}
+	data.Trades = TradeList{Trades: result.Trades, View: view}
+
+	writeStreamedTradesResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	}, &data.Trades)
+}
+
+// tradeFileWriter writes trades in a columnar file format as they are parsed
+type tradeFileWriter interface {
+	Write(t *binancevisionconnector.Trade) error
+	Close() error // Writes what remains buffered and ends the file
+}
+
+// handleStreamedFile streams the day's trades as a Parquet file or an Arrow IPC
+// stream. Trades are written from the streaming parser one row group or record batch
+// at a time, so the day is never held in memory, and they come in file order without
+// sorting or deduplication. The status is only sent once the first bytes leave the
+// buffer, so failures before that still get a JSON error; later failures cut the file
+// short, which readers detect by its missing footer or end-of-stream marker. No ETag
+// or Last-Modified is sent, as the trade count and publish time are only known once
+// the file has started.
+func (h *DownloadHandler) handleStreamedFile(ctx context.Context, w http.ResponseWriter, format outputFormat, params downloadParams, view TradeView, logger *slog.Logger) {
+	start := time.Now()
+	out := &deferredHeaderWriter{ResponseWriter: w}
+	extension := "parquet"
+	if format == formatArrow {
+		extension = "arrows"
+	}
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-trades-%s.%s"`, params.Symbol, params.Date(), extension))
+
+	bw := bufio.NewWriterSize(out, 64<<10)
+	var tw tradeFileWriter
+	if format == formatArrow {
+		tw = newArrowWriter(bw, view)
+	} else {
+		tw = newParquetWriter(bw, view)
+	}
+
+	result, err := h.Connector.StreamTrades(ctx, params.Symbol, params.Year, params.Month, params.Day, func(trade binancevisionconnector.Trade) error {
+		return tw.Write(&trade)
+	})
+	if err == nil {
+		if err = tw.Close(); err == nil {
+			err = bw.Flush()
+		}
+	}
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error(string(format)+" download failed", "duration", time.Since(start), "response_started", out.started, "error", err)
+		if !out.started {
+			w.Header().Del("Content-Disposition")
+			WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+				Success:   false,
+				Error:     fmt.Sprintf("Failed to download and parse trades: %v", err),
+				ErrorCode: downloadErrorCode(err),
+				SourceURL: h.Connector.TradesURL(params.Symbol, params.Year, params.Month, params.Day),
+			})
+		}
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	h.Metrics.RecordDownloadSuccess(time.Since(start), result.TradeCount)
+	logger.Info(string(format)+" download succeeded", "duration", time.Since(start), "trade_count", result.TradeCount)
+}
+
+// deferredHeaderWriter sends the 200 status with the first write, so a response can
+// still turn into an error until the first bytes go out
+type deferredHeaderWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (d *deferredHeaderWriter) Write(p []byte) (int, error) {
+	if !d.started {
+		d.started = true
+		d.ResponseWriter.WriteHeader(http.StatusOK)
+	}
+	return d.ResponseWriter.Write(p)
+}
+
+// ValidationResult is returned by /download?validate=true
+type ValidationResult struct {
+	Valid  bool   `json:"valid"`
+	Exists bool   `json:"exists"`
+	Symbol string `json:"symbol"`
+	Date   string `json:"date"`
+}
+
+// parseValidateOnly extracts the validate query parameter
+func parseValidateOnly(r *http.Request) (bool, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("validate"))
+	if raw == "" {
+		return false, nil
+	}
+	validate, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid validate: %s (must be true or false)", raw)
+	}
+	return validate, nil
+}
+
+// validate responds with whether the already validated parameters point at an
+// existing archive, using a HEAD request instead of downloading it
+func (h *DownloadHandler) validate(ctx context.Context, w http.ResponseWriter, params downloadParams) {
+	availability, err := h.Connector.CheckAvailability(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		Logger(ctx).Error("availability check failed", "symbol", params.Symbol, "date", params.Date(), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to check archive availability: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: ValidationResult{
+			Valid:  true,
+			Exists: availability.Exists,
+			Symbol: availability.Symbol,
+			Date:   availability.Date,
+		},
+	})
+}
+
+// beginDayRequest validates the symbol, date and timeout parameters shared by the
+// single-day endpoints. When one is invalid it records the failure, writes the error
+// response and returns false; otherwise ctx carries the request timeout and the
+// caller must call cancel.
+func (h *DownloadHandler) beginDayRequest(w http.ResponseWriter, r *http.Request) (downloadParams, context.Context, context.CancelFunc, bool) {
+	params, err := parseDownloadParams(r, h.MinDate, h.Symbols)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, validationErrorStatus(err), APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return downloadParams{}, nil, nil, false
+	}
+
+	timeout, err := parseTimeout(r, h.Timeout, h.MaxTimeout)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return downloadParams{}, nil, nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return params, ctx, cancel, true
+}
+
+// HandleBookTicker handles book ticker download requests
+func (h *DownloadHandler) HandleBookTicker(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	result, err := h.Connector.DownloadBookTicker(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error("book ticker download failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and parse book ticker: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	logger.Info("book ticker download succeeded", "duration", time.Since(start), "record_count", result.RecordCount)
 
 	WriteJSONResponse(w, http.StatusOK, APIResponse{
 		Success: true,
-		Message: fmt.Sprintf("Successfully downloaded and parsed %d trades for %s on %s", result.TradeCount, symbol, result.Date),
+		Message: fmt.Sprintf("Successfully downloaded and parsed %d book ticker records for %s on %s", result.RecordCount, params.Symbol, result.Date),
 		Data:    result,
 	})
 }
 
-// validateSymbol validates the trading pair symbol
-func validateSymbol(symbol string) error {
+// HandleFundingRate handles funding rate download requests
+func (h *DownloadHandler) HandleFundingRate(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	result, err := h.Connector.DownloadFundingRate(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error("funding rate download failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and parse funding rates: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	logger.Info("funding rate download succeeded", "duration", time.Since(start), "record_count", result.RecordCount)
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Successfully downloaded and parsed %d funding rate records for %s on %s", result.RecordCount, params.Symbol, result.Date),
+		Data:    result,
+	})
+}
+
+// HandleBookDepth handles USDⓈ-M futures book depth download requests
+func (h *DownloadHandler) HandleBookDepth(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	result, err := h.Connector.DownloadBookDepth(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error("book depth download failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and parse book depth: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	logger.Info("book depth download succeeded", "duration", time.Since(start), "record_count", result.RecordCount)
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Successfully downloaded and parsed %d book depth records for %s on %s", result.RecordCount, params.Symbol, result.Date),
+		Data:    result,
+	})
+}
+
+// HandleLiquidations handles USDⓈ-M futures liquidation snapshot download requests
+func (h *DownloadHandler) HandleLiquidations(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	result, err := h.Connector.DownloadLiquidations(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error("liquidation download failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and parse liquidations: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	logger.Info("liquidation download succeeded", "duration", time.Since(start), "record_count", result.RecordCount)
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Successfully downloaded and parsed %d liquidation records for %s on %s", result.RecordCount, params.Symbol, result.Date),
+		Data:    result,
+	})
+}
+
+// HandleMarkPriceKlines handles USDⓈ-M futures mark price kline download requests
+func (h *DownloadHandler) HandleMarkPriceKlines(w http.ResponseWriter, r *http.Request) {
+	h.handlePriceKlines(w, r, "mark price", h.Connector.DownloadMarkPriceKlines)
+}
+
+// HandleIndexPriceKlines handles USDⓈ-M futures index price kline download requests
+func (h *DownloadHandler) HandleIndexPriceKlines(w http.ResponseWriter, r *http.Request) {
+	h.handlePriceKlines(w, r, "index price", h.Connector.DownloadIndexPriceKlines)
+}
+
+// HandlePremiumIndexKlines handles USDⓈ-M futures premium index kline download requests
+func (h *DownloadHandler) HandlePremiumIndexKlines(w http.ResponseWriter, r *http.Request) {
+	h.handlePriceKlines(w, r, "premium index", h.Connector.DownloadPremiumIndexKlines)
+}
+
+// handlePriceKlines validates the symbol, date and INTERVAL parameters and serves the
+// klines returned by download. name describes the dataset in messages.
+func (h *DownloadHandler) handlePriceKlines(w http.ResponseWriter, r *http.Request, name string,
+	download func(ctx context.Context, symbol, interval, year, month, day string) (*binancevisionconnector.PriceKlineResult, error)) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	interval, err := parseKlineInterval(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date(), "interval", interval)
+	start := time.Now()
+
+	result, err := download(ctx, params.Symbol, interval, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error(name+" kline download failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and parse %s klines: %v", name, err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	logger.Info(name+" kline download succeeded", "duration", time.Since(start), "record_count", result.RecordCount)
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Successfully downloaded and parsed %d %s klines for %s on %s", result.RecordCount, name, params.Symbol, result.Date),
+		Data:    result,
+	})
+}
+
+// parseKlineInterval extracts and validates the INTERVAL query parameter
+func parseKlineInterval(r *http.Request) (string, error) {
+	interval := strings.TrimSpace(r.URL.Query().Get("INTERVAL"))
+	if interval == "" {
+		return "", codedErrorf(CodeMissingParameter, "Missing required parameter: INTERVAL")
+	}
+	if !binancevisionconnector.ValidKlineInterval(interval) {
+		return "", fmt.Errorf("invalid interval: %s (valid intervals: %s)", interval, strings.Join(binancevisionconnector.KlineIntervals, ", "))
+	}
+	return interval, nil
+}
+
+// HandleCount counts the trades in a daily archive without returning them
+func (h *DownloadHandler) HandleCount(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	result, err := h.Connector.CountTrades(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error("trade count failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and count trades: %v", err),
+			ErrorCode: downloadErrorCode(err),
+			SourceURL: h.Connector.TradesURL(params.Symbol, params.Year, params.Month, params.Day),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	logger.Info("trade count succeeded", "duration", time.Since(start), "trade_count", result.TradeCount)
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Counted %d trades for %s on %s", result.TradeCount, params.Symbol, result.Date),
+		Data:    result,
+	})
+}
+
+// HandleExists checks whether a trade archive exists without downloading it
+func (h *DownloadHandler) HandleExists(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	availability, err := h.Connector.CheckAvailability(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		Logger(r.Context()).Error("availability check failed",
+			"symbol", params.Symbol, "date", params.Date(), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to check archive availability: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    availability,
+	})
+}
+
+// AvailableDates is returned by /dates
+type AvailableDates struct {
+	Symbol string   `json:"symbol"`
+	Count  int      `json:"count"`
+	Dates  []string `json:"dates"`
+}
+
+// HandleDates lists the dates for which a symbol's trade archive has been published
+func (h *DownloadHandler) HandleDates(w http.ResponseWriter, r *http.Request) {
+	symbolRaw := strings.TrimSpace(r.URL.Query().Get("SYMBOL"))
+	if symbolRaw == "" {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     "Missing required parameter: SYMBOL",
+			ErrorCode: CodeMissingParameter,
+		})
+		return
+	}
+	if err := validateSymbol(symbolRaw, h.Symbols); err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, validationErrorStatus(err), APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+	symbol := strings.ToUpper(symbolRaw)
+
+	timeout, err := parseTimeout(r, h.Timeout, h.MaxTimeout)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	dates, err := h.Connector.ListAvailableDates(ctx, symbol)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		Logger(r.Context()).Error("listing available dates failed", "symbol", symbol, "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to list available dates: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+
+	if dates == nil {
+		dates = []string{}
+	}
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Found %d available dates for %s", len(dates), symbol),
+		Data: AvailableDates{
+			Symbol: symbol,
+			Count:  len(dates),
+			Dates:  dates,
+		},
+	})
+}
+
+// SymbolList is returned by /symbols
+type SymbolList struct {
+	Count     int                                 `json:"count"`
+	UpdatedAt time.Time                           `json:"updated_at"` // When the symbol index was last refreshed
+	Symbols   []binancevisionconnector.SymbolInfo `json:"symbols"`
+}
+
+// HandleSymbols lists the symbols in the connector's symbol index with the dates of
+// their first and latest trade archive. The optional prefix parameter filters
+// symbols case-insensitively, and symbols the access policy refuses are left out.
+func (h *DownloadHandler) HandleSymbols(w http.ResponseWriter, r *http.Request) {
+	indexed, updatedAt := h.Connector.Symbols()
+	if indexed == nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusServiceUnavailable, APIResponse{
+			Success:   false,
+			Error:     "Symbol index is not available; set SYMBOL_REFRESH_INTERVAL and wait for the first refresh",
+			ErrorCode: CodeSymbolIndexUnavailable,
+		})
+		return
+	}
+
+	prefix := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("prefix")))
+	symbols := make([]binancevisionconnector.SymbolInfo, 0, len(indexed))
+	for _, info := range indexed {
+		if strings.HasPrefix(info.Symbol, prefix) && h.Symbols.check(info.Symbol) == nil {
+			symbols = append(symbols, info)
+		}
+	}
+
+	h.Metrics.RecordSuccess()
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Found %d symbols", len(symbols)),
+		Data: SymbolList{
+			Count:     len(symbols),
+			UpdatedAt: updatedAt.UTC(),
+			Symbols:   symbols,
+		},
+	})
+}
+
+// defaultMaintenanceRetryAfter is the Retry-After sent for a maintenance page when
+// Binance Vision did not say how long the maintenance lasts
+const defaultMaintenanceRetryAfter = 5 * time.Minute
+
+// downloadErrorStatus maps a connector error to an HTTP status code, setting
+// any related response headers (such as Retry-After) on w
+func downloadErrorStatus(w http.ResponseWriter, err error) int {
+	var rateLimitErr *binancevisionconnector.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		if rateLimitErr.RetryAfter > 0 {
+			seconds := int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
+		return http.StatusTooManyRequests
+	}
+
+	// The maintenance page would also fail as not a zip file, so it is checked first
+	var maintenanceErr *binancevisionconnector.MaintenanceError
+	if errors.As(err, &maintenanceErr) {
+		retryAfter := maintenanceErr.RetryAfter
+		if retryAfter <= 0 {
+			retryAfter = defaultMaintenanceRetryAfter
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		return http.StatusServiceUnavailable
+	}
+
+	if errors.Is(err, binancevisionconnector.ErrArchiveNotFound) {
+		return http.StatusNotFound
+	}
+
+	// The archive exists but does not hold the requested kind of data
+	if errors.Is(err, binancevisionconnector.ErrNoCSVFiles) {
+		return http.StatusBadRequest
+	}
+
+	if errors.Is(err, binancevisionconnector.ErrResponseTooLarge) ||
+		errors.Is(err, binancevisionconnector.ErrUncompressedTooLarge) ||
+		errors.Is(err, binancevisionconnector.ErrNotAZipFile) {
+		return http.StatusBadGateway
+	}
+
+	return http.StatusInternalServerError
+}
+
+// downloadParams holds the validated symbol and date query parameters
+type downloadParams struct {
+	Symbol string
+	Year   string
+	Month  string
+	Day    string
+}
+
+// Date returns the zero-padded YYYY-MM-DD date
+func (p downloadParams) Date() string {
+	year, month, day := formatDate(p.Year, p.Month, p.Day)
+	return fmt.Sprintf("%s-%s-%s", year, month, day)
+}
+
+// parseDownloadParams extracts and validates the SYMBOL, YYYY, MM and DD query parameters.
+// Dates before minDate are rejected unless minDate is zero.
+func parseDownloadParams(r *http.Request, minDate time.Time, access SymbolAccess) (downloadParams, error) {
+	params, symbolRaw, err := parseDateParams(r, minDate)
+	if err != nil {
+		return downloadParams{}, err
+	}
+
+	if err := validateSymbol(symbolRaw, access); err != nil {
+		return downloadParams{}, err
+	}
+
+	params.Symbol = strings.ToUpper(symbolRaw)
+	return params, nil
+}
+
+// parseDateParams checks that SYMBOL and a date, given either as DATE or as YYYY, MM
+// and DD, are present and validates the date. DATE wins when both forms are given.
+// It returns params without a Symbol, along with the raw, unvalidated SYMBOL value.
+func parseDateParams(r *http.Request, minDate time.Time) (downloadParams, string, error) {
+	symbolRaw := strings.TrimSpace(r.URL.Query().Get("SYMBOL"))
+	year := strings.TrimSpace(r.URL.Query().Get("YYYY"))
+	month := strings.TrimSpace(r.URL.Query().Get("MM"))
+	day := strings.TrimSpace(r.URL.Query().Get("DD"))
+
+	if date := strings.TrimSpace(r.URL.Query().Get("DATE")); date != "" {
+		var err error
+		if year, month, day, err = splitDateParam(date); err != nil {
+			return downloadParams{}, "", err
+		}
+	}
+
+	if symbolRaw == "" || year == "" || month == "" || day == "" {
+		return downloadParams{}, "", codedErrorf(CodeMissingParameter, "Missing required parameters: SYMBOL, YYYY, MM, DD (or DATE)")
+	}
+
+	if err := validateDate(year, month, day); err != nil {
+		return downloadParams{}, "", err
+	}
+
+	if err := validateMinDate(year, month, day, minDate); err != nil {
+		return downloadParams{}, "", err
+	}
+
+	return downloadParams{
+		Year:  year,
+		Month: month,
+		Day:   day,
+	}, symbolRaw, nil
+}
+
+// splitDateParam splits a YYYY-MM-DD DATE value into the year, month and day strings
+// validateDate expects
+func splitDateParam(date string) (year, month, day string, err error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", "", "", codedErrorf(CodeInvalidDate, "invalid DATE: %s (must be YYYY-MM-DD)", date)
+	}
+	return date[0:4], date[5:7], date[8:10], nil
+}
+
+// validateSymbol validates the trading pair symbol and checks that access allows it
+func validateSymbol(symbol string, access SymbolAccess) error {
 	if symbol == "" {
-		return fmt.Errorf("symbol cannot be empty")
+		return codedErrorf(CodeMissingParameter, "symbol cannot be empty")
 	}
-	// Basic validation: should be alphanumeric, typically uppercase
-	matched, _ := regexp.MatchString("^[A-Z0-9]+$", symbol)
-	if !matched {
-		return fmt.Errorf("invalid symbol format: %s (should be uppercase alphanumeric)", symbol)
+	// Symbols are case-insensitive, so validate the canonical uppercase form callers will use
+	if access.contracts {
+		matched, _ := regexp.MatchString("^[A-Z0-9]+(_(PERP|[0-9]{6}))?$", strings.ToUpper(symbol))
+		if !matched {
+			return codedErrorf(CodeInvalidSymbol, "invalid symbol format: %s (should be alphanumeric, or a COIN-M contract such as BTCUSD_PERP or BTCUSD_240628)", symbol)
+		}
+	} else if matched, _ := regexp.MatchString("^[A-Z0-9]+$", strings.ToUpper(symbol)); !matched {
+		return codedErrorf(CodeInvalidSymbol, "invalid symbol format: %s (should be alphanumeric)", symbol)
 	}
-	return nil
+	if err := access.checkQuoteAsset(symbol); err != nil {
+		return err
+	}
+	return access.check(symbol)
 }
 
 // validateDate validates year, month, and day parameters
 func validateDate(year, month, day string) error {
 	y, err := strconv.Atoi(year)
 	if err != nil || y < 2000 || y > 2100 {
-		return fmt.Errorf("invalid year: %s", year)
+		return codedErrorf(CodeInvalidDate, "invalid year: %s", year)
 	}
 
 	m, err := strconv.Atoi(month)
 	if err != nil || m < 1 || m > 12 {
-		return fmt.Errorf("invalid month: %s (must be 01-12)", month)
+		return codedErrorf(CodeInvalidDate, "invalid month: %s (must be 01-12)", month)
 	}
 
 	d, err := strconv.Atoi(day)
 	if err != nil || d < 1 || d > 31 {
-		return fmt.Errorf("invalid day: %s (must be 01-31)", day)
+		return codedErrorf(CodeInvalidDate, "invalid day: %s (must be 01-31)", day)
 	}
 
 	// Format date with zero-padding for parsing
@@ -154,14 +1090,46 @@ func validateDate(year, month, day string) error {
 
 	// Validate actual date (e.g., Feb 30 doesn't exist)
 	dateStr := fmt.Sprintf("%s-%s-%s", year, month, day)
-	_, err = time.Parse("2006-01-02", dateStr)
+	date, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
-		return fmt.Errorf("invalid date: %s", dateStr)
+		return codedErrorf(CodeInvalidDate, "invalid date: %s", dateStr)
+	}
+
+	// Binance publishes daily archives with a lag, so today and later never exist yet
+	if latest := latestAvailableDate(time.Now()); date.After(latest) {
+		return codedErrorf(CodeInvalidDate, "date %s is not available yet (latest available date is %s)", dateStr, latest.Format("2006-01-02"))
 	}
 
 	return nil
 }
 
+// validateMinDate rejects dates before minDate (a zero minDate disables the check).
+// The date components must already be valid.
+func validateMinDate(year, month, day string, minDate time.Time) error {
+	if minDate.IsZero() {
+		return nil
+	}
+
+	year, month, day = formatDate(year, month, day)
+	dateStr := fmt.Sprintf("%s-%s-%s", year, month, day)
+	date, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return codedErrorf(CodeInvalidDate, "invalid date: %s", dateStr)
+	}
+
+	if date.Before(minDate) {
+		return codedErrorf(CodeInvalidDate, "date %s is before the earliest available date %s", dateStr, minDate.Format("2006-01-02"))
+	}
+
+	return nil
+}
+
+// latestAvailableDate returns yesterday's date in UTC
+func latestAvailableDate(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+}
+
 // formatDate ensures date components are zero-padded
 func formatDate(year, month, day string) (string, string, string) {
 	// Ensure zero-padding
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// parseEnvelope parses the envelope query parameter, which defaults to true. With
+// envelope=false a successful response is its data alone, without the success,
+// message and data wrapper; errors keep the wrapper so they can be told apart.
+func parseEnvelope(r *http.Request) (bool, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("envelope"))
+	if raw == "" {
+		return true, nil
+	}
+	envelope, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid envelope: %s (must be true or false)", raw)
+	}
+	return envelope, nil
+}
+
+// writeBareTrades writes trades as a bare JSON array, a chunk at a time. A day
+// without trades is an empty array rather than null.
+func writeBareTrades(w http.ResponseWriter, trades TradeList) {
+	if trades.Trades == nil {
+		trades.Trades = []binancevisionconnector.Trade{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := trades.writeJSON(w); err != nil {
+		return
+	}
+	w.Write([]byte("\n"))
+}
+
+// writeBareJSON writes v as the whole JSON response with the key naming selected by view
+func writeBareJSON(w http.ResponseWriter, statusCode int, v interface{}, view TradeView) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+		WriteJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success:   false,
+			Error:     "Failed to encode response",
+			ErrorCode: CodeInternalError,
+		})
+		return
+	}
+	if view.CamelCase {
+		data = camelCaseJSONKeys(data, jsonFieldNames(v))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(append(data, '\n'))
+}
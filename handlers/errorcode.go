@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// Error codes reported in APIResponse.ErrorCode. Unlike the error messages they are
+// a stable contract clients can branch on.
+const (
+	CodeMissingParameter    = "MISSING_PARAMETER"    // A required query parameter or field is absent
+	CodeInvalidSymbol       = "INVALID_SYMBOL"       // SYMBOL is not alphanumeric
+	CodeInvalidDate         = "INVALID_DATE"         // The date is malformed, in the future or before MIN_DATE
+	CodeSymbolNotAllowed    = "SYMBOL_NOT_ALLOWED"   // ALLOWED_SYMBOLS or DENIED_SYMBOLS refuses the symbol
+	CodeUnauthorized        = "UNAUTHORIZED"         // API_KEYS is set and the request has no valid key
+	CodeInvalidParameter    = "INVALID_PARAMETER"    // Any other malformed query parameter
+	CodeInvalidRequestBody  = "INVALID_REQUEST_BODY" // The POST body is not valid JSON or has the wrong size
+	CodeRequestTooLarge     = "REQUEST_TOO_LARGE"    // The POST body exceeds MAX_BODY_SIZE
+	CodeMethodNotAllowed    = "METHOD_NOT_ALLOWED"
+	CodeNotFound            = "NOT_FOUND"            // The requested prefetch job does not exist
+	CodeCacheDisabled       = "CACHE_DISABLED"       // Prefetch needs CACHE_SIZE to be set
+	CodeArchiveNotFound     = "ARCHIVE_NOT_FOUND"    // Binance Vision has no archive for the symbol and date
+	CodeRateLimited         = "RATE_LIMITED"         // Binance Vision answered 429
+	CodeClientRateLimited   = "CLIENT_RATE_LIMITED"  // The client exceeded CLIENT_RATE_LIMIT_RPM
+	CodeServerOverloaded    = "SERVER_OVERLOADED"    // MAX_IN_FLIGHT_REQUESTS downloads are already in flight
+	CodeArchiveTooLarge     = "ARCHIVE_TOO_LARGE"    // MAX_RESPONSE_SIZE or MAX_UNCOMPRESSED_SIZE was exceeded
+	CodeNoCSVFiles          = "NO_CSV_FILES"         // The archive holds no CSV files
+	CodeTimeout             = "TIMEOUT"              // The request timeout expired
+	CodeUpstreamError       = "UPSTREAM_ERROR"       // Any other failure fetching or parsing the archive
+	CodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE" // /ready could not reach Binance Vision
+	CodeUpstreamMaintenance = "UPSTREAM_MAINTENANCE" // Binance Vision served a maintenance page
+	CodeInternalError       = "INTERNAL_ERROR"       // A failure on this server, such as writing an output file
+
+	CodeSymbolIndexUnavailable = "SYMBOL_INDEX_UNAVAILABLE" // /symbols was called before the symbol index was built
+)
+
+// codedError attaches an error code to a validation error without changing its message
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// withCode returns err tagged with code
+func withCode(code string, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// codedErrorf formats an error tagged with code
+func codedErrorf(code, format string, args ...interface{}) error {
+	return withCode(code, fmt.Errorf(format, args...))
+}
+
+// validationErrorCode returns the code of a request validation error, or
+// CodeInvalidParameter if it has none
+func validationErrorCode(err error) string {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return CodeInvalidParameter
+}
+
+// downloadErrorCode returns the code of a connector error, mirroring downloadErrorStatus
+func downloadErrorCode(err error) string {
+	switch {
+	case errors.Is(err, binancevisionconnector.ErrUpstreamMaintenance):
+		return CodeUpstreamMaintenance
+	case errors.Is(err, binancevisionconnector.ErrRateLimited):
+		return CodeRateLimited
+	case errors.Is(err, binancevisionconnector.ErrArchiveNotFound):
+		return CodeArchiveNotFound
+	case errors.Is(err, binancevisionconnector.ErrResponseTooLarge),
+		errors.Is(err, binancevisionconnector.ErrUncompressedTooLarge):
+		return CodeArchiveTooLarge
+	case errors.Is(err, binancevisionconnector.ErrNoCSVFiles):
+		return CodeNoCSVFiles
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	}
+	return CodeUpstreamError
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestValidationErrorCode(t *testing.T) {
+	access := NewSymbolAccess(nil, []string{"LUNAUSDT"})
+	minDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode string
+	}{
+		{"missing parameter", "SYMBOL=BTCUSDT&YYYY=2025&MM=12", CodeMissingParameter},
+		{"invalid symbol", "SYMBOL=BTC-USDT&YYYY=2025&MM=12&DD=28", CodeInvalidSymbol},
+		{"invalid month", "SYMBOL=BTCUSDT&YYYY=2025&MM=13&DD=28", CodeInvalidDate},
+		{"before min date", "SYMBOL=BTCUSDT&YYYY=2019&MM=12&DD=28", CodeInvalidDate},
+		{"denied symbol", "SYMBOL=LUNAUSDT&YYYY=2025&MM=12&DD=28", CodeSymbolNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDownloadParams(httptest.NewRequest("GET", "/download?"+tt.query, nil), minDate, access)
+			if err == nil {
+				t.Fatal("parseDownloadParams() error = nil, want an error")
+			}
+			if got := validationErrorCode(err); got != tt.wantCode {
+				t.Errorf("validationErrorCode(%v) = %s, want %s", err, got, tt.wantCode)
+			}
+		})
+	}
+
+	if got := validationErrorCode(errors.New("invalid page size")); got != CodeInvalidParameter {
+		t.Errorf("validationErrorCode() of an untagged error = %s, want %s", got, CodeInvalidParameter)
+	}
+}
+
+func TestDownloadErrorCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   string
+		wantStatus int
+	}{
+		{"not found", fmt.Errorf("failed to download file: %w", binancevisionconnector.ErrArchiveNotFound), CodeArchiveNotFound, http.StatusNotFound},
+		{"rate limited", &binancevisionconnector.RateLimitError{}, CodeRateLimited, http.StatusTooManyRequests},
+		{"too large", binancevisionconnector.ErrResponseTooLarge, CodeArchiveTooLarge, http.StatusBadGateway},
+		{"not a zip", binancevisionconnector.ErrNotAZipFile, CodeUpstreamError, http.StatusBadGateway},
+		{"maintenance", fmt.Errorf("failed to parse zip file: %w", &binancevisionconnector.MaintenanceError{Detail: "<html>"}), CodeUpstreamMaintenance, http.StatusServiceUnavailable},
+		{"no csv files", fmt.Errorf("failed to parse zip file: %w", binancevisionconnector.ErrNoCSVFiles), CodeNoCSVFiles, http.StatusBadRequest},
+		{"timeout", fmt.Errorf("failed to download file: %w", context.DeadlineExceeded), CodeTimeout, http.StatusInternalServerError},
+		{"other", errors.New("failed to download file: status code 503"), CodeUpstreamError, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downloadErrorCode(tt.err); got != tt.wantCode {
+				t.Errorf("downloadErrorCode() = %s, want %s", got, tt.wantCode)
+			}
+			if got := downloadErrorStatus(httptest.NewRecorder(), tt.err); got != tt.wantStatus {
+				t.Errorf("downloadErrorStatus() = %d, want %d", got, tt.wantStatus)
+			}
+		})
+	}
+}
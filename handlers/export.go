@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultMaxExportDays is the longest range /export accepts when MaxExportDays is not set
+const defaultMaxExportDays = 366
+
+// ExportError describes a day /export could not include
+type ExportError struct {
+	Date      string `json:"date"`
+	Error     string `json:"error"`
+	ErrorCode string `json:"error_code"`
+}
+
+// exportRange holds the validated parameters of an /export request
+type exportRange struct {
+	Symbol   string
+	From, To time.Time
+}
+
+// parseExportRange extracts and validates the SYMBOL, FROM and TO query parameters.
+// Both dates are YYYY-MM-DD and validated like the date of /download.
+func parseExportRange(r *http.Request, minDate time.Time, access SymbolAccess, maxDays int) (exportRange, error) {
+	symbolRaw := strings.TrimSpace(r.URL.Query().Get("SYMBOL"))
+	fromRaw := strings.TrimSpace(r.URL.Query().Get("FROM"))
+	toRaw := strings.TrimSpace(r.URL.Query().Get("TO"))
+	if symbolRaw == "" || fromRaw == "" || toRaw == "" {
+		return exportRange{}, codedErrorf(CodeMissingParameter, "Missing required parameters: SYMBOL, FROM, TO")
+	}
+
+	var dates [2]time.Time
+	for i, raw := range []string{fromRaw, toRaw} {
+		year, month, day, err := splitDateParam(raw)
+		if err != nil {
+			return exportRange{}, err
+		}
+		if err := validateDate(year, month, day); err != nil {
+			return exportRange{}, err
+		}
+		if err := validateMinDate(year, month, day, minDate); err != nil {
+			return exportRange{}, err
+		}
+		dates[i], _ = time.Parse("2006-01-02", raw)
+	}
+	from, to := dates[0], dates[1]
+	if to.Before(from) {
+		return exportRange{}, codedErrorf(CodeInvalidDate, "invalid range: FROM %s is after TO %s", fromRaw, toRaw)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxDays {
+		return exportRange{}, fmt.Errorf("range of %d days exceeds maximum of %d", days, maxDays)
+	}
+
+	if err := validateSymbol(symbolRaw, access); err != nil {
+		return exportRange{}, err
+	}
+	return exportRange{Symbol: strings.ToUpper(symbolRaw), From: from, To: to}, nil
+}
+
+// HandleExport downloads every day of a range and streams them back as a .tar.gz
+// with one SYMBOL-YYYY-MM-DD.json file per day, each holding the day's result as
+// /download returns it in data. Days are downloaded one after another and written
+// as soon as they are encoded, so memory holds a single day. Days that fail are
+// listed in a final _errors.json entry instead of aborting the export.
+func (h *DownloadHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	maxDays := h.MaxExportDays
+	if maxDays <= 0 {
+		maxDays = defaultMaxExportDays
+	}
+	params, err := parseExportRange(r, h.MinDate, h.Symbols, maxDays)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, validationErrorStatus(err), APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	timeout, err := parseTimeout(r, h.Timeout, h.MaxTimeout)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	from, to := params.From.Format("2006-01-02"), params.To.Format("2006-01-02")
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "from", from, "to", to)
+	start := time.Now()
+
+	// An export runs for as long as its days take, so the server's write timeout
+	// would cut it off; each day is bounded by the request timeout instead
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s-%s.tar.gz"`, params.Symbol, from, to))
+	w.WriteHeader(http.StatusOK)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	var failures []ExportError
+	exported := 0
+	for day := params.From; !day.After(params.To); day = day.AddDate(0, 0, 1) {
+		if r.Context().Err() != nil {
+			logger.Info("export cancelled", "duration", time.Since(start), "exported_days", exported)
+			return
+		}
+
+		dayParams := downloadParams{Symbol: params.Symbol, Year: day.Format("2006"), Month: day.Format("01"), Day: day.Format("02")}
+		failure, err := h.exportDay(r.Context(), tw, dayParams, timeout)
+		if err != nil {
+			// Writing to the client failed, so there is no one left to export to
+			logger.Error("export write failed", "date", dayParams.Date(), "error", err)
+			return
+		}
+		if failure != nil {
+			failures = append(failures, *failure)
+			continue
+		}
+		exported++
+	}
+
+	if len(failures) > 0 {
+		doc, _ := json.Marshal(failures)
+		if err := writeTarFile(tw, "_errors.json", time.Now(), int64(len(doc)), func(w io.Writer) error {
+			_, err := w.Write(doc)
+			return err
+		}); err != nil {
+			logger.Error("export write failed", "error", err)
+			return
+		}
+	}
+	if err := tw.Close(); err != nil {
+		logger.Error("export write failed", "error", err)
+		return
+	}
+	if err := gz.Close(); err != nil {
+		logger.Error("export write failed", "error", err)
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	logger.Info("export succeeded", "duration", time.Since(start), "exported_days", exported, "failed_days", len(failures))
+}
+
+// exportDay downloads one day and adds it to the export as SYMBOL-YYYY-MM-DD.json.
+// A failed download is returned as an ExportError; the error is only set when writing
+// the export failed.
+func (h *DownloadHandler) exportDay(ctx context.Context, tw *tar.Writer, params downloadParams, timeout time.Duration) (*ExportError, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := h.Connector.DownloadTrades(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		return &ExportError{
+			Date:      params.Date(),
+			Error:     fmt.Sprintf("Failed to download and parse trades: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		}, nil
+	}
+	h.Metrics.RecordDownloadSuccess(time.Since(start), result.TradeCount)
+
+	// A tar header needs the file size before the content, so the day is encoded
+	// once to measure it and again into the archive
+	size, err := result.WriteTo(io.Discard)
+	if err != nil {
+		return &ExportError{
+			Date:      params.Date(),
+			Error:     fmt.Sprintf("Failed to encode trades: %v", err),
+			ErrorCode: CodeInternalError,
+		}, nil
+	}
+	name := fmt.Sprintf("%s-%s.json", params.Symbol, params.Date())
+	modTime := result.PublishedAt
+	if modTime.IsZero() {
+		modTime = archiveLastModified(params)
+	}
+	return nil, writeTarFile(tw, name, modTime, size, func(w io.Writer) error {
+		_, err := result.WriteTo(w)
+		return err
+	})
+}
+
+// writeTarFile adds a regular file of the given size to tw, with its content written by write
+func writeTarFile(tw *tar.Writer, name string, modTime time.Time, size int64, write func(io.Writer) error) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     size,
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+	return write(tw)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseExportRange(t *testing.T) {
+	minDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		query    string
+		wantDays int
+		wantCode string
+	}{
+		{"single day", "SYMBOL=btcusdt&FROM=2025-01-28&TO=2025-01-28", 1, ""},
+		{"month", "SYMBOL=BTCUSDT&FROM=2025-01-01&TO=2025-01-31", 31, ""},
+		{"missing TO", "SYMBOL=BTCUSDT&FROM=2025-01-01", 0, CodeMissingParameter},
+		{"malformed date", "SYMBOL=BTCUSDT&FROM=2025-1-1&TO=2025-01-31", 0, CodeInvalidDate},
+		{"reversed range", "SYMBOL=BTCUSDT&FROM=2025-01-31&TO=2025-01-01", 0, CodeInvalidDate},
+		{"before min date", "SYMBOL=BTCUSDT&FROM=2019-12-31&TO=2020-01-31", 0, CodeInvalidDate},
+		{"too long", "SYMBOL=BTCUSDT&FROM=2025-01-01&TO=2025-03-01", 0, CodeInvalidParameter},
+		{"invalid symbol", "SYMBOL=BTC-USDT&FROM=2025-01-01&TO=2025-01-31", 0, CodeInvalidSymbol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExportRange(httptest.NewRequest("GET", "/export?"+tt.query, nil), minDate, SymbolAccess{}, 31)
+			if tt.wantCode != "" {
+				if err == nil || validationErrorCode(err) != tt.wantCode {
+					t.Errorf("parseExportRange(%q) error = %v, want code %s", tt.query, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseExportRange(%q) error = %v", tt.query, err)
+			}
+			if days := int(got.To.Sub(got.From).Hours()/24) + 1; days != tt.wantDays || got.Symbol != "BTCUSDT" {
+				t.Errorf("parseExportRange(%q) = %s for %d days, want BTCUSDT for %d days", tt.query, got.Symbol, days, tt.wantDays)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// FileOutputSummary is returned instead of the trades when they are written to a file
+type FileOutputSummary struct {
+	Path         string `json:"path"`
+	TradeCount   int    `json:"trade_count"`
+	BytesWritten int64  `json:"bytes_written"`
+}
+
+// parseOutputPath extracts the out query parameter. The path must be relative and
+// stay inside outputDir; file output is rejected when no output directory is configured.
+// The returned bool reports whether the parameter was present.
+func parseOutputPath(r *http.Request, outputDir string) (string, bool, error) {
+	out := strings.TrimSpace(r.URL.Query().Get("out"))
+	if out == "" {
+		return "", false, nil
+	}
+
+	if outputDir == "" {
+		return "", true, fmt.Errorf("file output is disabled (no output directory configured)")
+	}
+
+	name := filepath.Clean(filepath.FromSlash(out))
+	if !filepath.IsLocal(name) {
+		return "", true, fmt.Errorf("invalid out path: %s (must be a relative path inside the output directory)", out)
+	}
+
+	return name, true, nil
+}
+
+// writeTradesFile writes trades to name inside dir, as CSV when the name ends in
+// .csv and as NDJSON otherwise. The file is opened through an os.Root so symlinks
+// cannot be used to escape dir. It returns the number of bytes written.
+func writeTradesFile(dir, name string, trades []binancevisionconnector.Trade, view TradeView) (int64, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open output directory: %w", err)
+	}
+	defer root.Close()
+
+	if parent := filepath.Dir(name); parent != "." {
+		if err := root.MkdirAll(parent, 0o755); err != nil {
+			return 0, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	f, err := root.Create(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+
+	cw := &countingWriter{w: f}
+	bw := bufio.NewWriter(cw)
+
+	if strings.EqualFold(filepath.Ext(name), ".csv") {
+		err = writeTradesCSV(bw, trades, view, nil)
+	} else {
+		err = writeTradesNDJSON(bw, trades, view)
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		return cw.n, fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	return cw.n, nil
+}
+
+// writeTradesNDJSON writes one JSON object per trade per line
+func writeTradesNDJSON(w io.Writer, trades []binancevisionconnector.Trade, view TradeView) error {
+	fields := view.fieldsOrAll()
+
+	var buf []byte
+	for i := range trades {
+		buf = view.appendTradeJSON(buf[:0], &trades[i], fields)
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTradesCSV writes a header row followed by one row per trade. When flush is not
+// nil it is called after every csvFlushRows rows.
+func writeTradesCSV(w io.Writer, trades []binancevisionconnector.Trade, view TradeView, flush func() error) error {
+	fields := view.fieldsOrAll()
+
+	buf := []byte(strings.Join(fields, ","))
+	buf = append(buf, '\n')
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+
+	for i := range trades {
+		buf = buf[:0]
+		for j, field := range fields {
+			if j > 0 {
+				buf = append(buf, ',')
+			}
+			buf = view.encoder(field)(buf, &trades[i])
+		}
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		if flush != nil && (i+1)%csvFlushRows == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
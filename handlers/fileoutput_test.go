@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestParseOutputPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		outputDir   string
+		want        string
+		wantPresent bool
+		wantErr     bool
+	}{
+		{"no param", "", "/data", "", false, false},
+		{"simple file", "out=trades.ndjson", "/data", "trades.ndjson", true, false},
+		{"nested file", "out=btc/2025-01-01.csv", "/data", filepath.Join("btc", "2025-01-01.csv"), true, false},
+		{"cleaned inside dir", "out=btc/../trades.csv", "/data", "trades.csv", true, false},
+		{"parent traversal", "out=../etc/passwd", "/data", "", true, true},
+		{"nested traversal", "out=btc/../../etc/passwd", "/data", "", true, true},
+		{"absolute path", "out=/etc/passwd", "/data", "", true, true},
+		{"output disabled", "out=trades.csv", "", "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/download?"+tt.query, nil)
+			got, present, err := parseOutputPath(req, tt.outputDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutputPath(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if present != tt.wantPresent {
+				t.Errorf("parseOutputPath(%q) present = %v, want %v", tt.query, present, tt.wantPresent)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseOutputPath(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTradesFile(t *testing.T) {
+	trades := []binancevisionconnector.Trade{
+		{TradeID: 1, Price: 0.5, Quantity: 10, QuoteQuantity: 5, Timestamp: 1735430400000, IsBuyerMaker: true, IsBestMatch: true},
+		{TradeID: 2, Price: 0.6, Quantity: 20, QuoteQuantity: 12, Timestamp: 1735430401000},
+	}
+
+	tests := []struct {
+		name string
+		file string
+		view TradeView
+		want string
+	}{
+		{
+			name: "ndjson",
+			file: "trades.ndjson",
+			view: TradeView{Fields: []string{"trade_id", "price"}},
+			want: "{\"trade_id\":1,\"price\":0.5}\n{\"trade_id\":2,\"price\":0.6}\n",
+		},
+		{
+			name: "csv in subdirectory",
+			file: filepath.Join("btc", "trades.csv"),
+			want: "trade_id,price,quantity,quote_quantity,timestamp,is_buyer_maker,is_best_match\n" +
+				"1,0.5,10,5,1735430400000,true,true\n" +
+				"2,0.6,20,12,1735430401000,false,false\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			written, err := writeTradesFile(dir, tt.file, trades, tt.view)
+			if err != nil {
+				t.Fatalf("writeTradesFile() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatalf("Failed to read output file: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("file contents = %q, want %q", data, tt.want)
+			}
+			if written != int64(len(data)) {
+				t.Errorf("bytes written = %d, want %d", written, len(data))
+			}
+		})
+	}
+}
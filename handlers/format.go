@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// outputFormat is a representation /download can return trades in
+type outputFormat string
+
+const (
+	formatJSON     outputFormat = "json"
+	formatNDJSON   outputFormat = "ndjson"
+	formatCSV      outputFormat = "csv"
+	formatProtobuf outputFormat = "protobuf" // Length-delimited Trade messages, see proto/trade.proto
+	formatParquet  outputFormat = "parquet"  // Streamed from the parser, see DownloadHandler.handleStreamedFile
+	formatArrow    outputFormat = "arrow"    // Arrow IPC stream, streamed from the parser like Parquet
+)
+
+// formatContentTypes maps each output format to its media type
+var formatContentTypes = map[outputFormat]string{
+	formatJSON:     "application/json",
+	formatNDJSON:   "application/x-ndjson",
+	formatCSV:      "text/csv",
+	formatProtobuf: "application/x-protobuf",
+	formatParquet:  "application/vnd.apache.parquet",
+	formatArrow:    "application/vnd.apache.arrow.stream",
+}
+
+// streamed reports whether the format is written from the streaming parser rather
+// than from a parsed result
+func (f outputFormat) streamed() bool {
+	return f == formatParquet || f == formatArrow
+}
+
+// parseOutputFormat picks the response format from the format query parameter or,
+// when it is absent, the Accept header. Unknown query values are rejected, while
+// an Accept header with no supported media type falls back to JSON.
+func parseOutputFormat(r *http.Request) (outputFormat, error) {
+	if raw := strings.TrimSpace(r.URL.Query().Get("format")); raw != "" {
+		format := outputFormat(strings.ToLower(raw))
+		if _, ok := formatContentTypes[format]; !ok {
+			return "", fmt.Errorf("invalid format: %s (valid formats: json, ndjson, csv, protobuf, parquet, arrow)", raw)
+		}
+		return format, nil
+	}
+
+	return negotiateFormat(r.Header.Get("Accept")), nil
+}
+
+// negotiateFormat returns the supported format with the highest quality in an
+// Accept header. Ties keep the order the client listed them in.
+func negotiateFormat(accept string) outputFormat {
+	best := formatJSON
+	bestQ := 0.0
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(key, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			best, bestQ = formatJSON, q
+		case "application/x-ndjson":
+			best, bestQ = formatNDJSON, q
+		case "text/csv", "text/*":
+			best, bestQ = formatCSV, q
+		case "application/x-protobuf", "application/protobuf":
+			best, bestQ = formatProtobuf, q
+		case "application/vnd.apache.parquet":
+			best, bestQ = formatParquet, q
+		case "application/vnd.apache.arrow.stream":
+			best, bestQ = formatArrow, q
+		}
+	}
+
+	return best
+}
+
+// csvFlushRows is the number of CSV rows after which a response is flushed to the
+// client, so a huge day arrives progressively rather than once it is fully encoded
+const csvFlushRows = 10000
+
+// writeTradesResponse streams trades as NDJSON, CSV or protobuf with the matching Content-Type
+func writeTradesResponse(w http.ResponseWriter, format outputFormat, trades []binancevisionconnector.Trade, view TradeView) {
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	w.WriteHeader(http.StatusOK)
+
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case formatCSV:
+		rc := http.NewResponseController(w)
+		err = writeTradesCSV(bw, trades, view, func() error {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			// A writer that cannot flush still gets every row; they just leave its
+			// buffers later
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+			return nil
+		})
+	case formatProtobuf:
+		err = writeTradesProtobuf(bw, trades, view)
+	default:
+		err = writeTradesNDJSON(bw, trades, view)
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		slog.Error("failed to write trades response", "format", format, "error", err)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		accept  string
+		want    outputFormat
+		wantErr bool
+	}{
+		{"default", "", "", formatJSON, false},
+		{"accept json", "", "application/json", formatJSON, false},
+		{"accept ndjson", "", "application/x-ndjson", formatNDJSON, false},
+		{"accept csv", "", "text/csv", formatCSV, false},
+		{"accept any", "", "*/*", formatJSON, false},
+		{"unsupported falls back to json", "", "application/xml", formatJSON, false},
+		{"highest quality wins", "", "application/json;q=0.5, text/csv;q=0.9", formatCSV, false},
+		{"first listed wins ties", "", "application/x-ndjson, text/csv", formatNDJSON, false},
+		{"unsupported type skipped", "", "text/html, application/x-ndjson;q=0.8", formatNDJSON, false},
+		{"zero quality excluded", "", "text/csv;q=0", formatJSON, false},
+		{"query param", "format=csv", "", formatCSV, false},
+		{"query param wins over accept", "format=ndjson", "text/csv", formatNDJSON, false},
+		{"query param case insensitive", "format=CSV", "", formatCSV, false},
+		{"accept protobuf", "", "application/x-protobuf", formatProtobuf, false},
+		{"query param protobuf", "format=protobuf", "", formatProtobuf, false},
+		{"accept parquet", "", "application/vnd.apache.parquet", formatParquet, false},
+		{"query param parquet", "format=parquet", "", formatParquet, false},
+		{"accept arrow", "", "application/vnd.apache.arrow.stream", formatArrow, false},
+		{"query param arrow", "format=arrow", "", formatArrow, false},
+		{"invalid query param", "format=xml", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/download?"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			got, err := parseOutputFormat(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutputFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseOutputFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// flushCountingRecorder counts the flushes that reach the client and the bytes sent
+// by the first one
+type flushCountingRecorder struct {
+	*httptest.ResponseRecorder
+	flushes      int
+	firstFlushed int
+}
+
+func (f *flushCountingRecorder) Flush() {
+	if f.flushes == 0 {
+		f.firstFlushed = f.Body.Len()
+	}
+	f.flushes++
+}
+
+// plainResponseWriter hides every optional interface of the recorder, like Flusher
+type plainResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (p *plainResponseWriter) Header() http.Header         { return p.header }
+func (p *plainResponseWriter) Write(b []byte) (int, error) { return p.body.Write(b) }
+func (p *plainResponseWriter) WriteHeader(int)             {}
+
+func TestWriteTradesResponse_CSVFlushes(t *testing.T) {
+	trades := make([]binancevisionconnector.Trade, 2*csvFlushRows+1)
+	for i := range trades {
+		trades[i].TradeID = int64(i)
+	}
+	view := TradeView{Fields: []string{"trade_id"}}
+
+	rec := &flushCountingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	writeTradesResponse(rec, formatCSV, trades, view)
+	if rec.flushes != 2 {
+		t.Errorf("flushed %d times, want 2", rec.flushes)
+	}
+	if rec.firstFlushed == 0 || rec.firstFlushed >= rec.Body.Len() {
+		t.Errorf("first flush sent %d of %d bytes, want the first rows only", rec.firstFlushed, rec.Body.Len())
+	}
+
+	// A writer without Flusher still gets the whole response
+	plain := &plainResponseWriter{header: http.Header{}}
+	writeTradesResponse(plain, formatCSV, trades, view)
+	if !bytes.Equal(plain.body.Bytes(), rec.Body.Bytes()) {
+		t.Errorf("response without Flusher has %d bytes, want %d", plain.body.Len(), rec.Body.Len())
+	}
+}
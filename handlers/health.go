@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
 )
 
 // RequestMetrics tracks request statistics
@@ -13,25 +18,90 @@ type RequestMetrics struct {
 	SuccessfulRequests int64
 	FailedRequests     int64
 	ActiveRequests     int64
+
+	StartTime              time.Time // Process start, used for uptime
+	LastSuccessfulDownload time.Time // Zero until the first successful download
+
+	// TotalBytesDownloaded is the cumulative size of the archives fetched from
+	// upstream. It is updated atomically rather than under Mu.
+	TotalBytesDownloaded atomic.Int64
+
+	downloads downloadWindow // Recent successful downloads, for /stats
+}
+
+// NewRequestMetrics creates request metrics with the start time set to now
+func NewRequestMetrics() *RequestMetrics {
+	return &RequestMetrics{
+		StartTime: time.Now(),
+	}
+}
+
+// RecordDownloadSuccess records the time, duration and trade count of a successful trade download
+func (m *RequestMetrics) RecordDownloadSuccess(duration time.Duration, tradeCount int) {
+	m.Mu.Lock()
+	m.LastSuccessfulDownload = time.Now()
+	m.downloads.add(downloadSample{duration: duration, tradeCount: tradeCount})
+	m.Mu.Unlock()
+}
+
+// RecordBytesDownloaded adds the size of an archive fetched from upstream
+func (m *RequestMetrics) RecordBytesDownloaded(n int64) {
+	m.TotalBytesDownloaded.Add(n)
+}
+
+// RecordSuccess increments the successful request counter
+func (m *RequestMetrics) RecordSuccess() {
+	m.Mu.Lock()
+	m.SuccessfulRequests++
+	m.Mu.Unlock()
+}
+
+// RecordFailure increments the failed request counter
+func (m *RequestMetrics) RecordFailure() {
+	m.Mu.Lock()
+	m.FailedRequests++
+	m.Mu.Unlock()
 }
 
 // HealthHandler handles health check requests
 type HealthHandler struct {
-	Metrics *RequestMetrics
+	Metrics   *RequestMetrics
+	Connector *binancevisionconnector.Connector // Optional; reports the download queue and result cache when set
 }
 
 // Handle handles health check requests
 func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	h.Metrics.Mu.RLock()
 	health := map[string]interface{}{
-		"status":             "healthy",
-		"timestamp":          time.Now().UTC().Format(time.RFC3339),
-		"total_requests":     h.Metrics.TotalRequests,
-		"successful_requests": h.Metrics.SuccessfulRequests,
-		"failed_requests":    h.Metrics.FailedRequests,
-		"active_requests":    h.Metrics.ActiveRequests,
+		"status":                   "healthy",
+		"timestamp":                time.Now().UTC().Format(time.RFC3339),
+		"total_requests":           h.Metrics.TotalRequests,
+		"successful_requests":      h.Metrics.SuccessfulRequests,
+		"failed_requests":          h.Metrics.FailedRequests,
+		"active_requests":          h.Metrics.ActiveRequests,
+		"uptime_seconds":           int64(0),
+		"last_successful_download": nil,
+		"total_bytes_downloaded":   h.Metrics.TotalBytesDownloaded.Load(),
+	}
+	if !h.Metrics.StartTime.IsZero() {
+		health["uptime_seconds"] = int64(time.Since(h.Metrics.StartTime).Seconds())
+	}
+	if !h.Metrics.LastSuccessfulDownload.IsZero() {
+		health["last_successful_download"] = h.Metrics.LastSuccessfulDownload.UTC().Format(time.RFC3339)
 	}
 	h.Metrics.Mu.RUnlock()
+	if h.Connector != nil {
+		active, queued := h.Connector.DownloadQueue()
+		health["active_downloads"] = active
+		health["download_queue_depth"] = queued
+
+		cache := h.Connector.CacheStats()
+		health["cache_hits"] = cache.Hits
+		health["cache_misses"] = cache.Misses
+		health["cache_evictions"] = cache.Evictions
+		health["cache_hit_ratio"] = cache.HitRatio
+		health["cache_size"] = cache.Size
+	}
 
 	WriteJSONResponse(w, http.StatusOK, APIResponse{
 		Success: true,
@@ -39,3 +109,32 @@ func (h *HealthHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ReadyHandler handles readiness probe requests
+type ReadyHandler struct {
+	Connector *binancevisionconnector.Connector
+	Timeout   time.Duration // Timeout for the upstream reachability check
+}
+
+// Handle reports whether the upstream data source is reachable
+func (h *ReadyHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+
+	if err := h.Connector.Ping(ctx); err != nil {
+		Logger(r.Context()).Warn("readiness check failed", "error", err)
+		WriteJSONResponse(w, http.StatusServiceUnavailable, APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Upstream not reachable: %v", err),
+			ErrorCode: CodeUpstreamUnavailable,
+		})
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"status":    "ready",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// inFlightRetryAfterSeconds is the Retry-After sent with a request shed at capacity.
+// Downloads take seconds, so a slot is usually free again by then.
+const inFlightRetryAfterSeconds = 1
+
+// InFlightLimiter caps the number of requests being served at once with a semaphore.
+// A nil limiter allows every request.
+type InFlightLimiter struct {
+	slots chan struct{}
+}
+
+// NewInFlightLimiter creates a limiter allowing max requests at once. It returns nil
+// (unlimited) when max is not positive.
+func NewInFlightLimiter(max int) *InFlightLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &InFlightLimiter{slots: make(chan struct{}, max)}
+}
+
+// LimitInFlight rejects requests with 503 and a Retry-After header while the limiter's
+// requests are all in flight, instead of queueing them until memory runs out. A nil
+// limiter passes every request through.
+func LimitInFlight(next http.HandlerFunc, limiter *InFlightLimiter) http.HandlerFunc {
+	if limiter == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case limiter.slots <- struct{}{}:
+		default:
+			w.Header().Set("Retry-After", strconv.Itoa(inFlightRetryAfterSeconds))
+			WriteJSONResponse(w, http.StatusServiceUnavailable, APIResponse{
+				Success:   false,
+				Error:     fmt.Sprintf("Server is at capacity with %d requests in flight, retry after %d seconds", cap(limiter.slots), inFlightRetryAfterSeconds),
+				ErrorCode: CodeServerOverloaded,
+			})
+			return
+		}
+		defer func() { <-limiter.slots }()
+		next(w, r)
+	}
+}
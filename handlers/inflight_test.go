@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLimitInFlight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := LimitInFlight(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("block") {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}, NewInFlightLimiter(1))
+
+	request := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/download?"+query, nil))
+		return w
+	}
+
+	// The only slot is taken by a request that is still running
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- request("block") }()
+	<-started
+
+	w := request("")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status at capacity = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusNoContent {
+		t.Errorf("blocking request status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	// The slot is released once the request completes
+	if w := request(""); w.Code != http.StatusNoContent {
+		t.Errorf("status after release = %d, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestLimitInFlight_Disabled(t *testing.T) {
+	if limiter := NewInFlightLimiter(0); limiter != nil {
+		t.Fatal("NewInFlightLimiter(0) should return nil")
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// writeStreamedTradesResponse writes response like writeViewJSONResponse, where trades
+// points at the TradeList inside response.Data. The rest of the response is encoded
+// with the trades replaced by a random placeholder string, and the trades are then
+// written in its place in small chunks, so the whole document is never held in memory.
+func writeStreamedTradesResponse(w http.ResponseWriter, statusCode int, response APIResponse, trades *TradeList) {
+	trades.placeholder = "trades:" + NewRequestID()
+	defer func() { trades.placeholder = "" }()
+
+	doc, err := json.Marshal(response)
+	if err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+		WriteJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success:   false,
+			Error:     "Failed to encode response",
+			ErrorCode: CodeInternalError,
+		})
+		return
+	}
+	if trades.View.CamelCase {
+		doc = camelCaseJSONKeys(doc, jsonFieldNames(response))
+	}
+	quoted := strconv.AppendQuote(nil, trades.placeholder)
+	at := bytes.Index(doc, quoted)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if at < 0 {
+		// Not reached while response.Data contains trades
+		w.Write(append(doc, '\n'))
+		return
+	}
+	if _, err := w.Write(doc[:at]); err != nil {
+		return
+	}
+	if err := trades.writeJSON(w); err != nil {
+		return
+	}
+	w.Write(append(doc[at+len(quoted):], '\n'))
+}
+
+// writeJSON writes the trades as MarshalJSON encodes them, a chunk at a time
+func (l TradeList) writeJSON(w io.Writer) error {
+	fields := l.View.fieldsOrAll()
+	return binancevisionconnector.WriteTradesJSON(w, l.Trades, func(dst []byte, t *binancevisionconnector.Trade) ([]byte, error) {
+		return l.View.appendTradeJSON(dst, t, fields), nil
+	})
+}
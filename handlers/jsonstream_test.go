@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestWriteStreamedTradesResponse(t *testing.T) {
+	// Enough trades to be written in several chunks
+	trades := make([]binancevisionconnector.Trade, 3000)
+	for i := range trades {
+		trades[i] = binancevisionconnector.Trade{TradeID: int64(i), Price: 0.001234 * float64(i), Quantity: 1e-7, Timestamp: 1735430400000 + int64(i), IsBuyerMaker: i%3 == 0}
+	}
+
+	tests := []struct {
+		name   string
+		trades []binancevisionconnector.Trade
+		view   TradeView
+	}{
+		{"default view", trades, TradeView{}},
+		{"projected fields", trades, TradeView{Fields: []string{"price", "timestamp"}}},
+		{"rfc3339 camel case", trades[:10], TradeView{RFC3339Time: true, CamelCase: true}},
+		{"no trades", nil, TradeView{}},
+		{"empty trades", []binancevisionconnector.Trade{}, TradeView{CamelCase: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &binancevisionconnector.DownloadResult{Symbol: "AIUSDT", Date: "2025-12-28", TradeCount: len(tt.trades), Trades: tt.trades}
+			response := func(data *DownloadResponse) APIResponse {
+				return APIResponse{Success: true, Message: "ok", Data: data}
+			}
+
+			want := httptest.NewRecorder()
+			wantData := &DownloadResponse{DownloadResult: result, PageInfo: &PageInfo{TotalCount: 5}}
+			wantData.Trades = TradeList{Trades: tt.trades, View: tt.view}
+			writeViewJSONResponse(want, 200, response(wantData), tt.view)
+
+			got := httptest.NewRecorder()
+			gotData := &DownloadResponse{DownloadResult: result, PageInfo: &PageInfo{TotalCount: 5}}
+			gotData.Trades = TradeList{Trades: tt.trades, View: tt.view}
+			writeStreamedTradesResponse(got, 200, response(gotData), &gotData.Trades)
+
+			if got.Body.String() != want.Body.String() {
+				t.Errorf("streamed response differs:\n%.500s\nwant\n%.500s", got.Body.String(), want.Body.String())
+			}
+			if got.Header().Get("Content-Type") != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
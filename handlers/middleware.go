@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// MethodGuard rejects requests whose method is not one of methods with 405 and an
+// Allow header, so handlers only see the methods they serve
+func MethodGuard(next http.HandlerFunc, methods ...string) http.HandlerFunc {
+	allow := strings.Join(methods, ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(methods, r.Method) {
+			w.Header().Set("Allow", allow)
+			WriteJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
+				Success:   false,
+				Error:     "Method not allowed",
+				ErrorCode: CodeMethodNotAllowed,
+			})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// LimitBody rejects request bodies larger than maxBytes with 413. Bodies that declare
+// their length are rejected before reading; others fail once the limit is passed,
+// which handlers report through requestBodyErrorStatus. A maxBytes of 0 or less
+// disables the limit.
+func LimitBody(next http.HandlerFunc, maxBytes int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if maxBytes > 0 {
+			if r.ContentLength > maxBytes {
+				WriteJSONResponse(w, http.StatusRequestEntityTooLarge, APIResponse{
+					Success:   false,
+					Error:     fmt.Sprintf("Request body exceeds maximum of %d bytes", maxBytes),
+					ErrorCode: CodeRequestTooLarge,
+				})
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		}
+		next(w, r)
+	}
+}
+
+// requestBodyErrorStatus maps an error from reading a request body to an HTTP status:
+// 413 when LimitBody cut the body off, 400 otherwise
+func requestBodyErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// requestBodyErrorCode returns the error code matching requestBodyErrorStatus
+func requestBodyErrorCode(err error) string {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return CodeRequestTooLarge
+	}
+	return CodeInvalidRequestBody
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMethodGuard(t *testing.T) {
+	handler := MethodGuard(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, http.MethodGet, http.MethodHead)
+
+	tests := []struct {
+		method     string
+		wantStatus int
+	}{
+		{http.MethodGet, http.StatusNoContent},
+		{http.MethodHead, http.StatusNoContent},
+		{http.MethodPost, http.StatusMethodNotAllowed},
+		{http.MethodDelete, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(tt.method, "/", nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusMethodNotAllowed {
+				if got := w.Header().Get("Allow"); got != "GET, HEAD" {
+					t.Errorf("Allow = %q, want %q", got, "GET, HEAD")
+				}
+				if !strings.Contains(w.Body.String(), "Method not allowed") {
+					t.Errorf("body = %q, want the standard error", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	handler := LimitBody(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(requestBodyErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}, 8)
+
+	tests := []struct {
+		name       string
+		body       string
+		unsized    bool // Hide the length so the limit is only hit while reading
+		wantStatus int
+	}{
+		{"within limit", "12345678", false, http.StatusNoContent},
+		{"declared too large", "123456789", false, http.StatusRequestEntityTooLarge},
+		{"unsized within limit", "1234", true, http.StatusNoContent},
+		{"unsized too large", "123456789", true, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.unsized {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			handler(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMaxDays is the most DATES /download/days accepts when MaxDays is not set.
+// Every day is held in memory until the response is written, so this stays small.
+const defaultMaxDays = 50
+
+// MultiDateResponse is the /download/days payload. Each date appears in exactly one
+// of Results or Errors.
+type MultiDateResponse struct {
+	Symbol  string                       `json:"symbol"`
+	Results map[string]*DownloadResponse `json:"results"`
+	Errors  map[string]string            `json:"errors,omitempty"`
+}
+
+// parseDateList extracts and validates the SYMBOL and comma-separated DATES query
+// parameters. Every date is YYYY-MM-DD and validated like the date of /download.
+// Duplicate dates are dropped while keeping the original order.
+func parseDateList(r *http.Request, minDate time.Time, access SymbolAccess, maxDays int) ([]downloadParams, error) {
+	symbolRaw := strings.TrimSpace(r.URL.Query().Get("SYMBOL"))
+	datesRaw := strings.TrimSpace(r.URL.Query().Get("DATES"))
+	if symbolRaw == "" || datesRaw == "" {
+		return nil, codedErrorf(CodeMissingParameter, "Missing required parameters: SYMBOL, DATES")
+	}
+
+	var days []downloadParams
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(datesRaw, ",") {
+		date := strings.TrimSpace(part)
+		if date == "" || seen[date] {
+			continue
+		}
+		seen[date] = true
+
+		year, month, day, err := splitDateParam(date)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateDate(year, month, day); err != nil {
+			return nil, err
+		}
+		if err := validateMinDate(year, month, day, minDate); err != nil {
+			return nil, err
+		}
+		days = append(days, downloadParams{Year: year, Month: month, Day: day})
+	}
+	if len(days) == 0 {
+		return nil, codedErrorf(CodeMissingParameter, "Missing required parameters: SYMBOL, DATES")
+	}
+	if len(days) > maxDays {
+		return nil, fmt.Errorf("DATES lists %d days, exceeding the maximum of %d", len(days), maxDays)
+	}
+
+	if err := validateSymbol(symbolRaw, access); err != nil {
+		return nil, err
+	}
+	for i := range days {
+		days[i].Symbol = strings.ToUpper(symbolRaw)
+	}
+	return days, nil
+}
+
+// HandleDays downloads the days listed in DATES for one symbol concurrently, for
+// sparse selections that a FROM/TO range would overfetch. Failed downloads are
+// reported per date in the response instead of failing the whole request.
+func (h *DownloadHandler) HandleDays(w http.ResponseWriter, r *http.Request) {
+	maxDays := h.MaxDays
+	if maxDays <= 0 {
+		maxDays = defaultMaxDays
+	}
+	days, err := parseDateList(r, h.MinDate, h.Symbols, maxDays)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, validationErrorStatus(err), APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	timeout, err := parseTimeout(r, h.Timeout, h.MaxTimeout)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	view, err := parseTradeView(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	response := MultiDateResponse{
+		Symbol:  days[0].Symbol,
+		Results: make(map[string]*DownloadResponse),
+		Errors:  make(map[string]string),
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	concurrency := h.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	jobs := make(chan downloadParams)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < min(concurrency, len(days)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for params := range jobs {
+				result, err := h.downloadSymbol(ctx, params)
+
+				mu.Lock()
+				if err != nil {
+					response.Errors[params.Date()] = fmt.Sprintf("Failed to download and parse trades: %v", err)
+				} else {
+					data := &DownloadResponse{DownloadResult: result}
+					data.Trades = TradeList{Trades: result.Trades, View: view}
+					response.Results[params.Date()] = data
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, params := range days {
+		jobs <- params
+	}
+	close(jobs)
+	wg.Wait()
+
+	h.Metrics.RecordSuccess()
+
+	writeViewJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Processed %d days for %s: %d succeeded, %d failed",
+			len(days), response.Symbol, len(response.Results), len(response.Errors)),
+		Data: response,
+	}, view)
+}
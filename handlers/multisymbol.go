@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// MultiSymbolResponse is the /download payload when SYMBOL lists several symbols.
+// Each symbol appears in exactly one of Results or Errors.
+type MultiSymbolResponse struct {
+	Date    string                       `json:"date"`
+	Results map[string]*DownloadResponse `json:"results"`
+	Errors  map[string]string            `json:"errors,omitempty"`
+}
+
+// multiSymbolUnsupportedParams are /download query parameters that only make sense for a single symbol
+var multiSymbolUnsupportedParams = []string{"offset", "limit", "head", "tail", "format", "out", "validate", "vwap", "side", "min_quote", "FROM_ID", "TO_ID"}
+
+// parseSymbolList splits a comma-separated SYMBOL value, dropping empty entries and
+// duplicates (in any letter case) while keeping the original order. Symbols are not
+// validated or uppercased here.
+func parseSymbolList(raw string) []string {
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		symbol := strings.TrimSpace(part)
+		if symbol == "" || seen[strings.ToUpper(symbol)] {
+			continue
+		}
+		seen[strings.ToUpper(symbol)] = true
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// handleMultiSymbol downloads the same day for every symbol in a comma-separated
+// SYMBOL concurrently. Invalid symbols and failed downloads are reported per symbol
+// in the response instead of failing the whole request.
+func (h *DownloadHandler) handleMultiSymbol(w http.ResponseWriter, r *http.Request) {
+	params, symbolRaw, err := parseDateParams(r, h.MinDate)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	for _, name := range multiSymbolUnsupportedParams {
+		if r.URL.Query().Has(name) {
+			h.Metrics.RecordFailure()
+			WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+				Success:   false,
+				Error:     fmt.Sprintf("%s is not supported when requesting multiple symbols", name),
+				ErrorCode: CodeInvalidParameter,
+			})
+			return
+		}
+	}
+
+	timeout, err := parseTimeout(r, h.Timeout, h.MaxTimeout)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	view, err := parseTradeView(r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	response := MultiSymbolResponse{
+		Date:    params.Date(),
+		Results: make(map[string]*DownloadResponse),
+		Errors:  make(map[string]string),
+	}
+
+	var symbols []string
+	for _, symbol := range parseSymbolList(symbolRaw) {
+		if err := validateSymbol(symbol, h.Symbols); err != nil {
+			response.Errors[symbol] = err.Error()
+			continue
+		}
+		symbols = append(symbols, strings.ToUpper(symbol))
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	concurrency := h.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < min(concurrency, len(symbols)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for symbol := range jobs {
+				symbolParams := params
+				symbolParams.Symbol = symbol
+				result, err := h.downloadSymbol(ctx, symbolParams)
+
+				mu.Lock()
+				if err != nil {
+					response.Errors[symbol] = fmt.Sprintf("Failed to download and parse trades: %v", err)
+				} else {
+					data := &DownloadResponse{DownloadResult: result}
+					data.Trades = TradeList{Trades: result.Trades, View: view}
+					response.Results[symbol] = data
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, symbol := range symbols {
+		jobs <- symbol
+	}
+	close(jobs)
+	wg.Wait()
+
+	h.Metrics.RecordSuccess()
+
+	writeViewJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Processed %d symbols for %s: %d succeeded, %d failed",
+			len(response.Results)+len(response.Errors), response.Date, len(response.Results), len(response.Errors)),
+		Data: response,
+	}, view)
+}
+
+// downloadSymbol downloads one symbol and day of a multi-symbol or /download/days request
+func (h *DownloadHandler) downloadSymbol(ctx context.Context, params downloadParams) (*binancevisionconnector.DownloadResult, error) {
+	// Downloads still queued when the request times out fail at once instead of
+	// starting a download that cannot finish
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	logger := Logger(ctx).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	result, err := h.Connector.DownloadTrades(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		logger.Error("trade download failed", "duration", time.Since(start), "error", err)
+		return nil, err
+	}
+
+	h.Metrics.RecordDownloadSuccess(time.Since(start), result.TradeCount)
+	logger.Info("trade download succeeded", "duration", time.Since(start), "trade_count", result.TradeCount)
+	return result, nil
+}
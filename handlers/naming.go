@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// snakeToCamel converts a snake_case name such as quote_quantity to camelCase (quoteQuantity)
+func snakeToCamel(name string) string {
+	out := make([]byte, 0, len(name))
+	upper := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '_' {
+			upper = len(out) > 0
+			continue
+		}
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+// camelCaseJSONKeys rewrites the object keys listed in fields in a valid JSON document
+// from snake_case to camelCase, leaving other keys, string values and key order
+// untouched. It works on the encoded bytes so any response can be re-keyed without a
+// camelCase copy of its types; fields keeps map keys such as BTCUSD_PERP intact.
+func camelCaseJSONKeys(src []byte, fields map[string]bool) []byte {
+	dst := make([]byte, 0, len(src))
+	for i := 0; i < len(src); i++ {
+		if src[i] != '"' {
+			dst = append(dst, src[i])
+			continue
+		}
+
+		// Find the closing quote, skipping escaped characters
+		end := i + 1
+		for end < len(src) && src[end] != '"' {
+			if src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+
+		// A string followed by a colon is an object key
+		next := end + 1
+		for next < len(src) && isJSONSpace(src[next]) {
+			next++
+		}
+		if next < len(src) && src[next] == ':' && fields[string(src[i+1:end])] {
+			dst = append(dst, '"')
+			dst = append(dst, snakeToCamel(string(src[i+1:end]))...)
+			dst = append(dst, '"')
+		} else {
+			dst = append(dst, src[i:min(end+1, len(src))]...)
+		}
+		i = end
+	}
+	return dst
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// jsonFieldNames returns the JSON names of the struct fields that encoding v can
+// produce. Types implementing json.Marshaler are skipped as they pick their own keys.
+func jsonFieldNames(v interface{}) map[string]bool {
+	names := make(map[string]bool)
+	collectJSONFieldNames(reflect.ValueOf(v), names)
+	return names
+}
+
+func collectJSONFieldNames(v reflect.Value, names map[string]bool) {
+	if !v.IsValid() {
+		return
+	}
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collectJSONFieldNames(v.Elem(), names)
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" && !field.Anonymous {
+				name = field.Name
+			}
+			if name != "" {
+				names[name] = true
+			}
+			collectJSONFieldNames(v.Field(i), names)
+		}
+	case reflect.Slice, reflect.Array:
+		// Elements of a static type all produce the same keys, so one is enough
+		n := v.Len()
+		if n > 0 && !hasDynamicJSON(t.Elem(), nil) {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			collectJSONFieldNames(v.Index(i), names)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectJSONFieldNames(iter.Value(), names)
+			if !hasDynamicJSON(t.Elem(), nil) {
+				break
+			}
+		}
+	}
+}
+
+// hasDynamicJSON reports whether values of t can contain interfaces, whose keys
+// depend on the value rather than the type
+func hasDynamicJSON(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return hasDynamicJSON(t.Elem(), seen)
+	case reflect.Struct:
+		if seen == nil {
+			seen = make(map[reflect.Type]bool)
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			if hasDynamicJSON(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isJSONSpace reports whether c is JSON insignificant whitespace
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// writeCamelCaseJSONResponse writes a JSON response like WriteJSONResponse, with all
+// struct field keys converted to camelCase
+func writeCamelCaseJSONResponse(w http.ResponseWriter, statusCode int, response APIResponse) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+		WriteJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success:   false,
+			Error:     "Failed to encode response",
+			ErrorCode: CodeInternalError,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(append(camelCaseJSONKeys(data, jsonFieldNames(response)), '\n'))
+}
+
+// writeViewJSONResponse writes response with the key naming selected by view
+func writeViewJSONResponse(w http.ResponseWriter, statusCode int, response APIResponse, view TradeView) {
+	if view.CamelCase {
+		writeCamelCaseJSONResponse(w, statusCode, response)
+		return
+	}
+	WriteJSONResponse(w, statusCode, response)
+}
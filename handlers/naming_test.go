@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestSnakeToCamel(t *testing.T) {
+	tests := map[string]string{
+		"trade_id":                   "tradeId",
+		"quote_quantity":             "quoteQuantity",
+		"average_trades_per_request": "averageTradesPerRequest",
+		"p50_ms":                     "p50Ms",
+		"symbol":                     "symbol",
+		"_private":                   "private",
+	}
+	for in, want := range tests {
+		if got := snakeToCamel(in); got != want {
+			t.Errorf("snakeToCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCamelCaseJSONKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"nested objects", `{"trade_count":1,"parse_stats":{"skipped_short":0}}`, `{"tradeCount":1,"parseStats":{"skippedShort":0}}`},
+		{"string values untouched", `{"error_message":"bad trade_id: \"x_y\""}`, `{"errorMessage":"bad trade_id: \"x_y\""}`},
+		{"arrays of objects", `[{"is_buyer_maker":true},{"is_best_match":false}]`, `[{"isBuyerMaker":true},{"isBestMatch":false}]`},
+		{"whitespace before colon", "{\"trade_id\" : 1}", "{\"tradeId\" : 1}"},
+		{"array of strings", `["snake_case","x"]`, `["snake_case","x"]`},
+		{"map keys untouched", `{"trade_count":1,"results":{"BTCUSD_PERP":{"trade_count":2}}}`, `{"tradeCount":1,"results":{"BTCUSD_PERP":{"tradeCount":2}}}`},
+	}
+
+	fields := map[string]bool{
+		"trade_count": true, "parse_stats": true, "skipped_short": true, "error_message": true,
+		"is_buyer_maker": true, "is_best_match": true, "trade_id": true, "results": true,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(camelCaseJSONKeys([]byte(tt.in), fields)); got != tt.want {
+				t.Errorf("camelCaseJSONKeys(%s) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONFieldNames(t *testing.T) {
+	response := APIResponse{
+		Success: true,
+		Data: MultiSymbolResponse{
+			Results: map[string]*DownloadResponse{"BTCUSD_PERP": {DownloadResult: &binancevisionconnector.DownloadResult{}}},
+			Errors:  map[string]string{"ETHUSD_PERP": "failed"},
+		},
+	}
+
+	names := jsonFieldNames(response)
+	for _, name := range []string{"success", "data", "results", "errors", "trade_count"} {
+		if !names[name] {
+			t.Errorf("jsonFieldNames() is missing %q", name)
+		}
+	}
+	for _, name := range []string{"BTCUSD_PERP", "ETHUSD_PERP"} {
+		if names[name] {
+			t.Errorf("jsonFieldNames() includes map key %q", name)
+		}
+	}
+}
+
+func TestWriteViewJSONResponse_CamelCaseKeepsSymbols(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeViewJSONResponse(rec, http.StatusOK, APIResponse{
+		Success: true,
+		Data: MultiSymbolResponse{
+			Date:    "2024-01-15",
+			Results: map[string]*DownloadResponse{},
+			Errors:  map[string]string{"BTCUSD_PERP": "failed"},
+		},
+	}, TradeView{CamelCase: true})
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `"errors":{"BTCUSD_PERP":"failed"}`) {
+		t.Errorf("response = %s, want the BTCUSD_PERP key unchanged", body)
+	}
+}
+
+func TestWriteTradesNDJSON_CamelCase(t *testing.T) {
+	trades := []binancevisionconnector.Trade{{TradeID: 1, QuoteQuantity: 2.5}}
+
+	var buf bytes.Buffer
+	view := TradeView{Fields: []string{"trade_id", "quote_quantity"}, CamelCase: true}
+	if err := writeTradesNDJSON(&buf, trades, view); err != nil {
+		t.Fatalf("writeTradesNDJSON() error = %v", err)
+	}
+
+	want := `{"tradeId":1,"quoteQuantity":2.5}` + "\n"
+	if buf.String() != want {
+		t.Errorf("writeTradesNDJSON() = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// Pagination holds the offset and limit query parameters
+type Pagination struct {
+	Offset int
+	Limit  int // 0 = no limit
+}
+
+// PageInfo holds the pagination metadata included in paginated responses
+type PageInfo struct {
+	TotalCount int `json:"total_count"`
+	Offset     int `json:"offset"`
+	Limit      int `json:"limit"`
+}
+
+// parsePagination extracts the offset and limit query parameters.
+// The returned bool reports whether either parameter was present.
+func parsePagination(r *http.Request) (Pagination, bool, error) {
+	var p Pagination
+
+	offsetRaw := strings.TrimSpace(r.URL.Query().Get("offset"))
+	limitRaw := strings.TrimSpace(r.URL.Query().Get("limit"))
+	if offsetRaw == "" && limitRaw == "" {
+		return p, false, nil
+	}
+
+	if offsetRaw != "" {
+		offset, err := strconv.Atoi(offsetRaw)
+		if err != nil || offset < 0 {
+			return p, true, fmt.Errorf("invalid offset: %s (must be a non-negative integer)", offsetRaw)
+		}
+		p.Offset = offset
+	}
+
+	if limitRaw != "" {
+		limit, err := strconv.Atoi(limitRaw)
+		if err != nil || limit < 0 {
+			return p, true, fmt.Errorf("invalid limit: %s (must be a non-negative integer)", limitRaw)
+		}
+		p.Limit = limit
+	}
+
+	return p, true, nil
+}
+
+// paginate slices the result's trades to the requested page in place
+func paginate(result *binancevisionconnector.DownloadResult, p Pagination) *PageInfo {
+	total := len(result.Trades)
+
+	start := p.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	// Compared as a difference, as start+p.Limit can overflow
+	if p.Limit > 0 && p.Limit < end-start {
+		end = start + p.Limit
+	}
+
+	result.Trades = result.Trades[start:end]
+	result.TradeCount = len(result.Trades)
+
+	return &PageInfo{
+		TotalCount: total,
+		Offset:     p.Offset,
+		Limit:      p.Limit,
+	}
+}
+
+// maxTradeSample is the largest head or tail accepted. A sample is meant for a quick
+// look at a day; larger selections are what offset and limit are for.
+const maxTradeSample = 100000
+
+// parseTradeSample extracts the head and tail query parameters, which select the first
+// or last trades of the day. The returned bool reports whether either was present.
+func parseTradeSample(r *http.Request) (binancevisionconnector.TradeSample, bool, error) {
+	var sample binancevisionconnector.TradeSample
+
+	headRaw := strings.TrimSpace(r.URL.Query().Get("head"))
+	tailRaw := strings.TrimSpace(r.URL.Query().Get("tail"))
+	if headRaw == "" && tailRaw == "" {
+		return sample, false, nil
+	}
+	if headRaw != "" && tailRaw != "" {
+		return sample, true, fmt.Errorf("head and tail cannot be combined")
+	}
+
+	if headRaw != "" {
+		head, err := strconv.Atoi(headRaw)
+		if err != nil || head <= 0 || head > maxTradeSample {
+			return sample, true, fmt.Errorf("invalid head: %s (must be a positive integer up to %d)", headRaw, maxTradeSample)
+		}
+		sample.Head = head
+	}
+
+	if tailRaw != "" {
+		tail, err := strconv.Atoi(tailRaw)
+		if err != nil || tail <= 0 || tail > maxTradeSample {
+			return sample, true, fmt.Errorf("invalid tail: %s (must be a positive integer up to %d)", tailRaw, maxTradeSample)
+		}
+		sample.Tail = tail
+	}
+
+	return sample, true, nil
+}
+
+// samplePageInfo describes a sampled result as the page of the day it is, given the
+// number of trades the day has
+func samplePageInfo(result *binancevisionconnector.DownloadResult, sample binancevisionconnector.TradeSample, total int) *PageInfo {
+	if sample.Tail > 0 {
+		return &PageInfo{TotalCount: total, Offset: total - result.TradeCount, Limit: sample.Tail}
+	}
+	return &PageInfo{TotalCount: total, Offset: 0, Limit: sample.Head}
+}
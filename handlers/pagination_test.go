@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		want        Pagination
+		wantPresent bool
+		wantErr     bool
+	}{
+		{"no params", "", Pagination{}, false, false},
+		{"offset only", "offset=10", Pagination{Offset: 10}, true, false},
+		{"limit only", "limit=5", Pagination{Limit: 5}, true, false},
+		{"offset and limit", "offset=10&limit=5", Pagination{Offset: 10, Limit: 5}, true, false},
+		{"negative offset", "offset=-1", Pagination{}, true, true},
+		{"negative limit", "limit=-5", Pagination{}, true, true},
+		{"non-numeric limit", "limit=abc", Pagination{}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/download?"+tt.query, nil)
+			got, present, err := parsePagination(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePagination(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if present != tt.wantPresent {
+				t.Errorf("parsePagination(%q) present = %v, want %v", tt.query, present, tt.wantPresent)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parsePagination(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTradeSample(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		want        binancevisionconnector.TradeSample
+		wantPresent bool
+		wantErr     bool
+	}{
+		{"no params", "", binancevisionconnector.TradeSample{}, false, false},
+		{"head", "head=100", binancevisionconnector.TradeSample{Head: 100}, true, false},
+		{"tail", "tail=100", binancevisionconnector.TradeSample{Tail: 100}, true, false},
+		{"head and tail", "head=1&tail=1", binancevisionconnector.TradeSample{}, true, true},
+		{"zero head", "head=0", binancevisionconnector.TradeSample{}, true, true},
+		{"tail at the limit", "tail=100000", binancevisionconnector.TradeSample{Tail: 100000}, true, false},
+		{"huge tail", "tail=10000000000", binancevisionconnector.TradeSample{}, true, true},
+		{"head over the limit", "head=100001", binancevisionconnector.TradeSample{}, true, true},
+		{"negative tail", "tail=-1", binancevisionconnector.TradeSample{}, true, true},
+		{"non-numeric tail", "tail=abc", binancevisionconnector.TradeSample{}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/download?"+tt.query, nil)
+			got, present, err := parseTradeSample(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTradeSample(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if present != tt.wantPresent {
+				t.Errorf("parseTradeSample(%q) present = %v, want %v", tt.query, present, tt.wantPresent)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseTradeSample(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name      string
+		p         Pagination
+		wantFirst int64
+		wantCount int
+	}{
+		{"first page", Pagination{Offset: 0, Limit: 2}, 1, 2},
+		{"middle page", Pagination{Offset: 2, Limit: 2}, 3, 2},
+		{"last partial page", Pagination{Offset: 4, Limit: 2}, 5, 1},
+		{"offset past end", Pagination{Offset: 10, Limit: 2}, 0, 0},
+		{"no limit", Pagination{Offset: 3}, 4, 2},
+		{"largest limit", Pagination{Offset: 1, Limit: math.MaxInt}, 2, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &binancevisionconnector.DownloadResult{}
+			for id := int64(1); id <= 5; id++ {
+				result.Trades = append(result.Trades, binancevisionconnector.Trade{TradeID: id})
+			}
+			result.TradeCount = len(result.Trades)
+
+			page := paginate(result, tt.p)
+			if page.TotalCount != 5 {
+				t.Errorf("TotalCount = %d, want 5", page.TotalCount)
+			}
+			if result.TradeCount != tt.wantCount || len(result.Trades) != tt.wantCount {
+				t.Fatalf("got %d trades, want %d", len(result.Trades), tt.wantCount)
+			}
+			if tt.wantCount > 0 && result.Trades[0].TradeID != tt.wantFirst {
+				t.Errorf("first trade id = %d, want %d", result.Trades[0].TradeID, tt.wantFirst)
+			}
+		})
+	}
+}
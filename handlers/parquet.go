@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// parquetRowGroupSize is the number of trades per row group. A row group is written
+// as soon as it is full, so only one is held in memory however busy the day was.
+const parquetRowGroupSize = 128 * 1024
+
+// parquetMagic starts and ends every Parquet file
+const parquetMagic = "PAR1"
+
+// Parquet physical types, encodings and other enum values from parquet.thrift
+const (
+	parquetBoolean = 0
+	parquetInt64   = 2
+	parquetDouble  = 5
+
+	parquetRequired        = 0 // FieldRepetitionType
+	parquetPlain           = 0 // Encoding
+	parquetRLE             = 3 // Encoding of the (absent) definition and repetition levels
+	parquetGzip            = 2 // CompressionCodec
+	parquetDataPage        = 0 // PageType
+	parquetTimestampMillis = 9 // ConvertedType
+)
+
+// parquetColumnTypes maps trade fields to their physical type
+var parquetColumnTypes = map[string]int32{
+	"trade_id":       parquetInt64,
+	"price":          parquetDouble,
+	"quantity":       parquetDouble,
+	"quote_quantity": parquetDouble,
+	"timestamp":      parquetInt64, // Annotated as a UTC timestamp in milliseconds
+	"is_buyer_maker": parquetBoolean,
+	"is_best_match":  parquetBoolean,
+}
+
+// parquetColumn buffers the PLAIN encoded values of one column of the current row group
+type parquetColumn struct {
+	field  string
+	name   string // Column name in the schema, following the view's naming
+	typ    int32
+	values []byte
+	chunks []parquetChunk // One per written row group
+}
+
+// parquetChunk describes a column chunk that has been written, for the file footer
+type parquetChunk struct {
+	offset           int64
+	uncompressedSize int64
+	compressedSize   int64
+	numValues        int64
+}
+
+// parquetWriter writes trades as a Parquet file with one required column per field
+// of the view. Values are PLAIN encoded in a single gzip compressed data page per
+// column chunk; this is not the most compact layout, but any reader accepts it.
+type parquetWriter struct {
+	w         io.Writer
+	offset    int64
+	columns   []*parquetColumn
+	rows      int   // Rows in the current row group
+	groupRows []int // Rows of each written row group
+	page      bytes.Buffer
+	gz        *gzip.Writer
+	err       error
+}
+
+// newParquetWriter writes the file header and returns a writer for the view's fields.
+// Timestamps are always epoch milliseconds, annotated as a UTC timestamp.
+func newParquetWriter(w io.Writer, view TradeView) *parquetWriter {
+	pw := &parquetWriter{w: w}
+	for _, field := range view.fieldsOrAll() {
+		name := field
+		if view.CamelCase {
+			name = tradeFieldCamelNames[field]
+		}
+		pw.columns = append(pw.columns, &parquetColumn{field: field, name: name, typ: parquetColumnTypes[field]})
+	}
+	pw.gz, _ = gzip.NewWriterLevel(&pw.page, gzip.BestSpeed)
+	pw.write([]byte(parquetMagic))
+	return pw
+}
+
+// Write adds a trade, writing the row group out once it is full
+func (pw *parquetWriter) Write(t *binancevisionconnector.Trade) error {
+	for _, column := range pw.columns {
+		column.values = appendParquetValue(column.values, column.field, t, pw.rows)
+	}
+	pw.rows++
+	if pw.rows == parquetRowGroupSize {
+		pw.flushRowGroup()
+	}
+	return pw.err
+}
+
+// Close writes the last row group and the file footer. It does not close the
+// underlying writer.
+func (pw *parquetWriter) Close() error {
+	if pw.rows > 0 {
+		pw.flushRowGroup()
+	}
+	footer := pw.appendFileMetaData(nil)
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, parquetMagic...)
+	pw.write(footer)
+	return pw.err
+}
+
+// appendParquetValue appends the PLAIN encoding of a trade field. Booleans are bit
+// packed, so row is needed to find the bit.
+func appendParquetValue(dst []byte, field string, t *binancevisionconnector.Trade, row int) []byte {
+	switch field {
+	case "trade_id":
+		return binary.LittleEndian.AppendUint64(dst, uint64(t.TradeID))
+	case "price":
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(t.Price))
+	case "quantity":
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(t.Quantity))
+	case "quote_quantity":
+		return binary.LittleEndian.AppendUint64(dst, math.Float64bits(t.QuoteQuantity))
+	case "timestamp":
+		return binary.LittleEndian.AppendUint64(dst, uint64(t.Timestamp))
+	case "is_buyer_maker":
+		return appendParquetBool(dst, t.IsBuyerMaker, row)
+	case "is_best_match":
+		return appendParquetBool(dst, t.IsBestMatch, row)
+	}
+	return dst
+}
+
+// appendParquetBool sets bit row%8 of the last byte, starting a new byte every 8 rows
+func appendParquetBool(dst []byte, value bool, row int) []byte {
+	if row%8 == 0 {
+		dst = append(dst, 0)
+	}
+	if value {
+		dst[len(dst)-1] |= 1 << (row % 8)
+	}
+	return dst
+}
+
+// flushRowGroup writes one data page per column and resets the column buffers
+func (pw *parquetWriter) flushRowGroup() {
+	for _, column := range pw.columns {
+		pw.page.Reset()
+		pw.gz.Reset(&pw.page)
+		pw.gz.Write(column.values)
+		pw.gz.Close()
+
+		var header thriftWriter
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(len(column.values)))
+		header.i32(3, int32(pw.page.Len()))
+		header.structBegin(5) // DataPageHeader
+		header.i32(1, int32(pw.rows))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.structEnd()
+		header.stop()
+
+		column.chunks = append(column.chunks, parquetChunk{
+			offset:           pw.offset,
+			uncompressedSize: int64(len(header.buf) + len(column.values)),
+			compressedSize:   int64(len(header.buf) + pw.page.Len()),
+			numValues:        int64(pw.rows),
+		})
+		pw.write(header.buf)
+		pw.write(pw.page.Bytes())
+		column.values = column.values[:0]
+	}
+	pw.groupRows = append(pw.groupRows, pw.rows)
+	pw.rows = 0
+}
+
+// appendFileMetaData appends the thrift encoded FileMetaData footer
+func (pw *parquetWriter) appendFileMetaData(dst []byte) []byte {
+	totalRows := 0
+	for _, rows := range pw.groupRows {
+		totalRows += rows
+	}
+
+	t := thriftWriter{buf: dst}
+	t.i32(1, 1) // version
+
+	t.listBegin(2, thriftStruct, len(pw.columns)+1) // schema: the root, then one leaf per column
+	t.elemBegin()
+	t.binary(4, "trade")
+	t.i32(5, int32(len(pw.columns)))
+	t.elemEnd()
+	for _, column := range pw.columns {
+		t.elemBegin()
+		t.i32(1, column.typ)
+		t.i32(3, parquetRequired)
+		t.binary(4, column.name)
+		if column.field == "timestamp" {
+			t.i32(6, parquetTimestampMillis)
+			t.structBegin(10) // LogicalType
+			t.structBegin(8)  // TimestampType
+			t.bool(1, true)   // isAdjustedToUTC
+			t.structBegin(2)  // TimeUnit
+			t.structBegin(1)  // MILLIS
+			t.structEnd()
+			t.structEnd()
+			t.structEnd()
+			t.structEnd()
+		}
+		t.elemEnd()
+	}
+
+	t.i64(3, int64(totalRows))
+
+	t.listBegin(4, thriftStruct, len(pw.groupRows)) // row_groups
+	for group, rows := range pw.groupRows {
+		t.elemBegin()
+		var groupSize int64
+		t.listBegin(1, thriftStruct, len(pw.columns)) // columns
+		for _, column := range pw.columns {
+			chunk := column.chunks[group]
+			groupSize += chunk.uncompressedSize
+			t.elemBegin()
+			t.i64(2, chunk.offset) // file_offset
+			t.structBegin(3)       // ColumnMetaData
+			t.i32(1, column.typ)
+			t.listBegin(2, thriftI32, 2) // encodings
+			t.listI32(parquetPlain)
+			t.listI32(parquetRLE)
+			t.listBegin(3, thriftBinary, 1) // path_in_schema
+			t.listBinary(column.name)
+			t.i32(4, parquetGzip)
+			t.i64(5, chunk.numValues)
+			t.i64(6, chunk.uncompressedSize)
+			t.i64(7, chunk.compressedSize)
+			t.i64(9, chunk.offset) // data_page_offset
+			t.structEnd()
+			t.elemEnd()
+		}
+		t.i64(2, groupSize)
+		t.i64(3, int64(rows))
+		t.elemEnd()
+	}
+
+	t.binary(6, "binance-vision-connector")
+	t.stop()
+	return t.buf
+}
+
+// write writes p, remembering the first error so callers can check it once
+func (pw *parquetWriter) write(p []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(p)
+	pw.offset += int64(n)
+	pw.err = err
+}
+
+// Thrift compact protocol type codes
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftStruct    = 12
+)
+
+// thriftWriter encodes the subset of the thrift compact protocol used by Parquet
+// metadata. Field IDs are delta encoded, so each struct tracks the last one written.
+type thriftWriter struct {
+	buf    []byte
+	lastID []int16 // Last field ID of each open struct, innermost last
+}
+
+// field writes a field header
+func (t *thriftWriter) field(id int16, typ byte) {
+	if len(t.lastID) == 0 {
+		t.lastID = append(t.lastID, 0)
+	}
+	last := &t.lastID[len(t.lastID)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.buf = binary.AppendVarint(t.buf, int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.buf = binary.AppendVarint(t.buf, int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.buf = binary.AppendVarint(t.buf, v)
+}
+
+func (t *thriftWriter) bool(id int16, v bool) {
+	if v {
+		t.field(id, thriftBoolTrue)
+	} else {
+		t.field(id, thriftBoolFalse)
+	}
+}
+
+func (t *thriftWriter) binary(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(v)))
+	t.buf = append(t.buf, v...)
+}
+
+// structBegin starts a struct valued field; close it with structEnd
+func (t *thriftWriter) structBegin(id int16) {
+	t.field(id, thriftStruct)
+	t.elemBegin()
+}
+
+func (t *thriftWriter) structEnd() {
+	t.elemEnd()
+}
+
+// listBegin starts a list field of size elements of type elem
+func (t *thriftWriter) listBegin(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elem)
+	} else {
+		t.buf = append(t.buf, 0xF0|elem)
+		t.buf = binary.AppendUvarint(t.buf, uint64(size))
+	}
+}
+
+// elemBegin starts a struct list element or nested struct
+func (t *thriftWriter) elemBegin() {
+	if len(t.lastID) == 0 {
+		t.lastID = append(t.lastID, 0)
+	}
+	t.lastID = append(t.lastID, 0)
+}
+
+// elemEnd writes the stop byte of the innermost struct
+func (t *thriftWriter) elemEnd() {
+	t.buf = append(t.buf, 0)
+	t.lastID = t.lastID[:len(t.lastID)-1]
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.buf = binary.AppendVarint(t.buf, int64(v))
+}
+
+func (t *thriftWriter) listBinary(v string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(v)))
+	t.buf = append(t.buf, v...)
+}
+
+// stop ends the top-level struct
+func (t *thriftWriter) stop() {
+	t.buf = append(t.buf, 0)
+}
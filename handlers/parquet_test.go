@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestThriftWriter(t *testing.T) {
+	var w thriftWriter
+	w.i32(1, 1)
+	w.binary(4, "ab")
+	w.structBegin(10)
+	w.bool(1, true)
+	w.structEnd()
+	w.i64(20, 1)
+	w.i32(40, -1)
+	w.listBegin(41, thriftI32, 2)
+	w.listI32(0)
+	w.listI32(3)
+	w.stop()
+
+	want := []byte{
+		0x15, 0x02, // field 1, i32 1 (zigzag)
+		0x38, 0x02, 'a', 'b', // field 4 (delta 3), binary
+		0x6c,       // field 10 (delta 6), struct
+		0x11,       // field 1, bool true
+		0x00,       // end of struct
+		0xa6, 0x02, // field 20 (delta 10), i64 1
+		0x05, 0x50, 0x01, // field 40 (delta 20, long form), i32 -1
+		0x19, 0x25, 0x00, 0x06, // field 41 (delta 1), list of 2 i32
+		0x00, // stop
+	}
+	if !bytes.Equal(w.buf, want) {
+		t.Errorf("thriftWriter encoded % x, want % x", w.buf, want)
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pw := newParquetWriter(&buf, TradeView{Fields: []string{"trade_id", "is_buyer_maker"}})
+	for i := 0; i < 10; i++ {
+		if err := pw.Write(&binancevisionconnector.Trade{TradeID: int64(i), IsBuyerMaker: i%3 == 0}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte(parquetMagic)) || !bytes.HasSuffix(data, []byte(parquetMagic)) {
+		t.Fatalf("file is not framed by %s: % x", parquetMagic, data)
+	}
+	footerLen := binary.LittleEndian.Uint32(data[len(data)-8:])
+	if int(footerLen) >= len(data)-12 {
+		t.Fatalf("footer length %d does not fit a %d byte file", footerLen, len(data))
+	}
+
+	// Each column is one gzip compressed page of PLAIN values, in schema order
+	pages := readGzipMembers(t, data[:len(data)-8-int(footerLen)])
+	if len(pages) != 2 {
+		t.Fatalf("found %d pages, want 2", len(pages))
+	}
+	for i := 0; i < 10; i++ {
+		if got := int64(binary.LittleEndian.Uint64(pages[0][i*8:])); got != int64(i) {
+			t.Errorf("trade_id[%d] = %d, want %d", i, got, i)
+		}
+	}
+	// Rows 0, 3, 6 and 9 are buyer maker, bit packed LSB first
+	if want := []byte{0x49, 0x02}; !bytes.Equal(pages[1], want) {
+		t.Errorf("is_buyer_maker page = % x, want % x", pages[1], want)
+	}
+}
+
+// TestParquetWriter_RoundTrip reads the file back with an independent Parquet reader,
+// across more than one row group and with camelCase column names
+func TestParquetWriter_RoundTrip(t *testing.T) {
+	type row struct {
+		TradeID       int64   `parquet:"tradeId"`
+		Price         float64 `parquet:"price"`
+		Quantity      float64 `parquet:"quantity"`
+		QuoteQuantity float64 `parquet:"quoteQuantity"`
+		Timestamp     int64   `parquet:"timestamp"`
+		IsBuyerMaker  bool    `parquet:"isBuyerMaker"`
+		IsBestMatch   bool    `parquet:"isBestMatch"`
+	}
+	trade := func(i int) binancevisionconnector.Trade {
+		return binancevisionconnector.Trade{
+			TradeID:       int64(i),
+			Price:         42000.5 + float64(i),
+			Quantity:      0.001 * float64(i%7),
+			QuoteQuantity: 21.0005,
+			Timestamp:     1700000000000 + int64(i),
+			IsBuyerMaker:  i%3 == 0,
+			IsBestMatch:   i%5 != 0,
+		}
+	}
+
+	const n = parquetRowGroupSize + 10
+	var buf bytes.Buffer
+	pw := newParquetWriter(&buf, TradeView{CamelCase: true})
+	for i := 0; i < n; i++ {
+		tr := trade(i)
+		if err := pw.Write(&tr); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("parquet.OpenFile() error = %v", err)
+	}
+	if got := len(f.RowGroups()); got != 2 {
+		t.Errorf("file has %d row groups, want 2", got)
+	}
+	if got := f.NumRows(); got != n {
+		t.Fatalf("file has %d rows, want %d", got, n)
+	}
+
+	r := parquet.NewGenericReader[row](f)
+	defer r.Close()
+	rows := make([]row, n)
+	if got, err := r.Read(rows); got != n || (err != nil && err != io.EOF) {
+		t.Fatalf("Read() = %d, %v, want %d rows", got, err, n)
+	}
+	for i, got := range rows {
+		tr := trade(i)
+		want := row{tr.TradeID, tr.Price, tr.Quantity, tr.QuoteQuantity, tr.Timestamp, tr.IsBuyerMaker, tr.IsBestMatch}
+		if got != want {
+			t.Fatalf("row %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+// readGzipMembers decompresses every gzip stream found in data, skipping the page
+// headers between them
+func readGzipMembers(t *testing.T, data []byte) [][]byte {
+	t.Helper()
+	var pages [][]byte
+	for {
+		start := bytes.Index(data, []byte{0x1f, 0x8b})
+		if start < 0 {
+			return pages
+		}
+		r := bytes.NewReader(data[start:])
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		zr.Multistream(false)
+		page, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("reading page: %v", err)
+		}
+		pages = append(pages, page)
+		data = data[len(data)-r.Len():]
+	}
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// maxPrefetchJobs is the number of jobs kept for status polling; the oldest
+// finished jobs are forgotten first
+const maxPrefetchJobs = 100
+
+// Prefetch job and item states
+const (
+	prefetchQueued  = "queued"
+	prefetchRunning = "running"
+	prefetchDone    = "done"
+	prefetchFailed  = "failed"
+)
+
+// PrefetchHandler schedules downloads into the connector's result cache in the
+// background so later /download requests for the same days are cache hits
+type PrefetchHandler struct {
+	Connector    *binancevisionconnector.Connector
+	Timeout      time.Duration // Timeout for each item's download
+	Metrics      *RequestMetrics
+	MaxBatchSize int          // Maximum number of items per job
+	Concurrency  int          // Maximum number of concurrent downloads across all jobs
+	MinDate      time.Time    // Earliest date accepted (zero = no minimum)
+	Symbols      SymbolAccess // Symbols the server may serve (zero value = all)
+
+	mu     sync.Mutex
+	jobs   map[string]*PrefetchJob
+	ids    []string        // Job IDs in creation order
+	ctx    context.Context // Parent of every item's context, cancelled by Shutdown
+	cancel context.CancelFunc
+	slots  chan struct{} // Bounds downloads across all jobs to Concurrency
+}
+
+// PrefetchJob reports the progress of a prefetch job
+type PrefetchJob struct {
+	ID          string               `json:"id"`
+	Status      string               `json:"status"` // queued, running or done
+	CreatedAt   time.Time            `json:"created_at"`
+	CompletedAt time.Time            `json:"completed_at,omitzero"`
+	Total       int                  `json:"total"`
+	Succeeded   int                  `json:"succeeded"`
+	Failed      int                  `json:"failed"`
+	Items       []PrefetchItemStatus `json:"items"`
+}
+
+// PrefetchItemStatus reports the state of one symbol/date in a prefetch job
+type PrefetchItemStatus struct {
+	Symbol string `json:"symbol"`
+	Date   string `json:"date"`
+	Status string `json:"status"` // queued, running, done or failed
+	Error  string `json:"error,omitempty"`
+
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// Handle validates a list of symbol/date items, schedules them for download and
+// responds immediately with the job ID
+func (h *PrefetchHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if h.Connector.Config().CacheSize <= 0 {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusConflict, APIResponse{
+			Success:   false,
+			Error:     "Prefetch requires the result cache to be enabled (set CACHE_SIZE)",
+			ErrorCode: CodeCacheDisabled,
+		})
+		return
+	}
+
+	var items []BatchItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, requestBodyErrorStatus(err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Invalid request body: %v", err),
+			ErrorCode: requestBodyErrorCode(err),
+		})
+		return
+	}
+
+	if len(items) == 0 {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     "Prefetch must contain at least one item",
+			ErrorCode: CodeInvalidRequestBody,
+		})
+		return
+	}
+
+	if h.MaxBatchSize > 0 && len(items) > h.MaxBatchSize {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Prefetch size %d exceeds maximum of %d", len(items), h.MaxBatchSize),
+			ErrorCode: CodeInvalidRequestBody,
+		})
+		return
+	}
+
+	// Reject the whole job up front, since nobody is waiting to see per-item validation errors
+	params := make([]downloadParams, len(items))
+	for i, item := range items {
+		p, err := parseBatchItem(item, h.MinDate, h.Symbols)
+		if err != nil {
+			h.Metrics.RecordFailure()
+			WriteJSONResponse(w, validationErrorStatus(err), APIResponse{
+				Success:   false,
+				Error:     fmt.Sprintf("Invalid item %d: %v", i, err),
+				ErrorCode: validationErrorCode(err),
+			})
+			return
+		}
+		params[i] = p
+	}
+
+	job := &PrefetchJob{
+		ID:        NewRequestID(),
+		Status:    prefetchQueued,
+		CreatedAt: time.Now().UTC(),
+		Total:     len(params),
+		Items:     make([]PrefetchItemStatus, len(params)),
+	}
+	for i, p := range params {
+		job.Items[i] = PrefetchItemStatus{Symbol: p.Symbol, Date: p.Date(), Status: prefetchQueued}
+	}
+	h.addJob(job)
+
+	Logger(r.Context()).Info("prefetch job scheduled", "job_id", job.ID, "items", job.Total)
+	go h.run(h.context(), job.ID, params)
+
+	h.Metrics.RecordSuccess()
+
+	WriteJSONResponse(w, http.StatusAccepted, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Scheduled %d items for prefetch", job.Total),
+		Data:    h.snapshot(job.ID),
+	})
+}
+
+// HandleStatus reports the progress of the job named by the {id} path segment
+func (h *PrefetchHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	job := h.snapshot(r.PathValue("id"))
+	if job == nil {
+		WriteJSONResponse(w, http.StatusNotFound, APIResponse{
+			Success:   false,
+			Error:     "Prefetch job not found",
+			ErrorCode: CodeNotFound,
+		})
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    job,
+	})
+}
+
+// Shutdown cancels the downloads of all running and later jobs, whose remaining
+// items are reported as failed
+func (h *PrefetchHandler) Shutdown() {
+	h.context()
+	h.cancel()
+}
+
+// context returns the context jobs run under, setting up the handler on first use
+func (h *PrefetchHandler) context() context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ctx == nil {
+		h.ctx, h.cancel = context.WithCancel(context.Background())
+		h.slots = make(chan struct{}, max(h.Concurrency, 1))
+	}
+	return h.ctx
+}
+
+// run downloads the job's items into the cache. At most Concurrency downloads run at
+// a time across all jobs.
+func (h *PrefetchHandler) run(ctx context.Context, id string, params []downloadParams) {
+	logger := slog.With("job_id", id)
+	start := time.Now()
+	h.update(id, func(job *PrefetchJob) { job.Status = prefetchRunning })
+
+	concurrency := h.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(params)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				h.prefetchItem(ctx, id, i, params[i], logger)
+			}
+		}()
+	}
+	for i := range params {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	h.update(id, func(job *PrefetchJob) {
+		job.Status = prefetchDone
+		job.CompletedAt = time.Now().UTC()
+		logger.Info("prefetch job finished", "duration", time.Since(start),
+			"succeeded", job.Succeeded, "failed", job.Failed)
+	})
+}
+
+// prefetchItem downloads one item of a job and records its outcome
+func (h *PrefetchHandler) prefetchItem(ctx context.Context, id string, i int, params downloadParams, logger *slog.Logger) {
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	case <-ctx.Done():
+		h.update(id, func(job *PrefetchJob) {
+			job.Items[i].Status = prefetchFailed
+			job.Items[i].Error = fmt.Sprintf("Not downloaded: prefetch %v", ctx.Err())
+			job.Items[i].ErrorCode = downloadErrorCode(ctx.Err())
+			job.Failed++
+		})
+		return
+	}
+	h.update(id, func(job *PrefetchJob) { job.Items[i].Status = prefetchRunning })
+
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := h.Connector.DownloadTrades(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		logger.Error("prefetch download failed", "symbol", params.Symbol, "date", params.Date(),
+			"duration", time.Since(start), "error", err)
+	} else if !result.CacheHit {
+		h.Metrics.RecordDownloadSuccess(time.Since(start), result.TradeCount)
+	}
+
+	h.update(id, func(job *PrefetchJob) {
+		if err != nil {
+			job.Items[i].Status = prefetchFailed
+			job.Items[i].Error = fmt.Sprintf("Failed to download and parse trades: %v", err)
+			job.Items[i].ErrorCode = downloadErrorCode(err)
+			job.Failed++
+			return
+		}
+		job.Items[i].Status = prefetchDone
+		job.Succeeded++
+	})
+}
+
+// addJob registers a job, forgetting the oldest finished jobs beyond maxPrefetchJobs
+func (h *PrefetchHandler) addJob(job *PrefetchJob) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.jobs == nil {
+		h.jobs = make(map[string]*PrefetchJob)
+	}
+	h.jobs[job.ID] = job
+	h.ids = append(h.ids, job.ID)
+
+	for i := 0; len(h.ids) > maxPrefetchJobs && i < len(h.ids); {
+		if old := h.jobs[h.ids[i]]; old.Status == prefetchDone {
+			delete(h.jobs, h.ids[i])
+			h.ids = append(h.ids[:i], h.ids[i+1:]...)
+			continue
+		}
+		i++
+	}
+}
+
+// update applies fn to a job under the lock
+func (h *PrefetchHandler) update(id string, fn func(job *PrefetchJob)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if job, ok := h.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// snapshot returns a copy of a job that is safe to encode while it keeps running,
+// or nil if the job is unknown
+func (h *PrefetchHandler) snapshot(id string) *PrefetchJob {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	job, ok := h.jobs[id]
+	if !ok {
+		return nil
+	}
+	copied := *job
+	copied.Items = append([]PrefetchItemStatus(nil), job.Items...)
+	return &copied
+}
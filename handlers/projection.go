@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Embedded so ?tz= works on hosts without a zoneinfo database
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// tradeFieldNames lists the JSON field names of a trade in output order
+var tradeFieldNames = func() []string {
+	names := make([]string, len(binancevisionconnector.TradeJSONFields))
+	for i, field := range binancevisionconnector.TradeJSONFields {
+		names[i] = field.Name
+	}
+	return names
+}()
+
+// tradeFieldCamelNames maps JSON field names to their camelCase form
+var tradeFieldCamelNames = func() map[string]string {
+	names := make(map[string]string, len(tradeFieldNames))
+	for _, field := range tradeFieldNames {
+		names[field] = snakeToCamel(field)
+	}
+	return names
+}()
+
+// tradeFieldEncoder appends the JSON value of a single trade field
+type tradeFieldEncoder func(dst []byte, t *binancevisionconnector.Trade) []byte
+
+// tradeFieldEncoders maps JSON field names to their encoders
+var tradeFieldEncoders = func() map[string]tradeFieldEncoder {
+	encoders := make(map[string]tradeFieldEncoder, len(binancevisionconnector.TradeJSONFields))
+	for _, field := range binancevisionconnector.TradeJSONFields {
+		encoders[field.Name] = field.Append
+	}
+	return encoders
+}()
+
+// rfc3339Millis is RFC 3339 with millisecond precision, matching the precision of trade timestamps
+const rfc3339Millis = "2006-01-02T15:04:05.000Z07:00"
+
+// encodeTimestampRFC3339 appends the trade timestamp as a quoted RFC 3339 UTC string
+func encodeTimestampRFC3339(dst []byte, t *binancevisionconnector.Trade) []byte {
+	dst = append(dst, '"')
+	dst = time.UnixMilli(t.Timestamp).UTC().AppendFormat(dst, rfc3339Millis)
+	return append(dst, '"')
+}
+
+// timestampRFC3339In returns an encoder for the trade timestamp as a quoted RFC 3339
+// string in loc, with loc's UTC offset
+func timestampRFC3339In(loc *time.Location) tradeFieldEncoder {
+	return func(dst []byte, t *binancevisionconnector.Trade) []byte {
+		dst = append(dst, '"')
+		dst = time.UnixMilli(t.Timestamp).In(loc).AppendFormat(dst, rfc3339Millis)
+		return append(dst, '"')
+	}
+}
+
+// TradeView controls how trades are rendered in JSON responses
+type TradeView struct {
+	Fields      []string // Field names to include, in output order (empty = all fields)
+	RFC3339Time bool     // Render timestamps as RFC 3339 strings instead of epoch milliseconds
+	CamelCase   bool     // Use camelCase JSON keys (tradeId) instead of snake_case (trade_id)
+
+	Location *time.Location // Time zone of RFC 3339 timestamps (nil = UTC)
+}
+
+// TradeList renders trades according to a view
+type TradeList struct {
+	Trades []binancevisionconnector.Trade
+	View   TradeView
+
+	placeholder string // Encoded instead of the trades by writeStreamedTradesResponse
+}
+
+// DownloadResponse is the /download payload: the connector result with its trades
+// rendered through a TradeList and optional pagination metadata
+type DownloadResponse struct {
+	*binancevisionconnector.DownloadResult
+	Trades TradeList `json:"trades"`
+	*PageInfo
+}
+
+// parseTradeView extracts the fields, time_format, tz and naming query parameters.
+// Unknown field names, time formats, time zones and naming styles are rejected.
+func parseTradeView(r *http.Request) (TradeView, error) {
+	var view TradeView
+
+	switch naming := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("naming"))); naming {
+	case "", "snake":
+	case "camel":
+		view.CamelCase = true
+	default:
+		return view, fmt.Errorf("invalid naming: %s (valid styles: snake, camel)", naming)
+	}
+
+	switch timeFormat := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("time_format"))); timeFormat {
+	case "", "epoch_ms":
+	case "rfc3339":
+		view.RFC3339Time = true
+	default:
+		return view, fmt.Errorf("invalid time_format: %s (valid formats: epoch_ms, rfc3339)", timeFormat)
+	}
+
+	// Epoch milliseconds have no zone, so tz only changes how RFC 3339 timestamps are written
+	if tz := strings.TrimSpace(r.URL.Query().Get("tz")); tz != "" {
+		if !view.RFC3339Time {
+			return view, fmt.Errorf("tz requires time_format=rfc3339")
+		}
+		loc, err := time.LoadLocation(tz)
+		if err != nil || tz == "Local" {
+			return view, fmt.Errorf("invalid tz: %s (use an IANA time zone name such as America/New_York)", tz)
+		}
+		view.Location = loc
+	}
+
+	fieldsRaw := strings.TrimSpace(r.URL.Query().Get("fields"))
+	if fieldsRaw == "" {
+		return view, nil
+	}
+
+	requested := make(map[string]bool)
+	for _, field := range strings.Split(fieldsRaw, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if _, ok := tradeFieldEncoders[field]; !ok {
+			return view, fmt.Errorf("unknown field: %s (valid fields: %s)", field, strings.Join(tradeFieldNames, ", "))
+		}
+		requested[field] = true
+	}
+
+	// Keep the canonical field order regardless of the order requested
+	for _, field := range tradeFieldNames {
+		if requested[field] {
+			view.Fields = append(view.Fields, field)
+		}
+	}
+
+	return view, nil
+}
+
+// MarshalJSON encodes the trades, including only the view's fields
+func (l TradeList) MarshalJSON() ([]byte, error) {
+	if l.placeholder != "" {
+		return strconv.AppendQuote(nil, l.placeholder), nil
+	}
+	if len(l.View.Fields) == 0 && !l.View.RFC3339Time {
+		if l.Trades == nil {
+			return []byte("null"), nil
+		}
+		return json.Marshal(l.Trades)
+	}
+
+	fields := l.View.fieldsOrAll()
+	buf := make([]byte, 0, len(l.Trades)*16*len(fields)+2)
+	buf = append(buf, '[')
+	for i := range l.Trades {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = l.View.appendTradeJSON(buf, &l.Trades[i], fields)
+	}
+	buf = append(buf, ']')
+
+	return buf, nil
+}
+
+// fieldsOrAll returns the view's fields, or every trade field if none were selected
+func (v TradeView) fieldsOrAll() []string {
+	if len(v.Fields) == 0 {
+		return tradeFieldNames
+	}
+	return v.Fields
+}
+
+// encoder returns the encoder for a field, taking the view's time format into account
+func (v TradeView) encoder(field string) tradeFieldEncoder {
+	if field == "timestamp" && v.RFC3339Time {
+		if v.Location != nil {
+			return timestampRFC3339In(v.Location)
+		}
+		return encodeTimestampRFC3339
+	}
+	return tradeFieldEncoders[field]
+}
+
+// appendTradeJSON appends a JSON object containing the given fields of t
+func (v TradeView) appendTradeJSON(dst []byte, t *binancevisionconnector.Trade, fields []string) []byte {
+	dst = append(dst, '{')
+	for i, field := range fields {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, '"')
+		if v.CamelCase {
+			dst = append(dst, tradeFieldCamelNames[field]...)
+		} else {
+			dst = append(dst, field...)
+		}
+		dst = append(dst, '"', ':')
+		dst = v.encoder(field)(dst, t)
+	}
+	return append(dst, '}')
+}
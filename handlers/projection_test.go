@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestParseTradeView(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantFields []string
+		wantErr    bool
+	}{
+		{"no fields", "", nil, false},
+		{"single field", "fields=price", []string{"price"}, false},
+		{"canonical order", "fields=timestamp,price", []string{"price", "timestamp"}, false},
+		{"case and spaces", "fields=%20Price%20,TIMESTAMP", []string{"price", "timestamp"}, false},
+		{"unknown field", "fields=price,volume", nil, true},
+		{"rfc3339 time format", "time_format=rfc3339", nil, false},
+		{"unknown time format", "time_format=unix", nil, true},
+		{"camel naming", "naming=camel", nil, false},
+		{"unknown naming", "naming=kebab", nil, true},
+		{"time zone", "time_format=rfc3339&tz=America/New_York", nil, false},
+		{"unknown time zone", "time_format=rfc3339&tz=Mars/Olympus_Mons", nil, true},
+		{"server local time zone", "time_format=rfc3339&tz=Local", nil, true},
+		{"time zone without rfc3339", "tz=America/New_York", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/download?"+tt.query, nil)
+			view, err := parseTradeView(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTradeView(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(view.Fields, tt.wantFields) {
+				t.Errorf("parseTradeView(%q) fields = %v, want %v", tt.query, view.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestTradeListMarshalJSON(t *testing.T) {
+	trades := []binancevisionconnector.Trade{
+		{TradeID: 1, Price: 0.001234, Quantity: 100, QuoteQuantity: 0.1234, Timestamp: 1735430400000, IsBuyerMaker: true, IsBestMatch: true},
+		{TradeID: 2, Price: 1e-7, Quantity: 2.5, QuoteQuantity: 2.5e-7, Timestamp: 1735430401000},
+	}
+
+	// Without a projection the output matches the default encoding
+	got, err := json.Marshal(TradeList{Trades: trades})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	want, _ := json.Marshal(trades)
+	if string(got) != string(want) {
+		t.Errorf("MarshalJSON() = %s, want %s", got, want)
+	}
+
+	// With a projection only the requested keys are emitted
+	got, err = json.Marshal(TradeList{Trades: trades, View: TradeView{Fields: []string{"price", "timestamp"}}})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	wantProjected := `[{"price":0.001234,"timestamp":1735430400000},{"price":1e-7,"timestamp":1735430401000}]`
+	if string(got) != wantProjected {
+		t.Errorf("MarshalJSON() = %s, want %s", got, wantProjected)
+	}
+
+	// RFC 3339 timestamps are rendered as UTC strings with millisecond precision
+	got, err = json.Marshal(TradeList{Trades: trades[:1], View: TradeView{Fields: []string{"trade_id", "timestamp"}, RFC3339Time: true}})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	wantRFC3339 := `[{"trade_id":1,"timestamp":"2024-12-29T00:00:00.000Z"}]`
+	if string(got) != wantRFC3339 {
+		t.Errorf("MarshalJSON() = %s, want %s", got, wantRFC3339)
+	}
+
+	// With a time zone they carry its offset instead
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	got, err = json.Marshal(TradeList{Trades: trades[:1], View: TradeView{Fields: []string{"timestamp"}, RFC3339Time: true, Location: newYork}})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	wantZoned := `[{"timestamp":"2024-12-28T19:00:00.000-05:00"}]`
+	if string(got) != wantZoned {
+		t.Errorf("MarshalJSON() = %s, want %s", got, wantZoned)
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"slices"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// Protobuf wire types used by the Trade message in proto/trade.proto
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+)
+
+// tradeProtoField is a field of the Trade message in proto/trade.proto. The tests
+// check the table against the .proto file, so the two cannot drift apart.
+type tradeProtoField struct {
+	name   string // Trade field name, as in tradeFieldNames
+	number int
+	typ    string // Scalar type in the .proto file
+	append func(dst []byte, number int, t *binancevisionconnector.Trade) []byte
+}
+
+// tradeProtoFields lists the fields of the Trade message in field number order. Zero
+// values are omitted as in proto3.
+var tradeProtoFields = []tradeProtoField{
+	{"trade_id", 1, "int64", func(dst []byte, number int, t *binancevisionconnector.Trade) []byte {
+		return appendProtoVarint(dst, number, uint64(t.TradeID), t.TradeID != 0)
+	}},
+	{"price", 2, "double", func(dst []byte, number int, t *binancevisionconnector.Trade) []byte {
+		return appendProtoDouble(dst, number, t.Price)
+	}},
+	{"quantity", 3, "double", func(dst []byte, number int, t *binancevisionconnector.Trade) []byte {
+		return appendProtoDouble(dst, number, t.Quantity)
+	}},
+	{"quote_quantity", 4, "double", func(dst []byte, number int, t *binancevisionconnector.Trade) []byte {
+		return appendProtoDouble(dst, number, t.QuoteQuantity)
+	}},
+	{"timestamp", 5, "int64", func(dst []byte, number int, t *binancevisionconnector.Trade) []byte {
+		return appendProtoVarint(dst, number, uint64(t.Timestamp), t.Timestamp != 0)
+	}},
+	{"is_buyer_maker", 6, "bool", func(dst []byte, number int, t *binancevisionconnector.Trade) []byte {
+		return appendProtoVarint(dst, number, 1, t.IsBuyerMaker)
+	}},
+	{"is_best_match", 7, "bool", func(dst []byte, number int, t *binancevisionconnector.Trade) []byte {
+		return appendProtoVarint(dst, number, 1, t.IsBestMatch)
+	}},
+}
+
+// writeTradesProtobuf writes trades as length-delimited Trade messages (see
+// proto/trade.proto). Fields left out of the view are omitted, so they decode
+// to their zero values. Timestamps are always epoch milliseconds.
+func writeTradesProtobuf(w io.Writer, trades []binancevisionconnector.Trade, view TradeView) error {
+	var fields []tradeProtoField
+	for _, field := range tradeProtoFields {
+		if slices.Contains(view.fieldsOrAll(), field.name) {
+			fields = append(fields, field)
+		}
+	}
+
+	var msg, buf []byte
+	for i := range trades {
+		msg = appendTradeProto(msg[:0], &trades[i], fields)
+		buf = binary.AppendUvarint(buf[:0], uint64(len(msg)))
+		buf = append(buf, msg...)
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendTradeProto appends the protobuf encoding of the given fields of t
+func appendTradeProto(dst []byte, t *binancevisionconnector.Trade, fields []tradeProtoField) []byte {
+	for _, field := range fields {
+		dst = field.append(dst, field.number, t)
+	}
+	return dst
+}
+
+// appendProtoVarint appends a varint field unless present is false; negative int64
+// values take ten bytes as in protobuf
+func appendProtoVarint(dst []byte, field int, v uint64, present bool) []byte {
+	if !present {
+		return dst
+	}
+	dst = binary.AppendUvarint(dst, uint64(field)<<3|protoWireVarint)
+	return binary.AppendUvarint(dst, v)
+}
+
+// appendProtoDouble appends a double field as little-endian fixed64 unless it is zero
+func appendProtoDouble(dst []byte, field int, v float64) []byte {
+	if v == 0 {
+		return dst
+	}
+	dst = binary.AppendUvarint(dst, uint64(field)<<3|protoWireFixed64)
+	return binary.LittleEndian.AppendUint64(dst, math.Float64bits(v))
+}
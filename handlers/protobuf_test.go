@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestWriteTradesProtobuf(t *testing.T) {
+	trades := []binancevisionconnector.Trade{
+		{TradeID: 1, Price: 0.5, IsBestMatch: true},
+		{TradeID: 300, Timestamp: 1},
+	}
+
+	tests := []struct {
+		name string
+		view TradeView
+		want []byte
+	}{
+		{
+			name: "all fields",
+			want: []byte{
+				// Trade 1: trade_id=1, price=0.5, is_best_match=true; zero fields are omitted
+				0x0d,
+				0x08, 0x01,
+				0x11, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe0, 0x3f,
+				0x38, 0x01,
+				// Trade 2: trade_id=300 (two byte varint), timestamp=1
+				0x05,
+				0x08, 0xac, 0x02,
+				0x28, 0x01,
+			},
+		},
+		{
+			name: "projected fields",
+			view: TradeView{Fields: []string{"trade_id"}},
+			want: []byte{
+				0x02, 0x08, 0x01,
+				0x03, 0x08, 0xac, 0x02,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeTradesProtobuf(&buf, trades, tt.view); err != nil {
+				t.Fatalf("writeTradesProtobuf() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("writeTradesProtobuf() = % x, want % x", buf.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+// TestTradeProtoFields pins tradeProtoFields to the Trade message of proto/trade.proto
+func TestTradeProtoFields(t *testing.T) {
+	proto, err := os.ReadFile("../proto/trade.proto")
+	if err != nil {
+		t.Fatalf("reading trade.proto: %v", err)
+	}
+	message := regexp.MustCompile(`(?s)message Trade \{(.*?)\}`).FindSubmatch(proto)
+	if message == nil {
+		t.Fatal("trade.proto has no Trade message")
+	}
+
+	var declared []tradeProtoField
+	for _, m := range regexp.MustCompile(`(?m)^\s*(\w+)\s+(\w+)\s*=\s*(\d+);`).FindAllSubmatch(message[1], -1) {
+		number, _ := strconv.Atoi(string(m[3]))
+		declared = append(declared, tradeProtoField{name: string(m[2]), number: number, typ: string(m[1])})
+	}
+	if len(declared) != len(tradeProtoFields) {
+		t.Fatalf("trade.proto declares %d fields, tradeProtoFields has %d", len(declared), len(tradeProtoFields))
+	}
+	for i, want := range declared {
+		got := tradeProtoFields[i]
+		if got.name != want.name || got.number != want.number || got.typ != want.typ {
+			t.Errorf("tradeProtoFields[%d] = %s %s = %d, trade.proto declares %s %s = %d",
+				i, got.typ, got.name, got.number, want.typ, want.name, want.number)
+		}
+	}
+
+	// Every trade field can be written
+	for _, name := range tradeFieldNames {
+		if !slices.ContainsFunc(tradeProtoFields, func(f tradeProtoField) bool { return f.name == name }) {
+			t.Errorf("trade field %s has no protobuf field", name)
+		}
+	}
+}
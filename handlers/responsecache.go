@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ResponseCache is an LRU cache of rendered /download responses, bounded by the total
+// size of their bodies, so repeated identical requests are answered without encoding
+// the trades again. Like the connector's result cache, entries do not expire, since
+// published archives never change. A nil cache never hits.
+type ResponseCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64                    // Total body size of the cached responses
+	compress bool                     // Store bodies gzip-compressed
+	order    *list.List               // Most recently used at the front
+	entries  map[string]*list.Element // Values are *cachedResponse
+
+	hits, misses, evictions int64
+}
+
+// ResponseCacheStats reports the activity of the response cache since it was created
+type ResponseCacheStats struct {
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Evictions int64   `json:"evictions"`
+	HitRatio  float64 `json:"hit_ratio"` // Hits over lookups, 0 before the first lookup
+	Entries   int     `json:"entries"`   // Responses currently cached
+	Bytes     int64   `json:"bytes"`     // Total body size of the cached responses
+	MaxBytes  int64   `json:"max_bytes"`
+}
+
+// responseCacheHeader tells clients whether a response was replayed from the cache,
+// since a replayed body keeps the timing and cache_hit of the response it recorded
+const responseCacheHeader = "X-Response-Cache"
+
+// perRequestHeaders are set for every request before its response is rendered or
+// replayed, so they are not stored with the response
+var perRequestHeaders = []string{RequestIDHeader, "Vary", "Content-Length"}
+
+// cachedResponse is a rendered response and its key
+type cachedResponse struct {
+	key        string
+	header     http.Header
+	body       []byte
+	gzipped    bool
+	tradeCount int // Trades of the download the response was rendered from, for metrics
+}
+
+// NewResponseCache creates a cache holding up to maxBytes of response bodies, or nil
+// when maxBytes is not positive. With compress set, bodies are stored gzip-compressed,
+// so more responses fit, and sent as they are to clients that accept gzip.
+func NewResponseCache(maxBytes int64, compress bool) *ResponseCache {
+	if maxBytes <= 0 {
+		return nil
+	}
+	return &ResponseCache{
+		maxBytes: maxBytes,
+		compress: compress,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// responseCacheKey identifies a rendered trades response by symbol, date, format and
+// every query parameter that shapes it
+func responseCacheKey(r *http.Request, symbol, date string, format outputFormat) string {
+	return symbol + "|" + date + "|" + string(format) + "|" + shapingQuery(r)
+}
+
+// get returns the cached response for key. Entries are never modified once added.
+func (c *ResponseCache) get(key string) (*cachedResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cachedResponse), true
+}
+
+// add stores entry, evicting the least recently used entries until the bodies fit
+func (c *ResponseCache) add(entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[entry.key]; ok {
+		c.bytes -= int64(len(elem.Value.(*cachedResponse).body))
+		c.order.Remove(elem)
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	c.bytes += int64(len(entry.body))
+
+	for c.bytes > c.maxBytes {
+		oldest := c.order.Back()
+		evicted := oldest.Value.(*cachedResponse)
+		c.order.Remove(oldest)
+		delete(c.entries, evicted.key)
+		c.bytes -= int64(len(evicted.body))
+		c.evictions++
+	}
+}
+
+// Stats returns the cache's counters. A nil cache reports zeros.
+func (c *ResponseCache) Stats() ResponseCacheStats {
+	if c == nil {
+		return ResponseCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := ResponseCacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   c.order.Len(),
+		Bytes:     c.bytes,
+		MaxBytes:  c.maxBytes,
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRatio = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
+
+// record returns a writer that passes the response through to w and keeps a copy for
+// store, or nil for a nil cache
+func (c *ResponseCache) record(w http.ResponseWriter) *responseRecorder {
+	if c == nil {
+		return nil
+	}
+	w.Header().Set(responseCacheHeader, "MISS")
+	rec := &responseRecorder{ResponseWriter: w, limit: c.maxBytes}
+	if c.compress {
+		rec.zw, _ = gzip.NewWriterLevel(&rec.buf, gzip.BestSpeed)
+	}
+	return rec
+}
+
+// store adds the response rec recorded under key if it was sent in full with 200 and
+// fits in the cache. A nil rec is ignored.
+func (c *ResponseCache) store(key string, rec *responseRecorder) {
+	if rec == nil || rec.failed || rec.status != http.StatusOK {
+		return
+	}
+	if rec.zw != nil {
+		if err := rec.zw.Close(); err != nil {
+			return
+		}
+	}
+	if int64(rec.buf.Len()) > c.maxBytes {
+		return
+	}
+
+	header := rec.Header().Clone()
+	for _, name := range perRequestHeaders {
+		header.Del(name)
+	}
+	c.add(&cachedResponse{
+		key:        key,
+		header:     header,
+		body:       rec.buf.Bytes(),
+		gzipped:    rec.zw != nil,
+		tradeCount: rec.tradeCount,
+	})
+}
+
+// responseRecorder passes a response through while recording its status and body.
+// Recording stops once the body outgrows the cache or a write fails.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+	zw     *gzip.Writer // Compresses into buf when set
+	limit  int64
+	failed bool
+
+	tradeCount int // Set by the handler once the trades are downloaded
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(p)
+	if err != nil {
+		rec.failed = true
+	}
+	if !rec.failed {
+		if rec.zw != nil {
+			rec.zw.Write(p[:n])
+		} else {
+			rec.buf.Write(p[:n])
+		}
+		if int64(rec.buf.Len()) > rec.limit {
+			rec.failed = true
+			rec.buf = bytes.Buffer{}
+		}
+	}
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// writeCachedResponse sends a cached response, or 304 when the request's preconditions
+// match its ETag or Last-Modified, marked with X-Response-Cache: HIT. Compressed bodies are sent as they are to
+// clients that accept gzip and decompressed for the others.
+func writeCachedResponse(w http.ResponseWriter, r *http.Request, entry *cachedResponse) {
+	for key, values := range entry.header {
+		w.Header()[key] = append([]string(nil), values...) // Later Adds must not reach the entry
+	}
+	w.Header().Set(responseCacheHeader, "HIT")
+	lastModified, _ := http.ParseTime(entry.header.Get("Last-Modified"))
+	if etagMatches(r.Header.Get("If-None-Match"), entry.header.Get("ETag")) || notModifiedSince(r, lastModified) {
+		writeNotModified(w)
+		return
+	}
+
+	if !entry.gzipped {
+		w.Header().Set("Content-Length", strconv.Itoa(len(entry.body)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(entry.body)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	if acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", strconv.Itoa(len(entry.body)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(entry.body)
+		return
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(entry.body))
+	if err != nil {
+		// Not reached: the body was compressed by store
+		slog.Error("failed to read cached response", "error", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, zr)
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(params), "=")
+		if ok && strings.EqualFold(key, "q") {
+			if q, err := strconv.ParseFloat(value, 64); err == nil && q == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseCache_EvictsBySize(t *testing.T) {
+	c := NewResponseCache(10, false)
+	c.add(&cachedResponse{key: "a", body: []byte("aaaa")})
+	c.add(&cachedResponse{key: "b", body: []byte("bbbb")})
+	c.get("a") // Make b the least recently used
+	c.add(&cachedResponse{key: "c", body: []byte("cccc")})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("get(b) hit, want it evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("get(%s) missed, want a hit", key)
+		}
+	}
+	if c.bytes != 8 {
+		t.Errorf("bytes = %d, want 8", c.bytes)
+	}
+	want := ResponseCacheStats{Hits: 3, Misses: 1, Evictions: 1, HitRatio: 0.75, Entries: 2, Bytes: 8, MaxBytes: 10}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	if NewResponseCache(0, false) != nil {
+		t.Error("NewResponseCache(0) != nil, want a disabled cache")
+	}
+}
+
+func TestResponseCache_Store(t *testing.T) {
+	tests := []struct {
+		name      string
+		compress  bool
+		status    int
+		body      string
+		wantEntry bool
+	}{
+		{"ok response", false, http.StatusOK, "hello", true},
+		{"compressed response", true, http.StatusOK, "hello", true},
+		{"error response", false, http.StatusBadGateway, "hello", false},
+		{"too large", false, http.StatusOK, strings.Repeat("x", 100), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewResponseCache(64, tt.compress)
+			w := httptest.NewRecorder()
+			rec := c.record(w)
+			rec.Header().Set("Content-Type", "text/plain")
+			rec.WriteHeader(tt.status)
+			rec.Write([]byte(tt.body))
+			c.store("key", rec)
+
+			if w.Body.String() != tt.body {
+				t.Errorf("passed through %q, want %q", w.Body.String(), tt.body)
+			}
+			entry, ok := c.get("key")
+			if ok != tt.wantEntry {
+				t.Fatalf("get() ok = %v, want %v", ok, tt.wantEntry)
+			}
+			if !ok {
+				return
+			}
+
+			// A client without gzip support always gets the plain body
+			replay := httptest.NewRecorder()
+			writeCachedResponse(replay, httptest.NewRequest("GET", "/download", nil), entry)
+			if replay.Body.String() != tt.body || replay.Header().Get("Content-Type") != "text/plain" {
+				t.Errorf("replayed %q with Content-Type %q", replay.Body.String(), replay.Header().Get("Content-Type"))
+			}
+			if w.Header().Get(responseCacheHeader) != "MISS" || replay.Header().Get(responseCacheHeader) != "HIT" {
+				t.Errorf("%s = %q recorded and %q replayed, want MISS and HIT", responseCacheHeader,
+					w.Header().Get(responseCacheHeader), replay.Header().Get(responseCacheHeader))
+			}
+		})
+	}
+}
+
+func TestWriteCachedResponse_Gzip(t *testing.T) {
+	c := NewResponseCache(1<<20, true)
+	rec := c.record(httptest.NewRecorder())
+	rec.Write([]byte(`{"success":true}`))
+	c.store("key", rec)
+	entry, _ := c.get("key")
+
+	r := httptest.NewRequest("GET", "/download", nil)
+	r.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+	w := httptest.NewRecorder()
+	writeCachedResponse(w, r, entry)
+
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", w.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != `{"success":true}` {
+		t.Errorf("decompressed body = %q", body)
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.5", true},
+		{"GZIP", true},
+		{"gzip;q=0", false},
+		{"br", false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// sseProgressInterval is how often /download/stream sends a progress event while
+// the download is making progress
+const sseProgressInterval = 500 * time.Millisecond
+
+// DownloadStreamSummary is the data of the complete event sent on /download/stream
+type DownloadStreamSummary struct {
+	*binancevisionconnector.DownloadResult
+	Trades []binancevisionconnector.Trade `json:"trades,omitempty"` // Left nil to hide the result's trades
+}
+
+// sseWriter writes Server-Sent Events, flushing each one so it reaches the client at once
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// event sends data as JSON in an event of type name
+func (s sseWriter) event(name string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, payload); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// HandleDownloadStream downloads and parses a day's trades like /download, reporting
+// how far it has got as Server-Sent Events instead of returning the trades: progress
+// events with the bytes downloaded and trades parsed so far, then a complete event
+// with the result summary, or an error event. Parameters are validated before the
+// stream starts, so invalid requests still get a plain JSON error.
+func (h *DownloadHandler) HandleDownloadStream(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	// The connector reports from its own goroutines, so keep the latest progress for
+	// this goroutine to send, skipping ticks where nothing changed
+	var (
+		mu       sync.Mutex
+		progress binancevisionconnector.Progress
+		changed  bool
+	)
+	ctx = binancevisionconnector.ContextWithProgress(ctx, func(p binancevisionconnector.Progress) {
+		mu.Lock()
+		progress, changed = p, true
+		mu.Unlock()
+	})
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	type outcome struct {
+		result *binancevisionconnector.DownloadResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := h.Connector.DownloadTrades(ctx, params.Symbol, params.Year, params.Month, params.Day)
+		done <- outcome{result, err}
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep nginx from holding events back
+	w.WriteHeader(http.StatusOK)
+	events := sseWriter{w: w, rc: http.NewResponseController(w)}
+	events.rc.Flush()
+
+	// Write errors mean the client went away, which cancels the download through the
+	// request context, so they are not handled separately
+	sendProgress := func() {
+		mu.Lock()
+		p, ok := progress, changed
+		changed = false
+		mu.Unlock()
+		if ok {
+			events.event("progress", p)
+		}
+	}
+
+	ticker := time.NewTicker(sseProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sendProgress()
+		case out := <-done:
+			sendProgress()
+			if out.err != nil {
+				h.Metrics.RecordFailure()
+				logger.Error("trade download stream failed", "duration", time.Since(start), "error", out.err)
+				events.event("error", APIResponse{
+					Success:   false,
+					Error:     fmt.Sprintf("Failed to download and parse trades: %v", out.err),
+					ErrorCode: downloadErrorCode(out.err),
+					SourceURL: h.Connector.TradesURL(params.Symbol, params.Year, params.Month, params.Day),
+				})
+				return
+			}
+
+			h.Metrics.RecordSuccess()
+			h.Metrics.RecordDownloadSuccess(time.Since(start), out.result.TradeCount)
+			logger.Info("trade download stream succeeded", "duration", time.Since(start), "trade_count", out.result.TradeCount)
+			events.event("complete", DownloadStreamSummary{DownloadResult: out.result})
+			return
+		}
+	}
+}
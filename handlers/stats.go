@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// statsWindowSize is the number of most recent successful downloads /stats is computed over
+const statsWindowSize = 1000
+
+// downloadSample is one successful download recorded for /stats
+type downloadSample struct {
+	duration   time.Duration
+	tradeCount int
+}
+
+// downloadWindow is a ring buffer of the most recent download samples. The zero
+// value is ready to use; it is guarded by RequestMetrics.Mu.
+type downloadWindow struct {
+	samples []downloadSample
+	next    int // Index overwritten by the next sample once the buffer is full
+}
+
+// add records a sample, evicting the oldest one when the window is full
+func (w *downloadWindow) add(s downloadSample) {
+	if len(w.samples) < statsWindowSize {
+		w.samples = append(w.samples, s)
+		return
+	}
+	w.samples[w.next] = s
+	w.next = (w.next + 1) % statsWindowSize
+}
+
+// DownloadStats summarizes the successful downloads in the sliding window
+type DownloadStats struct {
+	WindowSize              int     `json:"window_size"`  // Maximum number of downloads considered
+	SampleCount             int     `json:"sample_count"` // Downloads currently in the window
+	P50MS                   float64 `json:"p50_ms"`
+	P90MS                   float64 `json:"p90_ms"`
+	P99MS                   float64 `json:"p99_ms"`
+	AverageTradesPerRequest float64 `json:"average_trades_per_request"`
+}
+
+// DownloadStats computes latency percentiles and the average trade count over
+// the most recent successful downloads. All values are zero before the first download.
+func (m *RequestMetrics) DownloadStats() DownloadStats {
+	m.Mu.RLock()
+	samples := slices.Clone(m.downloads.samples)
+	m.Mu.RUnlock()
+
+	stats := DownloadStats{
+		WindowSize:  statsWindowSize,
+		SampleCount: len(samples),
+	}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	durations := make([]time.Duration, len(samples))
+	totalTrades := 0
+	for i, s := range samples {
+		durations[i] = s.duration
+		totalTrades += s.tradeCount
+	}
+	slices.Sort(durations)
+
+	stats.P50MS = binancevisionconnector.DurationMS(percentile(durations, 50))
+	stats.P90MS = binancevisionconnector.DurationMS(percentile(durations, 90))
+	stats.P99MS = binancevisionconnector.DurationMS(percentile(durations, 99))
+	stats.AverageTradesPerRequest = float64(totalTrades) / float64(len(samples))
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p (0-100] of non-empty sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// StatsHandler handles download statistics requests
+type StatsHandler struct {
+	Metrics   *RequestMetrics
+	Connector *binancevisionconnector.Connector // Optional; reports the result cache when set
+	Responses *ResponseCache                    // Optional; reports the response cache when set
+}
+
+// statsResponse is the data of a /stats response
+type statsResponse struct {
+	DownloadStats
+	Cache         *binancevisionconnector.CacheStats `json:"cache,omitempty"`
+	ResponseCache *ResponseCacheStats                `json:"response_cache,omitempty"`
+}
+
+// Handle reports latency percentiles, average trades per request and result cache activity
+func (h *StatsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	stats := statsResponse{DownloadStats: h.Metrics.DownloadStats()}
+	if h.Connector != nil {
+		cache := h.Connector.CacheStats()
+		stats.Cache = &cache
+	}
+	if h.Responses != nil {
+		responses := h.Responses.Stats()
+		stats.ResponseCache = &responses
+	}
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    stats,
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDownloadStats(t *testing.T) {
+	m := NewRequestMetrics()
+
+	if stats := m.DownloadStats(); stats.SampleCount != 0 || stats.P99MS != 0 {
+		t.Errorf("DownloadStats() before any download = %+v, want zero values", stats)
+	}
+
+	// 1ms..100ms, each with 10 trades
+	for i := 1; i <= 100; i++ {
+		m.RecordDownloadSuccess(time.Duration(i)*time.Millisecond, 10)
+	}
+
+	stats := m.DownloadStats()
+	if stats.SampleCount != 100 {
+		t.Errorf("SampleCount = %d, want 100", stats.SampleCount)
+	}
+	if stats.P50MS != 50 || stats.P90MS != 90 || stats.P99MS != 99 {
+		t.Errorf("percentiles = %v/%v/%v, want 50/90/99", stats.P50MS, stats.P90MS, stats.P99MS)
+	}
+	if stats.AverageTradesPerRequest != 10 {
+		t.Errorf("AverageTradesPerRequest = %v, want 10", stats.AverageTradesPerRequest)
+	}
+}
+
+func TestDownloadStats_SlidingWindow(t *testing.T) {
+	var m RequestMetrics
+
+	// Fill the window with slow downloads, then replace all of them with fast ones
+	for i := 0; i < statsWindowSize; i++ {
+		m.RecordDownloadSuccess(time.Second, 1)
+	}
+	for i := 0; i < statsWindowSize; i++ {
+		m.RecordDownloadSuccess(time.Millisecond, 3)
+	}
+
+	stats := m.DownloadStats()
+	if stats.SampleCount != statsWindowSize {
+		t.Errorf("SampleCount = %d, want %d", stats.SampleCount, statsWindowSize)
+	}
+	if stats.P99MS != 1 {
+		t.Errorf("P99MS = %v, want 1 after the slow downloads left the window", stats.P99MS)
+	}
+	if stats.AverageTradesPerRequest != 3 {
+		t.Errorf("AverageTradesPerRequest = %v, want 3", stats.AverageTradesPerRequest)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTimeout extracts the timeout query parameter, in seconds, and returns the
+// timeout to use for the request. Without the parameter it returns defaultTimeout.
+// Values above maxTimeout are clamped rather than rejected; a zero maxTimeout
+// caps overrides at defaultTimeout.
+func parseTimeout(r *http.Request, defaultTimeout, maxTimeout time.Duration) (time.Duration, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("timeout"))
+	if raw == "" {
+		return defaultTimeout, nil
+	}
+
+	seconds, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(seconds) || seconds <= 0 || math.IsInf(seconds, 0) {
+		return 0, fmt.Errorf("invalid timeout: %s (must be a positive number of seconds)", raw)
+	}
+
+	if maxTimeout <= 0 {
+		maxTimeout = defaultTimeout
+	}
+	if seconds > maxTimeout.Seconds() {
+		Logger(r.Context()).Warn("requested timeout clamped", "requested_seconds", seconds, "max_timeout", maxTimeout)
+		return maxTimeout, nil
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		maxTimeout time.Duration
+		want       time.Duration
+		wantErr    bool
+	}{
+		{"default", "", time.Minute, 30 * time.Second, false},
+		{"shorter", "timeout=5", time.Minute, 5 * time.Second, false},
+		{"longer within max", "timeout=45", time.Minute, 45 * time.Second, false},
+		{"fractional seconds", "timeout=1.5", time.Minute, 1500 * time.Millisecond, false},
+		{"clamped to max", "timeout=600", time.Minute, time.Minute, false},
+		{"zero max caps at default", "timeout=600", 0, 30 * time.Second, false},
+		{"zero", "timeout=0", time.Minute, 0, true},
+		{"negative", "timeout=-5", time.Minute, 0, true},
+		{"not a number", "timeout=soon", time.Minute, 0, true},
+		{"nan", "timeout=NaN", time.Minute, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/download?"+tt.query, nil)
+			got, err := parseTimeout(req, 30*time.Second, tt.maxTimeout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// parseTradeFilter extracts the side and min_quote query parameters as a single
+// filter keeping the trades both allow. It returns nil when neither is set.
+func parseTradeFilter(r *http.Request) (binancevisionconnector.TradeFilter, error) {
+	side, err := parseTradeSide(r)
+	if err != nil {
+		return nil, err
+	}
+	minQuote, err := parseMinQuote(r)
+	if err != nil {
+		return nil, err
+	}
+	return binancevisionconnector.AllOf(side, minQuote), nil
+}
+
+// parseTradeSide extracts the side query parameter, which keeps only buyer-maker
+// (maker) or buyer-taker (taker) trades. It returns nil when side is not set.
+func parseTradeSide(r *http.Request) (binancevisionconnector.TradeFilter, error) {
+	switch raw := strings.TrimSpace(r.URL.Query().Get("side")); raw {
+	case "":
+		return nil, nil
+	case "maker":
+		return binancevisionconnector.MakerTrades, nil
+	case "taker":
+		return binancevisionconnector.TakerTrades, nil
+	default:
+		return nil, fmt.Errorf("invalid side: %s (must be maker or taker)", raw)
+	}
+}
+
+// parseMinQuote extracts the min_quote query parameter, which keeps only trades with
+// a quote quantity of at least that amount. It returns nil when min_quote is not set.
+func parseMinQuote(r *http.Request) (binancevisionconnector.TradeFilter, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("min_quote"))
+	if raw == "" {
+		return nil, nil
+	}
+	threshold, err := strconv.ParseFloat(raw, 64)
+	if err != nil || threshold < 0 || math.IsInf(threshold, 0) || math.IsNaN(threshold) {
+		return nil, fmt.Errorf("invalid min_quote: %s (must be a non-negative number)", raw)
+	}
+	return binancevisionconnector.MinQuoteQuantity(threshold), nil
+}
+
+// parseTradeIDRange extracts the FROM_ID and TO_ID query parameters, which keep only
+// trades with IDs in that inclusive range. Either bound may be left out. It returns
+// false when neither is set.
+func parseTradeIDRange(r *http.Request) (binancevisionconnector.TradeIDRange, bool, error) {
+	ids := binancevisionconnector.TradeIDRange{From: math.MinInt64, To: math.MaxInt64}
+	set := false
+	for _, bound := range []struct {
+		name  string
+		value *int64
+	}{{"FROM_ID", &ids.From}, {"TO_ID", &ids.To}} {
+		raw := strings.TrimSpace(r.URL.Query().Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return ids, false, fmt.Errorf("invalid %s: %s (must be an integer trade ID)", bound.name, raw)
+		}
+		*bound.value = parsed
+		set = true
+	}
+	if ids.From > ids.To {
+		return ids, false, fmt.Errorf("invalid trade ID range: FROM_ID %d is after TO_ID %d", ids.From, ids.To)
+	}
+	return ids, set, nil
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+func TestParseTradeFilter(t *testing.T) {
+	maker := binancevisionconnector.Trade{IsBuyerMaker: true, QuoteQuantity: 500}
+	taker := binancevisionconnector.Trade{IsBuyerMaker: false, QuoteQuantity: 20000}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantNil   bool
+		wantMaker bool
+		wantTaker bool
+		wantErr   bool
+	}{
+		{"not set", "", true, false, false, false},
+		{"side", "side=maker", false, true, false, false},
+		{"min quote", "min_quote=10000", false, false, true, false},
+		{"min quote is inclusive", "min_quote=500", false, true, true, false},
+		{"side and min quote", "side=maker&min_quote=10000", false, false, false, false},
+		{"negative min quote", "min_quote=-1", false, false, false, true},
+		{"non-numeric min quote", "min_quote=lots", false, false, false, true},
+		{"infinite min quote", "min_quote=Inf", false, false, false, true},
+		{"invalid side", "side=buy&min_quote=1", false, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep, err := parseTradeFilter(httptest.NewRequest("GET", "/download?"+tt.query, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTradeFilter(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if (keep == nil) != tt.wantNil {
+				t.Fatalf("parseTradeFilter(%q) nil = %v, want %v", tt.query, keep == nil, tt.wantNil)
+			}
+			if keep != nil && (keep(&maker) != tt.wantMaker || keep(&taker) != tt.wantTaker) {
+				t.Errorf("parseTradeFilter(%q) keeps maker %v, taker %v, want %v, %v",
+					tt.query, keep(&maker), keep(&taker), tt.wantMaker, tt.wantTaker)
+			}
+		})
+	}
+}
+
+func TestParseTradeIDRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    binancevisionconnector.TradeIDRange
+		wantSet bool
+		wantErr bool
+	}{
+		{"not set", "", binancevisionconnector.TradeIDRange{From: math.MinInt64, To: math.MaxInt64}, false, false},
+		{"both bounds", "FROM_ID=10&TO_ID=20", binancevisionconnector.TradeIDRange{From: 10, To: 20}, true, false},
+		{"from only", "FROM_ID=10", binancevisionconnector.TradeIDRange{From: 10, To: math.MaxInt64}, true, false},
+		{"to only", "TO_ID=20", binancevisionconnector.TradeIDRange{From: math.MinInt64, To: 20}, true, false},
+		{"single trade", "FROM_ID=15&TO_ID=15", binancevisionconnector.TradeIDRange{From: 15, To: 15}, true, false},
+		{"reversed", "FROM_ID=20&TO_ID=10", binancevisionconnector.TradeIDRange{}, false, true},
+		{"not an integer", "FROM_ID=abc", binancevisionconnector.TradeIDRange{}, false, true},
+		{"out of int64 range", "TO_ID=9223372036854775808", binancevisionconnector.TradeIDRange{}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, set, err := parseTradeIDRange(httptest.NewRequest("GET", "/download?"+tt.query, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTradeIDRange(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if err == nil && (got != tt.want || set != tt.wantSet) {
+				t.Errorf("parseTradeIDRange(%q) = %+v, %v, want %+v, %v", tt.query, got, set, tt.want, tt.wantSet)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestValidateSymbol(t *testing.T) {
@@ -13,14 +15,16 @@ func TestValidateSymbol(t *testing.T) {
 		{"valid symbol", "AIUSDT", false},
 		{"valid symbol with numbers", "BTC123", false},
 		{"empty symbol", "", true},
-		{"lowercase symbol", "aiusdt", true},
+		{"lowercase symbol", "aiusdt", false},
+		{"mixed case symbol", "AiUsdt", false},
+		{"lowercase symbol with special chars", "ai-usdt", true},
 		{"symbol with special chars", "AI-USDT", true},
 		{"symbol with spaces", "AI USDT", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateSymbol(tt.symbol)
+			err := validateSymbol(tt.symbol, SymbolAccess{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("validateSymbol(%q) error = %v, wantErr %v", tt.symbol, err, tt.wantErr)
 			}
@@ -29,6 +33,9 @@ func TestValidateSymbol(t *testing.T) {
 }
 
 func TestValidateDate(t *testing.T) {
+	today := time.Now().UTC()
+	yesterday := today.AddDate(0, 0, -1)
+
 	tests := []struct {
 		name    string
 		year    string
@@ -46,6 +53,10 @@ func TestValidateDate(t *testing.T) {
 		{"invalid day zero", "2025", "12", "0", true},
 		{"invalid date Feb 30", "2025", "02", "30", true},
 		{"invalid date Feb 29 non-leap", "2025", "02", "29", true},
+		{"valid date Feb 29 leap", "2024", "02", "29", false},
+		{"valid date yesterday", yesterday.Format("2006"), yesterday.Format("01"), yesterday.Format("02"), false},
+		{"invalid date today", today.Format("2006"), today.Format("01"), today.Format("02"), true},
+		{"invalid date in future", "2100", "12", "31", true},
 	}
 
 	for _, tt := range tests {
@@ -58,6 +69,33 @@ func TestValidateDate(t *testing.T) {
 	}
 }
 
+func TestValidateMinDate(t *testing.T) {
+	minDate := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		year    string
+		month   string
+		day     string
+		minDate time.Time
+		wantErr bool
+	}{
+		{"after minimum", "2025", "12", "28", minDate, false},
+		{"on minimum", "2017", "1", "1", minDate, false},
+		{"before minimum", "2016", "12", "31", minDate, true},
+		{"no minimum", "2000", "01", "01", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMinDate(tt.year, tt.month, tt.day, tt.minDate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMinDate(%q, %q, %q) error = %v, wantErr %v", tt.year, tt.month, tt.day, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestFormatDate(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -81,3 +119,67 @@ func TestFormatDate(t *testing.T) {
 	}
 }
 
+func TestParseKlineInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		want     string
+		wantCode string
+	}{
+		{"minute interval", "INTERVAL=1m", "1m", ""},
+		{"day interval", "INTERVAL=1d", "1d", ""},
+		{"missing", "", "", CodeMissingParameter},
+		{"unsupported", "INTERVAL=7m", "", CodeInvalidParameter},
+		{"wrong case", "INTERVAL=1H", "", CodeInvalidParameter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseKlineInterval(httptest.NewRequest("GET", "/download/markpriceklines?"+tt.query, nil))
+			if tt.wantCode != "" {
+				if err == nil || validationErrorCode(err) != tt.wantCode {
+					t.Errorf("parseKlineInterval(%q) error = %v, want code %s", tt.query, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseKlineInterval(%q) = %q, %v, want %q", tt.query, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateParams_Date(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantDate string
+		wantCode string
+	}{
+		{"date param", "SYMBOL=BTCUSDT&DATE=2025-01-28", "2025-01-28", ""},
+		{"date wins over split params", "SYMBOL=BTCUSDT&DATE=2025-01-28&YYYY=2024&MM=3&DD=4", "2025-01-28", ""},
+		{"date fills in missing split params", "SYMBOL=BTCUSDT&DATE=2025-01-28&YYYY=2024", "2025-01-28", ""},
+		{"split params", "SYMBOL=BTCUSDT&YYYY=2025&MM=1&DD=28", "2025-01-28", ""},
+		{"unpadded date", "SYMBOL=BTCUSDT&DATE=2025-1-28", "", CodeInvalidDate},
+		{"impossible date", "SYMBOL=BTCUSDT&DATE=2025-02-30", "", CodeInvalidDate},
+		{"future date", "SYMBOL=BTCUSDT&DATE=2099-01-01", "", CodeInvalidDate},
+		{"before min date", "SYMBOL=BTCUSDT&DATE=2019-12-31", "", CodeInvalidDate},
+		{"missing symbol", "DATE=2025-01-28", "", CodeMissingParameter},
+	}
+
+	minDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _, err := parseDateParams(httptest.NewRequest("GET", "/download?"+tt.query, nil), minDate)
+			if tt.wantCode != "" {
+				if err == nil || validationErrorCode(err) != tt.wantCode {
+					t.Errorf("parseDateParams(%q) error = %v, want code %s", tt.query, err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || params.Date() != tt.wantDate {
+				t.Errorf("parseDateParams(%q) = %s, %v, want %s", tt.query, params.Date(), err, tt.wantDate)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HandleVerify returns a data quality report of a day's trade archive: parsed and
+// skipped row counts, its size and whether it matches the published checksum. A
+// checksum mismatch is a successful report with checksum_ok false, so clients can
+// tell it apart from a failed download.
+func (h *DownloadHandler) HandleVerify(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	result, err := h.Connector.VerifyTrades(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error("trade verification failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and verify trades: %v", err),
+			ErrorCode: downloadErrorCode(err),
+			SourceURL: h.Connector.TradesURL(params.Symbol, params.Year, params.Month, params.Day),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	h.Metrics.RecordDownloadSuccess(time.Since(start), result.ParsedCount)
+	logger.Info("trade verification succeeded", "duration", time.Since(start), "parsed_count", result.ParsedCount,
+		"skipped_count", result.SkippedCount, "checksum_ok", result.ChecksumOK)
+
+	checksum := "checksum matches"
+	switch {
+	case result.ChecksumError != "":
+		checksum = "checksum unavailable"
+	case !result.ChecksumOK:
+		checksum = "checksum mismatch"
+	}
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Verified %s on %s: %d trades parsed, %d rows skipped, %s", params.Symbol, result.Date, result.ParsedCount, result.SkippedCount, checksum),
+		Data:    result,
+	})
+}
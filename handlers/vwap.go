@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseIncludeVWAP extracts the vwap query parameter, which adds the day's
+// volume-weighted average price to a /download response
+func parseIncludeVWAP(r *http.Request) (bool, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("vwap"))
+	if raw == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid vwap: %s (must be true or false)", raw)
+	}
+	return include, nil
+}
+
+// HandleVWAP returns the volume-weighted average price of a day's trades. The
+// archive is parsed while it streams and no trades are sent, so this is much
+// cheaper for the client than downloading the day to compute it.
+func (h *DownloadHandler) HandleVWAP(w http.ResponseWriter, r *http.Request) {
+	params, ctx, cancel, ok := h.beginDayRequest(w, r)
+	if !ok {
+		return
+	}
+	defer cancel()
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	result, err := h.Connector.ComputeVWAP(ctx, params.Symbol, params.Year, params.Month, params.Day)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error("vwap computation failed", "duration", time.Since(start), "error", err)
+		WriteJSONResponse(w, downloadErrorStatus(w, err), APIResponse{
+			Success:   false,
+			Error:     fmt.Sprintf("Failed to download and compute VWAP: %v", err),
+			ErrorCode: downloadErrorCode(err),
+			SourceURL: h.Connector.TradesURL(params.Symbol, params.Year, params.Month, params.Day),
+		})
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	h.Metrics.RecordDownloadSuccess(time.Since(start), result.TradeCount)
+	logger.Info("vwap computation succeeded", "duration", time.Since(start), "trade_count", result.TradeCount)
+
+	WriteJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("VWAP of %s on %s is %g over %d trades", params.Symbol, result.Date, result.VWAP, result.TradeCount),
+		Data:    result,
+	})
+}
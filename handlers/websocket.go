@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	binancevisionconnector "binance-vision-connector/binance-vision-connector"
+)
+
+// websocketGUID is the fixed GUID used to compute Sec-WebSocket-Accept (RFC 6455 section 1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// WebSocket close codes
+const (
+	wsCloseNormal        = 1000
+	wsCloseInternalError = 1011
+)
+
+// maxWSControlPayload is the largest payload allowed in a control frame
+const maxWSControlPayload = 125
+
+// wsConn is a minimal server side WebSocket connection. It only sends text
+// messages; incoming frames are read to answer pings and detect closure.
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	mu   sync.Mutex // Serializes frame writes
+}
+
+// isWebSocketUpgrade reports whether r asks to switch to the WebSocket protocol
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+// headerContainsToken reports whether a comma-separated header contains token (case-insensitive)
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgradeWebSocket performs the opening handshake and takes over the connection
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if !isWebSocketUpgrade(r) {
+		return nil, errors.New("not a WebSocket upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errors.New("unsupported WebSocket version (expected 13)")
+	}
+	key := strings.TrimSpace(r.Header.Get("Sec-WebSocket-Key"))
+	if key == "" {
+		return nil, errors.New("missing Sec-WebSocket-Key header")
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hijack connection: %w", err)
+	}
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to complete handshake: %w", err)
+	}
+
+	// The handshake is done, so clear any deadlines set by the HTTP server
+	conn.SetDeadline(time.Time{})
+
+	return &wsConn{conn: conn, rw: rw}, nil
+}
+
+// WriteJSON sends v as a single text message. The message is buffered; call Flush
+// to push buffered messages to the client.
+func (c *wsConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(wsOpText, data, false)
+}
+
+// Flush sends any buffered messages
+func (c *wsConn) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rw.Flush()
+}
+
+// Close sends a close frame with the given code and reason and closes the connection
+func (c *wsConn) Close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	if len(payload) > maxWSControlPayload {
+		payload = payload[:maxWSControlPayload]
+	}
+
+	err := c.writeFrame(wsOpClose, payload, true)
+	c.conn.Close()
+	return err
+}
+
+// writeFrame writes a single unfragmented, unmasked frame
+func (c *wsConn) writeFrame(opcode byte, payload []byte, flush bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := make([]byte, 0, 10)
+	header = append(header, 0x80|opcode) // FIN set
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	if flush {
+		return c.rw.Flush()
+	}
+	return nil
+}
+
+// readLoop reads client frames until the client closes the connection or it fails,
+// answering pings along the way. It returns when the connection is no longer usable.
+func (c *wsConn) readLoop() {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case wsOpClose:
+			return
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload, true); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readFrame reads one client frame and unmasks its payload
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	// Clients must mask their frames, and this endpoint never expects large messages
+	if !masked {
+		return 0, nil, errors.New("received unmasked client frame")
+	}
+	if length > maxWSControlPayload && opcode >= wsOpClose {
+		return 0, nil, errors.New("control frame too large")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	// Only control frames need their payload; data frames are discarded
+	if opcode < wsOpClose {
+		if _, err := io.CopyN(io.Discard, c.rw, int64(length)); err != nil {
+			return 0, nil, err
+		}
+		return opcode, nil, nil
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return opcode, payload, nil
+}
+
+// wsFlushInterval is the number of trade messages buffered before flushing to the client
+const wsFlushInterval = 500
+
+// WebSocketSummary is the final message sent on /ws/download after all trades
+type WebSocketSummary struct {
+	Type string `json:"type"` // Always "summary"
+	*binancevisionconnector.DownloadResult
+	Trades []binancevisionconnector.Trade `json:"-"` // Trades were already streamed
+}
+
+// WebSocketError is sent on /ws/download when the download fails after the upgrade
+type WebSocketError struct {
+	Type      string `json:"type"` // Always "error"
+	Error     string `json:"error"`
+	ErrorCode string `json:"error_code"`
+}
+
+// HandleWebSocket streams trades over a WebSocket as they are parsed. Each trade is
+// sent as its own JSON message, followed by a summary message and a normal close.
+// Parsing is cancelled if the client disconnects. The date may also be given as a
+// lowercase date parameter, as dashboards built for this endpoint send it.
+func (h *DownloadHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if query := r.URL.Query(); query.Get("DATE") == "" && query.Get("date") != "" {
+		query.Set("DATE", query.Get("date"))
+		r.URL.RawQuery = query.Encode()
+	}
+
+	params, err := parseDownloadParams(r, h.MinDate, h.Symbols)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, validationErrorStatus(err), APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	timeout, err := parseTimeout(r, h.Timeout, h.MaxTimeout)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		h.Metrics.RecordFailure()
+		WriteJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: validationErrorCode(err),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	// Stop writing once the timeout passes, even if the client stops reading
+	if deadline, ok := ctx.Deadline(); ok {
+		ws.conn.SetWriteDeadline(deadline)
+	}
+
+	// Cancel the download as soon as the client closes or drops the connection
+	go func() {
+		ws.readLoop()
+		cancel()
+	}()
+
+	logger := Logger(r.Context()).With("symbol", params.Symbol, "date", params.Date())
+	start := time.Now()
+
+	sent := 0
+	result, err := h.Connector.StreamTrades(ctx, params.Symbol, params.Year, params.Month, params.Day, func(trade binancevisionconnector.Trade) error {
+		if err := ws.WriteJSON(trade); err != nil {
+			return err
+		}
+		sent++
+		if sent%wsFlushInterval == 0 {
+			return ws.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		h.Metrics.RecordFailure()
+		logger.Error("trade stream failed", "duration", time.Since(start), "trades_sent", sent, "error", err)
+		ws.WriteJSON(WebSocketError{
+			Type:      "error",
+			Error:     fmt.Sprintf("Failed to download and parse trades: %v", err),
+			ErrorCode: downloadErrorCode(err),
+		})
+		ws.Close(wsCloseInternalError, "download failed")
+		return
+	}
+
+	h.Metrics.RecordSuccess()
+	h.Metrics.RecordDownloadSuccess(time.Since(start), result.TradeCount)
+	logger.Info("trade stream succeeded", "duration", time.Since(start), "trade_count", result.TradeCount)
+
+	ws.WriteJSON(WebSocketSummary{Type: "summary", DownloadResult: result})
+	ws.Close(wsCloseNormal, "")
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestWSConn returns a wsConn reading client frames from in and writing server
+// frames to out
+func newTestWSConn(in []byte, out *bytes.Buffer) *wsConn {
+	return &wsConn{rw: bufio.NewReadWriter(bufio.NewReader(bytes.NewReader(in)), bufio.NewWriter(out))}
+}
+
+// Frames from the examples in RFC 6455 section 5.7
+func TestWSConn_WriteFrame(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    []byte
+		wantHeader []byte
+	}{
+		{"single-frame unmasked text", []byte("Hello"), []byte{0x81, 0x05}},
+		{"largest 7-bit length", make([]byte, 125), []byte{0x81, 0x7D}},
+		{"256 bytes uses a 16-bit length", make([]byte, 256), []byte{0x81, 0x7E, 0x01, 0x00}},
+		{"largest 16-bit length", make([]byte, 65535), []byte{0x81, 0x7E, 0xFF, 0xFF}},
+		{"64KiB uses a 64-bit length", make([]byte, 65536), []byte{0x81, 0x7F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			c := newTestWSConn(nil, &out)
+			if err := c.writeFrame(wsOpText, tt.payload, true); err != nil {
+				t.Fatalf("writeFrame() error = %v", err)
+			}
+			want := append(append([]byte(nil), tt.wantHeader...), tt.payload...)
+			if !bytes.Equal(out.Bytes(), want) {
+				t.Errorf("writeFrame() wrote header % x, want % x", out.Bytes()[:min(out.Len(), 10)], tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestWSConn_ReadFrame(t *testing.T) {
+	// A masked "Hello" from RFC 6455 section 5.7, as a text and as a ping frame
+	maskedHello := []byte{0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58}
+
+	tests := []struct {
+		name        string
+		frame       []byte
+		wantOpcode  byte
+		wantPayload []byte
+		wantErr     bool
+	}{
+		{"masked text is discarded", append([]byte{0x81}, maskedHello...), wsOpText, nil, false},
+		{"masked ping is unmasked", append([]byte{0x89}, maskedHello...), wsOpPing, []byte("Hello"), false},
+		{"16-bit length", append([]byte{0x82, 0xFE, 0x01, 0x00, 0, 0, 0, 0}, make([]byte, 256)...), 0x2, nil, false},
+		{"unmasked client frame", []byte{0x81, 0x05, 'H', 'e', 'l', 'l', 'o'}, 0, nil, true},
+		{"oversized control frame", append([]byte{0x89, 0xFE, 0x00, 0x7E, 0, 0, 0, 0}, make([]byte, 126)...), 0, nil, true},
+		{"truncated frame", []byte{0x89, 0x85, 0x37}, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestWSConn(tt.frame, &bytes.Buffer{})
+			opcode, payload, err := c.readFrame()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("readFrame() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readFrame() error = %v", err)
+			}
+			if opcode != tt.wantOpcode || !bytes.Equal(payload, tt.wantPayload) {
+				t.Errorf("readFrame() = %#x %q, want %#x %q", opcode, payload, tt.wantOpcode, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestWSConn_ReadLoopAnswersPing(t *testing.T) {
+	frames := []byte{0x89, 0x85, 0x37, 0xfa, 0x21, 0x3d, 0x7f, 0x9f, 0x4d, 0x51, 0x58} // Ping "Hello"
+	frames = append(frames, 0x88, 0x82, 0, 0, 0, 0, 0x03, 0xE8)                        // Close 1000
+
+	var out bytes.Buffer
+	newTestWSConn(frames, &out).readLoop()
+
+	// An unmasked pong with the ping's payload, as in RFC 6455 section 5.7
+	want := []byte{0x8A, 0x05, 'H', 'e', 'l', 'l', 'o'}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("readLoop() wrote % x, want % x", out.Bytes(), want)
+	}
+}
+
+func TestWSConn_Close(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   int
+		reason string
+		want   []byte
+	}{
+		{"normal closure", wsCloseNormal, "", []byte{0x88, 0x02, 0x03, 0xE8}},
+		{"with reason", wsCloseInternalError, "failed", append([]byte{0x88, 0x08, 0x03, 0xF3}, "failed"...)},
+		{"reason truncated to the control frame limit", wsCloseNormal, strings.Repeat("x", 200), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			defer client.Close()
+			c := &wsConn{conn: server, rw: bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))}
+
+			received := make(chan []byte)
+			go func() {
+				data, _ := io.ReadAll(client)
+				received <- data
+			}()
+			c.Close(tt.code, tt.reason)
+
+			var got []byte
+			select {
+			case got = <-received:
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for the close frame")
+			}
+			if tt.want == nil {
+				if len(got) != 2+maxWSControlPayload || got[1] != maxWSControlPayload {
+					t.Errorf("Close() wrote a %d byte frame with length %d, want a %d byte payload", len(got), got[1], maxWSControlPayload)
+				}
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Close() wrote % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgradeWebSocket_Handshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		wantErr bool
+	}{
+		{"valid", map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ=="}, false},
+		{"not an upgrade", map[string]string{"Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ=="}, true},
+		{"wrong version", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8", "Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ=="}, true},
+		{"missing key", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted := make(chan error, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ws, err := upgradeWebSocket(w, r)
+				accepted <- err
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				ws.Close(wsCloseNormal, "")
+			}))
+			defer server.Close()
+
+			conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+			if err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+			req, _ := http.NewRequest("GET", server.URL+"/", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			req.Write(conn)
+
+			resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+			if err != nil {
+				t.Fatalf("Failed to read handshake response: %v", err)
+			}
+			resp.Body.Close()
+			if err := <-accepted; (err != nil) != tt.wantErr {
+				t.Fatalf("upgradeWebSocket() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if resp.StatusCode != http.StatusBadRequest {
+					t.Errorf("status = %d, want 400", resp.StatusCode)
+				}
+				return
+			}
+			// Accept value from the RFC 6455 section 1.3 example key
+			if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+				t.Errorf("handshake = %d with accept %q, want 101 with the RFC example accept", resp.StatusCode, resp.Header.Get("Sec-WebSocket-Accept"))
+			}
+		})
+	}
+}
@@ -3,9 +3,13 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,50 +21,248 @@ import (
 
 // Config holds application configuration
 type Config struct {
-	Port            string
-	Timeout         time.Duration
-	MaxConnsPerHost int
-	MaxIdleConns    int
+	Port             string
+	Timeout          time.Duration
+	MaxTimeout       time.Duration
+	MaxConnsPerHost  int
+	MaxIdleConns     int
+	MaxBatchSize     int
+	MaxExportDays    int
+	MaxBodySize      int
+	MaxResponseSize  int
+	MaxTradesPerFile int
+	MaxUncompressed  int64
+	CacheSize        int
+	ResponseCache    int
+	ResponseGzip     bool
+	BaseURL          string
+	ListingURL       string
+	Market           string
+	MonthlyFallback  bool
+	ProxyURL         string
+	ForceHTTP2       bool
+	PartialOK        bool
+	ParseConcurrency int
+	SortTrades       bool
+	SortBy           string
+	Deduplicate      bool
+	RateLimitRPS     float64
+	RateLimitBurst   int
+	MaxDownloads     int
+	MaxInFlight      int
+	ClientRPM        float64
+	ClientBurst      int
+	MinDate          time.Time
+	OutputDir        string
+	SpoolDir         string
+	DownloadRetries  int
+	CSVDelimiter     rune
+	LazyQuotes       bool
+	ErrorDetails     int
+	AllowedSymbols   []string
+	DeniedSymbols    []string
+	StrictSymbols    bool
+	QuoteAssets      []string
+	APIKeys          []string
+	LogLevel         slog.Level
+	LogFormat        string
+
+	SymbolRefreshInterval time.Duration // How often the /symbols index is rebuilt (0 = disabled)
+
+	// Upstream transport timeouts (0 = no limit)
+	ResponseHeaderTimeout time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ExpectContinueTimeout time.Duration
 }
 
 var (
-	config           Config
-	connector        *binancevisionconnector.Connector
-	downloadHandler  *handlers.DownloadHandler
-	healthHandler    *handlers.HealthHandler
-	requestMetrics   *handlers.RequestMetrics
+	config          Config
+	connector       *binancevisionconnector.Connector
+	symbolAccess    handlers.SymbolAccess
+	downloadHandler *handlers.DownloadHandler
+	batchHandler    *handlers.BatchHandler
+	prefetchHandler *handlers.PrefetchHandler
+	healthHandler   *handlers.HealthHandler
+	statsHandler    *handlers.StatsHandler
+	readyHandler    *handlers.ReadyHandler
+	requestMetrics  *handlers.RequestMetrics
 )
 
 func init() {
 	// Load .env file if it exists
 	godotenv.Load()
 
+	// Structured logs; the standard log package is routed through the same handler
+	logLevel := getEnvLogLevel("LOG_LEVEL", slog.LevelInfo)
+	logFormat := getEnv("LOG_FORMAT", "json")
+	if logFormat != "json" && logFormat != "text" {
+		log.Printf("Invalid value for LOG_FORMAT: %q, using default json", logFormat)
+		logFormat = "json"
+	}
+	slog.SetDefault(newLogger(logLevel, logFormat))
+
 	config = Config{
-		Port:            getEnv("PORT", "8080"),
-		Timeout:         30 * time.Second,
-		MaxConnsPerHost: 10,
-		MaxIdleConns:    100,
+		Port:             getEnv("PORT", "8080"),
+		Timeout:          30 * time.Second,
+		MaxTimeout:       time.Duration(getEnvInt("MAX_REQUEST_TIMEOUT", 300)) * time.Second,
+		MaxConnsPerHost:  10,
+		MaxIdleConns:     100,
+		MaxBatchSize:     getEnvInt("MAX_BATCH_SIZE", 50),
+		MaxExportDays:    getEnvInt("MAX_EXPORT_DAYS", 366),
+		MaxBodySize:      getEnvInt("MAX_BODY_SIZE", 1<<20),
+		MaxResponseSize:  getEnvInt("MAX_RESPONSE_SIZE", 0),
+		MaxTradesPerFile: getEnvInt("MAX_TRADES_PER_FILE", 0),
+		MaxUncompressed:  getEnvInt64("MAX_UNCOMPRESSED_SIZE", 4<<30),
+		CacheSize:        getEnvInt("CACHE_SIZE", 0),
+		ResponseCache:    getEnvInt("RESPONSE_CACHE_SIZE", 0),
+		ResponseGzip:     getEnvBool("RESPONSE_CACHE_GZIP", false),
+		BaseURL:          getEnv("BASE_URL", binancevisionconnector.DefaultBaseURL),
+		ListingURL:       getEnv("LISTING_URL", binancevisionconnector.DefaultListingURL),
+		Market:           getEnv("MARKET", string(binancevisionconnector.MarketSpot)),
+		MonthlyFallback:  getEnvBool("FALLBACK_TO_MONTHLY", false),
+		ProxyURL:         getEnv("PROXY_URL", ""),
+		ForceHTTP2:       getEnvBool("FORCE_HTTP2", false),
+		PartialOK:        getEnvBool("PARTIAL_OK", false),
+		ParseConcurrency: getEnvInt("PARSE_CONCURRENCY", runtime.NumCPU()),
+		SortTrades:       getEnvBool("SORT_TRADES", false),
+		SortBy:           getEnv("SORT_BY", string(binancevisionconnector.SortByTradeID)),
+		Deduplicate:      getEnvBool("DEDUPLICATE", false),
+		RateLimitRPS:     getEnvFloat("RATE_LIMIT_RPS", 0),
+		RateLimitBurst:   getEnvInt("RATE_LIMIT_BURST", 1),
+		MaxDownloads:     getEnvInt("MAX_CONCURRENT_DOWNLOADS", 0),
+		MaxInFlight:      getEnvInt("MAX_IN_FLIGHT_REQUESTS", 0),
+		ClientRPM:        getEnvFloat("CLIENT_RATE_LIMIT_RPM", 0),
+		ClientBurst:      getEnvInt("CLIENT_RATE_LIMIT_BURST", 10),
+		MinDate:          getEnvDate("MIN_DATE", "2017-01-01"),
+		OutputDir:        getEnv("OUTPUT_DIR", ""),
+		SpoolDir:         getEnv("SPOOL_DIR", ""),
+		DownloadRetries:  getEnvInt("DOWNLOAD_RETRIES", 2),
+		CSVDelimiter:     getEnvRune("CSV_DELIMITER", ','),
+		LazyQuotes:       getEnvBool("LAZY_QUOTES", false),
+		ErrorDetails:     getEnvInt("PARSE_ERROR_DETAILS", 0),
+		AllowedSymbols:   getEnvList("ALLOWED_SYMBOLS"),
+		DeniedSymbols:    getEnvList("DENIED_SYMBOLS"),
+		StrictSymbols:    getEnvBool("STRICT_SYMBOLS", false),
+		QuoteAssets:      getEnvList("QUOTE_ASSETS"),
+		APIKeys:          getEnvList("API_KEYS"),
+		LogLevel:         logLevel,
+		LogFormat:        logFormat,
+
+		SymbolRefreshInterval: time.Duration(getEnvInt("SYMBOL_REFRESH_INTERVAL", 0)) * time.Second,
+
+		ResponseHeaderTimeout: time.Duration(getEnvInt("RESPONSE_HEADER_TIMEOUT", 0)) * time.Second,
+		TLSHandshakeTimeout:   time.Duration(getEnvInt("TLS_HANDSHAKE_TIMEOUT", 0)) * time.Second,
+		ExpectContinueTimeout: time.Duration(getEnvInt("EXPECT_CONTINUE_TIMEOUT", 0)) * time.Second,
 	}
 
 	// Initialize connector with optimized configuration
 	connectorConfig := binancevisionconnector.DefaultConfig()
 	connectorConfig.Timeout = config.Timeout
+	if config.MaxTimeout > config.Timeout {
+		// The per-request context enforces the actual timeout, so the client
+		// must not cut off requests that asked for longer with ?timeout=
+		connectorConfig.Timeout = config.MaxTimeout
+	}
 	connectorConfig.MaxConnsPerHost = config.MaxConnsPerHost
 	connectorConfig.MaxIdleConns = config.MaxIdleConns
-	connector = binancevisionconnector.NewConnectorWithConfig(connectorConfig)
+	connectorConfig.BaseURL = config.BaseURL
+	connectorConfig.ListingURL = config.ListingURL
+	connectorConfig.Market = binancevisionconnector.Market(config.Market)
+	connectorConfig.MaxResponseSize = int64(config.MaxResponseSize)
+	connectorConfig.MaxTradesPerFile = config.MaxTradesPerFile
+	connectorConfig.MaxUncompressedSize = config.MaxUncompressed
+	connectorConfig.PartialOK = config.PartialOK
+	connectorConfig.FallbackToMonthly = config.MonthlyFallback
+	connectorConfig.ParseConcurrency = config.ParseConcurrency
+	connectorConfig.SortTrades = config.SortTrades
+	connectorConfig.SortBy = binancevisionconnector.TradeSortKey(config.SortBy)
+	connectorConfig.Deduplicate = config.Deduplicate
+	connectorConfig.CacheSize = config.CacheSize
+	connectorConfig.SpoolDir = config.SpoolDir
+	connectorConfig.DownloadRetries = config.DownloadRetries
+	connectorConfig.CSVDelimiter = config.CSVDelimiter
+	connectorConfig.LazyQuotes = config.LazyQuotes
+	connectorConfig.ParseErrorDetails = config.ErrorDetails
+	connectorConfig.RequestsPerSecond = config.RateLimitRPS
+	connectorConfig.Burst = config.RateLimitBurst
+	connectorConfig.MaxConcurrentDownloads = config.MaxDownloads
+	connectorConfig.ProxyURL = config.ProxyURL
+	connectorConfig.ForceHTTP2 = config.ForceHTTP2
+	connectorConfig.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+	connectorConfig.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	connectorConfig.ExpectContinueTimeout = config.ExpectContinueTimeout
 
 	// Initialize request metrics
-	requestMetrics = &handlers.RequestMetrics{}
+	requestMetrics = handlers.NewRequestMetrics()
+	connectorConfig.OnArchiveDownloaded = requestMetrics.RecordBytesDownloaded
+
+	var err error
+	connector, err = binancevisionconnector.NewConnectorWithConfig(connectorConfig)
+	if err != nil {
+		log.Fatalf("Invalid connector configuration: %v", err)
+	}
 
 	// Initialize handlers
+	symbolAccess = handlers.NewSymbolAccess(config.AllowedSymbols, config.DeniedSymbols).
+		WithMarket(connector.Config().Market)
+	if config.StrictSymbols {
+		if len(config.QuoteAssets) == 0 {
+			config.QuoteAssets = handlers.DefaultQuoteAssetsFor(connector.Config().Market)
+		}
+		symbolAccess = symbolAccess.WithQuoteAssets(config.QuoteAssets)
+	}
+
+	responseCache := handlers.NewResponseCache(int64(config.ResponseCache), config.ResponseGzip)
 	downloadHandler = &handlers.DownloadHandler{
 		Connector: connector,
 		Timeout:   config.Timeout,
 		Metrics:   requestMetrics,
+		MinDate:   config.MinDate,
+		OutputDir: config.OutputDir,
+
+		MaxTimeout:  config.MaxTimeout,
+		Concurrency: config.MaxConnsPerHost,
+		Symbols:     symbolAccess,
+		Responses:   responseCache,
+
+		MaxExportDays: config.MaxExportDays,
+		MaxDays:       config.MaxBatchSize,
+	}
+
+	batchHandler = &handlers.BatchHandler{
+		Connector:    connector,
+		Timeout:      config.Timeout,
+		Metrics:      requestMetrics,
+		MaxBatchSize: config.MaxBatchSize,
+		Concurrency:  config.MaxConnsPerHost,
+		MinDate:      config.MinDate,
+		Symbols:      symbolAccess,
 	}
 
 	healthHandler = &handlers.HealthHandler{
-		Metrics: requestMetrics,
+		Metrics:   requestMetrics,
+		Connector: connector,
+	}
+
+	prefetchHandler = &handlers.PrefetchHandler{
+		Connector:    connector,
+		Timeout:      config.Timeout,
+		Metrics:      requestMetrics,
+		MaxBatchSize: config.MaxBatchSize,
+		Concurrency:  config.MaxConnsPerHost,
+		MinDate:      config.MinDate,
+		Symbols:      symbolAccess,
+	}
+
+	statsHandler = &handlers.StatsHandler{
+		Metrics:   requestMetrics,
+		Connector: connector,
+		Responses: responseCache,
+	}
+
+	readyHandler = &handlers.ReadyHandler{
+		Connector: connector,
+		Timeout:   5 * time.Second,
 	}
 }
 
@@ -71,49 +273,324 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// requestTrackingMiddleware tracks request metrics
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid value for %s: %q, using default %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvInt64 is getEnvInt for sizes that may not fit in an int on 32-bit platforms
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid value for %s: %q, using default %d", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid value for %s: %q, using default %v", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid value for %s: %q, using default %v", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvDate parses a YYYY-MM-DD date from the environment
+func getEnvDate(key, defaultValue string) time.Time {
+	value := getEnv(key, defaultValue)
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		log.Printf("Invalid value for %s: %q, using default %s", key, value, defaultValue)
+		date, _ = time.Parse("2006-01-02", defaultValue)
+	}
+	return date
+}
+
+// getEnvRune parses a single character from the environment; `\t` stands for a tab
+func getEnvRune(key string, defaultValue rune) rune {
+	value := os.Getenv(key)
+	if value == `\t` {
+		return '\t'
+	}
+	if value != "" {
+		if runes := []rune(value); len(runes) == 1 {
+			return runes[0]
+		}
+		log.Printf("Invalid value for %s: %q, using default %q", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvLogLevel parses a log level name (debug, info, warn or error) from the environment
+func getEnvLogLevel(key string, defaultValue slog.Level) slog.Level {
+	if value := os.Getenv(key); value != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err == nil {
+			return level
+		}
+		log.Printf("Invalid value for %s: %q, using default %s", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// newLogger creates a logger writing to stderr at level, as JSON lines or, for
+// format "text", as key=value lines
+func newLogger(level slog.Level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	if format == "text" {
+		return slog.New(slog.NewTextHandler(os.Stderr, options))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stderr, options))
+}
+
+// getEnvList parses a comma-separated list from the environment, dropping blank entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// requestTrackingMiddleware tracks request metrics and assigns each request an ID,
+// which is echoed in the X-Request-ID header and attached to every log line
 func requestTrackingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(handlers.RequestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = handlers.NewRequestID()
+		}
+		w.Header().Set(handlers.RequestIDHeader, requestID)
+		r = r.WithContext(handlers.WithRequestID(r.Context(), requestID))
+		// Debug logs from the connector, such as skipped CSV rows, carry the request ID too
+		r = r.WithContext(binancevisionconnector.ContextWithLogger(r.Context(), handlers.Logger(r.Context())))
+
 		requestMetrics.Mu.Lock()
 		requestMetrics.TotalRequests++
 		requestMetrics.ActiveRequests++
 		requestMetrics.Mu.Unlock()
 
+		logger := handlers.Logger(r.Context())
+		logger.Info("request started", "method", r.Method, "path", r.URL.Path, "query", r.URL.RawQuery)
+		start := time.Now()
+		recorder := &handlers.StatusRecorder{ResponseWriter: w, Status: http.StatusOK}
+
 		defer func() {
 			requestMetrics.Mu.Lock()
 			requestMetrics.ActiveRequests--
 			requestMetrics.Mu.Unlock()
+
+			logger.Info("request completed", "method", r.Method, "path", r.URL.Path,
+				"status", recorder.Status, "duration", time.Since(start))
 		}()
 
-		next(w, r)
+		next(recorder, r)
 	}
 }
 
 func main() {
+	// Run a single CLI command instead of the server when one is given
+	if len(os.Args) > 1 && os.Args[1] == "download" {
+		os.Exit(runDownloadCommand(connector, symbolAccess, os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Setup HTTP server with optimized settings for high load
+	// Data endpoints require an API key when API_KEYS is set and are rate limited per
+	// client; /health, /stats and /ready stay public for probes and monitoring.
+	// Endpoints that download archives share a cap on requests in flight.
 	mux := http.NewServeMux()
-	mux.HandleFunc("/download", requestTrackingMiddleware(downloadHandler.Handle))
+	apiKeys := handlers.NewAPIKeys(config.APIKeys)
+	clientLimiter := handlers.NewClientRateLimiter(config.ClientRPM, config.ClientBurst)
+	protect := func(next http.HandlerFunc) http.HandlerFunc {
+		return requestTrackingMiddleware(handlers.RequireAPIKey(handlers.LimitClientRate(next, clientLimiter, apiKeys), apiKeys))
+	}
+	get := func(next http.HandlerFunc) http.HandlerFunc {
+		return protect(handlers.MethodGuard(next, http.MethodGet))
+	}
+	post := func(next http.HandlerFunc) http.HandlerFunc {
+		return protect(handlers.MethodGuard(handlers.LimitBody(next, int64(config.MaxBodySize)), http.MethodPost))
+	}
+	inFlightLimiter := handlers.NewInFlightLimiter(config.MaxInFlight)
+	shed := func(next http.HandlerFunc) http.HandlerFunc {
+		return handlers.LimitInFlight(next, inFlightLimiter)
+	}
+	mux.HandleFunc("/download", get(shed(downloadHandler.Handle)))
+	mux.HandleFunc("/download/bookticker", get(shed(downloadHandler.HandleBookTicker)))
+	mux.HandleFunc("/download/bookdepth", get(shed(downloadHandler.HandleBookDepth)))
+	mux.HandleFunc("/download/fundingrate", get(shed(downloadHandler.HandleFundingRate)))
+	mux.HandleFunc("/download/liquidations", get(shed(downloadHandler.HandleLiquidations)))
+	mux.HandleFunc("/download/markpriceklines", get(shed(downloadHandler.HandleMarkPriceKlines)))
+	mux.HandleFunc("/download/indexpriceklines", get(shed(downloadHandler.HandleIndexPriceKlines)))
+	mux.HandleFunc("/download/premiumindexklines", get(shed(downloadHandler.HandlePremiumIndexKlines)))
+	mux.HandleFunc("/download/days", get(shed(downloadHandler.HandleDays)))
+	mux.HandleFunc("/download/batch", post(shed(batchHandler.Handle)))
+	mux.HandleFunc("/count", get(shed(downloadHandler.HandleCount)))
+	mux.HandleFunc("/vwap", get(shed(downloadHandler.HandleVWAP)))
+	mux.HandleFunc("/verify", get(shed(downloadHandler.HandleVerify)))
+	mux.HandleFunc("/export", get(shed(downloadHandler.HandleExport)))
+	mux.HandleFunc("/prefetch", post(prefetchHandler.Handle))
+	mux.HandleFunc("/prefetch/{id}", get(prefetchHandler.HandleStatus))
+	mux.HandleFunc("/exists", get(downloadHandler.HandleExists))
+	mux.HandleFunc("/dates", get(downloadHandler.HandleDates))
+	mux.HandleFunc("/symbols", get(downloadHandler.HandleSymbols))
+	mux.HandleFunc("/ws/download", get(shed(downloadHandler.HandleWebSocket)))
+	mux.HandleFunc("/download/stream", get(shed(downloadHandler.HandleDownloadStream)))
 	mux.HandleFunc("/health", healthHandler.Handle)
+	mux.HandleFunc("/stats", statsHandler.Handle)
+	mux.HandleFunc("/ready", readyHandler.Handle)
 
 	server := &http.Server{
 		Addr:           ":" + config.Port,
 		Handler:        mux,
 		ReadTimeout:    30 * time.Second,
-		WriteTimeout:  60 * time.Second, // Increased for large JSON responses
+		WriteTimeout:  max(60*time.Second, config.MaxTimeout+30*time.Second), // Room for large JSON responses and long ?timeout= requests
 		IdleTimeout:   120 * time.Second,
 		MaxHeaderBytes: 1 << 20, // 1MB
 	}
 
+	// Keep the /symbols index fresh in the background until shutdown
+	refreshCtx, stopRefresh := context.WithCancel(context.Background())
+	defer stopRefresh()
+	if config.SymbolRefreshInterval > 0 {
+		go connector.RunSymbolRefresh(refreshCtx, config.SymbolRefreshInterval)
+	}
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server starting on port %s", config.Port)
 		log.Printf("Configuration:")
 		log.Printf("  Timeout: %v", config.Timeout)
+		log.Printf("  Max Request Timeout: %v", config.MaxTimeout)
 		log.Printf("  Max Connections Per Host: %d", config.MaxConnsPerHost)
 		log.Printf("  Max Idle Connections: %d", config.MaxIdleConns)
+		log.Printf("  Max Batch Size: %d", config.MaxBatchSize)
+		log.Printf("  Max Export Days: %d", config.MaxExportDays)
+		log.Printf("  Max Request Body Size: %d bytes", config.MaxBodySize)
+		log.Printf("  Log Level: %s (%s)", config.LogLevel, config.LogFormat)
+		if config.MaxResponseSize > 0 {
+			log.Printf("  Max Response Size: %d bytes", config.MaxResponseSize)
+		}
+		if config.MaxUncompressed > 0 {
+			log.Printf("  Max Uncompressed Size: %d bytes", config.MaxUncompressed)
+		}
+		if config.MaxTradesPerFile > 0 {
+			log.Printf("  Max Trades Per File: %d", config.MaxTradesPerFile)
+		}
+		log.Printf("  Base URL: %s", config.BaseURL)
+		log.Printf("  Listing URL: %s", config.ListingURL)
+		log.Printf("  Market: %s", connector.Config().Market)
+		if config.ProxyURL != "" {
+			log.Printf("  Proxy: configured")
+		}
+		log.Printf("  Force HTTP/2: %v", config.ForceHTTP2)
+		if config.ResponseHeaderTimeout > 0 || config.TLSHandshakeTimeout > 0 || config.ExpectContinueTimeout > 0 {
+			log.Printf("  Transport Timeouts: response header %v, TLS handshake %v, expect continue %v",
+				config.ResponseHeaderTimeout, config.TLSHandshakeTimeout, config.ExpectContinueTimeout)
+		}
+		log.Printf("  Partial Results: %v", config.PartialOK)
+		if config.MonthlyFallback {
+			log.Printf("  Fallback To Monthly Archives: enabled")
+		}
+		log.Printf("  Parse Concurrency: %d", config.ParseConcurrency)
+		if config.SortTrades {
+			log.Printf("  Sort Trades By: %s", config.SortBy)
+		}
+		log.Printf("  Deduplicate Trades: %v", config.Deduplicate)
+		if config.CSVDelimiter != ',' || config.LazyQuotes {
+			log.Printf("  CSV Delimiter: %q (lazy quotes %v)", config.CSVDelimiter, config.LazyQuotes)
+		}
+		if config.ErrorDetails > 0 {
+			log.Printf("  Parse Error Details: %d per archive", config.ErrorDetails)
+		}
+		if config.CacheSize > 0 {
+			log.Printf("  Result Cache Size: %d", config.CacheSize)
+		}
+		if config.ResponseCache > 0 {
+			log.Printf("  Response Cache Size: %d bytes (gzip %v)", config.ResponseCache, config.ResponseGzip)
+		}
+		if !config.MinDate.IsZero() {
+			log.Printf("  Min Date: %s", config.MinDate.Format("2006-01-02"))
+		}
+		if config.OutputDir != "" {
+			log.Printf("  Output Directory: %s", config.OutputDir)
+		}
+		if len(config.AllowedSymbols) > 0 {
+			log.Printf("  Allowed Symbols: %s", strings.Join(config.AllowedSymbols, ","))
+		}
+		if len(config.DeniedSymbols) > 0 {
+			log.Printf("  Denied Symbols: %s", strings.Join(config.DeniedSymbols, ","))
+		}
+		if config.StrictSymbols {
+			log.Printf("  Strict Symbols: quote assets %s", strings.Join(config.QuoteAssets, ","))
+		}
+		if apiKeys.Enabled() {
+			log.Printf("  API Key Auth: enabled (%d keys)", len(config.APIKeys))
+		}
+		log.Printf("  Download Retries: %d", config.DownloadRetries)
+		if config.SpoolDir != "" {
+			log.Printf("  Spool Directory: %s", config.SpoolDir)
+		}
+		if config.SymbolRefreshInterval > 0 {
+			log.Printf("  Symbol Refresh Interval: %v", config.SymbolRefreshInterval)
+		}
+		if config.ClientRPM > 0 {
+			log.Printf("  Client Rate Limit: %.2f req/min (burst %d)", config.ClientRPM, config.ClientBurst)
+		}
+		if config.RateLimitRPS > 0 {
+			log.Printf("  Upstream Rate Limit: %.2f req/s (burst %d)", config.RateLimitRPS, config.RateLimitBurst)
+		}
+		if config.MaxDownloads > 0 {
+			log.Printf("  Max Concurrent Downloads: %d", config.MaxDownloads)
+		}
+		if config.MaxInFlight > 0 {
+			log.Printf("  Max In-Flight Requests: %d", config.MaxInFlight)
+		}
 		log.Printf("Endpoints:")
 		log.Printf("  GET /download?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /download/bookticker?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /download/bookdepth?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /download/fundingrate?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /download/liquidations?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /download/markpriceklines?SYMBOL=<symbol>&INTERVAL=<interval>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /download/indexpriceklines?SYMBOL=<symbol>&INTERVAL=<interval>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /download/premiumindexklines?SYMBOL=<symbol>&INTERVAL=<interval>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /download/days?SYMBOL=<symbol>&DATES=<YYYY-MM-DD>,<YYYY-MM-DD>,...")
+		log.Printf("  POST /download/batch")
+		log.Printf("  GET /count?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /vwap?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /verify?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /export?SYMBOL=<symbol>&FROM=<YYYY-MM-DD>&TO=<YYYY-MM-DD>")
+		log.Printf("  POST /prefetch")
+		log.Printf("  GET /prefetch/<id>")
+		log.Printf("  GET /exists?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day>")
+		log.Printf("  GET /dates?SYMBOL=<symbol>")
+		log.Printf("  GET /symbols[?prefix=<prefix>]")
+		log.Printf("  GET /ws/download?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day> (WebSocket)")
+		log.Printf("  GET /download/stream?SYMBOL=<symbol>&YYYY=<year>&MM=<month>&DD=<day> (Server-Sent Events)")
 		log.Printf("  GET /health")
+		log.Printf("  GET /stats")
+		log.Printf("  GET /ready")
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server failed to start: %v", err)
 		}
@@ -125,6 +602,8 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopRefresh()
+	prefetchHandler.Shutdown()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
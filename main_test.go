@@ -1,15 +1,26 @@
 package main
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -57,6 +68,16 @@ func createMockZipFile(symbol, year, month, day string, trades [][]string) ([]by
 	return buf.Bytes(), nil
 }
 
+// newTestConnector creates a connector from config, failing the test on invalid configuration
+func newTestConnector(t *testing.T, config *binancevisionconnector.ConnectorConfig) *binancevisionconnector.Connector {
+	t.Helper()
+	c, err := binancevisionconnector.NewConnectorWithConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to create connector: %v", err)
+	}
+	return c
+}
+
 // setupMockBinanceServer creates a test HTTP server that serves mock zip files
 func setupMockBinanceServer(t *testing.T) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -109,22 +130,6 @@ func setupMockBinanceServer(t *testing.T) *httptest.Server {
 }
 
 
-// urlRewritingTransport rewrites URLs to point to mock server
-type urlRewritingTransport struct {
-	baseURL  string
-	transport http.RoundTripper
-}
-
-func (t *urlRewritingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Rewrite binance.vision URLs to mock server
-	if strings.Contains(req.URL.Host, "data.binance.vision") {
-		req.URL.Host = strings.TrimPrefix(t.baseURL, "http://")
-		req.URL.Host = strings.TrimPrefix(req.URL.Host, "https://")
-		req.URL.Scheme = "http"
-	}
-	return t.transport.RoundTrip(req)
-}
-
 // TestE2E_DownloadEndpoint tests the full flow: server -> request -> response
 func TestE2E_DownloadEndpoint(t *testing.T) {
 	// Setup mock Binance Vision server
@@ -133,24 +138,12 @@ func TestE2E_DownloadEndpoint(t *testing.T) {
 
 	// Create a custom connector that uses the mock server
 	originalConnector := connector
-	
-	// Create transport that rewrites URLs
-	rewriteTransport := &urlRewritingTransport{
-		baseURL: mockBinanceServer.URL,
-		transport: &http.Transport{},
-	}
 
-	// Create test connector with custom client
+	// Create test connector pointing at the mock server
 	testConnectorConfig := binancevisionconnector.DefaultConfig()
 	testConnectorConfig.Timeout = 10 * time.Second
-	testConnector := binancevisionconnector.NewConnectorWithConfig(testConnectorConfig)
-	
-	// Replace connector's client with transport that rewrites URLs
-	
-	testConnector.SetClient(&http.Client{
-		Timeout:   10 * time.Second,
-		Transport: rewriteTransport,
-	})
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testConnector := newTestConnector(t, testConnectorConfig)
 
 	// Temporarily replace global connector
 	connector = testConnector
@@ -225,12 +218,24 @@ func TestE2E_DownloadEndpoint(t *testing.T) {
 			IsBuyerMaker  bool    `json:"is_buyer_maker"`
 			IsBestMatch   bool    `json:"is_best_match"`
 		} `json:"trades"`
+		CompressedBytes   int64  `json:"compressed_bytes"`
+		UncompressedBytes int64  `json:"uncompressed_bytes"`
+		SourceURL         string `json:"source_url"`
 	}
 
 	if err := json.Unmarshal(dataBytes, &downloadResult); err != nil {
 		t.Fatalf("Failed to unmarshal download result: %v", err)
 	}
 
+	if want := mockBinanceServer.URL + "/data/spot/daily/trades/AIUSDT/AIUSDT-trades-2025-12-28.zip"; downloadResult.SourceURL != want {
+		t.Errorf("Expected source_url %q, got %q", want, downloadResult.SourceURL)
+	}
+
+	if downloadResult.CompressedBytes <= 0 || downloadResult.UncompressedBytes <= 0 {
+		t.Errorf("Expected non-zero byte counts, got compressed=%d uncompressed=%d",
+			downloadResult.CompressedBytes, downloadResult.UncompressedBytes)
+	}
+
 	// Verify data content
 	if downloadResult.Symbol != "AIUSDT" {
 		t.Errorf("Expected symbol AIUSDT, got %s", downloadResult.Symbol)
@@ -265,17 +270,10 @@ func TestE2E_DownloadEndpoint_ErrorCases(t *testing.T) {
 	defer mockBinanceServer.Close()
 
 	// Create test connector
-	rewriteTransport := &urlRewritingTransport{
-		baseURL: mockBinanceServer.URL,
-		transport: &http.Transport{},
-	}
 	testConnectorConfig := binancevisionconnector.DefaultConfig()
 	testConnectorConfig.Timeout = 10 * time.Second
-	testConnector := binancevisionconnector.NewConnectorWithConfig(testConnectorConfig)
-	testConnector.SetClient(&http.Client{
-		Timeout:   10 * time.Second,
-		Transport: rewriteTransport,
-	})
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testConnector := newTestConnector(t, testConnectorConfig)
 
 	// Create handlers with test connector
 	testMetrics := &handlers.RequestMetrics{}
@@ -290,7 +288,7 @@ func TestE2E_DownloadEndpoint_ErrorCases(t *testing.T) {
 
 	// Start the application server
 	mux := http.NewServeMux()
-	mux.HandleFunc("/download", requestTrackingMiddleware(testDownloadHandler.Handle))
+	mux.HandleFunc("/download", requestTrackingMiddleware(handlers.MethodGuard(testDownloadHandler.Handle, http.MethodGet)))
 	mux.HandleFunc("/health", testHealthHandler.Handle)
 
 	testServer := httptest.NewServer(mux)
@@ -302,6 +300,7 @@ func TestE2E_DownloadEndpoint_ErrorCases(t *testing.T) {
 		queryParams    string
 		expectedStatus int
 		expectedError   string
+		expectedCode   string
 	}{
 		{
 			name:           "missing parameters",
@@ -309,6 +308,7 @@ func TestE2E_DownloadEndpoint_ErrorCases(t *testing.T) {
 			queryParams:    "",
 			expectedStatus: http.StatusBadRequest,
 			expectedError:   "Missing required parameters",
+			expectedCode:   handlers.CodeMissingParameter,
 		},
 		{
 			name:           "invalid symbol format",
@@ -316,6 +316,7 @@ func TestE2E_DownloadEndpoint_ErrorCases(t *testing.T) {
 			queryParams:    "SYMBOL=ai-usdt&YYYY=2025&MM=12&DD=28",
 			expectedStatus: http.StatusBadRequest,
 			expectedError:   "invalid symbol format",
+			expectedCode:   handlers.CodeInvalidSymbol,
 		},
 		{
 			name:           "invalid date",
@@ -323,6 +324,7 @@ func TestE2E_DownloadEndpoint_ErrorCases(t *testing.T) {
 			queryParams:    "SYMBOL=AIUSDT&YYYY=2025&MM=13&DD=28",
 			expectedStatus: http.StatusBadRequest,
 			expectedError:   "invalid month",
+			expectedCode:   handlers.CodeInvalidDate,
 		},
 		{
 			name:           "wrong HTTP method",
@@ -330,6 +332,7 @@ func TestE2E_DownloadEndpoint_ErrorCases(t *testing.T) {
 			queryParams:    "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28",
 			expectedStatus: http.StatusMethodNotAllowed,
 			expectedError:   "Method not allowed",
+			expectedCode:   handlers.CodeMethodNotAllowed,
 		},
 	}
 
@@ -371,6 +374,10 @@ func TestE2E_DownloadEndpoint_ErrorCases(t *testing.T) {
 			if !strings.Contains(apiResp.Error, tt.expectedError) {
 				t.Errorf("Expected error to contain '%s', got '%s'", tt.expectedError, apiResp.Error)
 			}
+
+			if apiResp.ErrorCode != tt.expectedCode {
+				t.Errorf("Expected error code %s, got %s", tt.expectedCode, apiResp.ErrorCode)
+			}
 		})
 	}
 }
@@ -380,7 +387,7 @@ func TestE2E_HealthEndpoint(t *testing.T) {
 	// Create handlers
 	testMetrics := &handlers.RequestMetrics{}
 	testConnectorConfig := binancevisionconnector.DefaultConfig()
-	testConnector := binancevisionconnector.NewConnectorWithConfig(testConnectorConfig)
+	testConnector := newTestConnector(t, testConnectorConfig)
 	testDownloadHandler := &handlers.DownloadHandler{
 		Connector: testConnector,
 		Timeout:   10 * time.Second,
@@ -451,7 +458,7 @@ func TestE2E_ConcurrentRequests(t *testing.T) {
 	// Create handlers
 	testMetrics := &handlers.RequestMetrics{}
 	testConnectorConfig := binancevisionconnector.DefaultConfig()
-	testConnector := binancevisionconnector.NewConnectorWithConfig(testConnectorConfig)
+	testConnector := newTestConnector(t, testConnectorConfig)
 	testDownloadHandler := &handlers.DownloadHandler{
 		Connector: testConnector,
 		Timeout:   10 * time.Second,
@@ -516,100 +523,1965 @@ func TestE2E_ConcurrentRequests(t *testing.T) {
 	}
 }
 
-// TestDownloadHandler tests handler directly (unit test)
-func TestDownloadHandler(t *testing.T) {
+// TestE2E_BatchDownloadEndpoint tests batch downloads with mixed valid and invalid items
+func TestE2E_BatchDownloadEndpoint(t *testing.T) {
+	// Setup mock Binance Vision server
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testConnector := newTestConnector(t, testConnectorConfig)
+
+	testBatchHandler := &handlers.BatchHandler{
+		Connector:    testConnector,
+		Timeout:      10 * time.Second,
+		Metrics:      &handlers.RequestMetrics{},
+		MaxBatchSize: 2,
+		Concurrency:  2,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/download/batch", requestTrackingMiddleware(testBatchHandler.Handle))
+
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	body := `[{"symbol":"AIUSDT","year":"2025","month":"12","day":"28"},{"symbol":"AI-USDT","year":"2025","month":"12","day":"28"}]`
+	resp, err := http.Post(testServer.URL+"/download/batch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var apiResp struct {
+		Success bool                       `json:"success"`
+		Data    []handlers.BatchItemResult `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+
+	if len(apiResp.Data) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(apiResp.Data))
+	}
+
+	if !apiResp.Data[0].Success || apiResp.Data[0].Data == nil || apiResp.Data[0].Data.TradeCount != 3 {
+		t.Errorf("Expected first item to succeed with 3 trades, got %+v", apiResp.Data[0])
+	}
+
+	if apiResp.Data[1].Success || !strings.Contains(apiResp.Data[1].Error, "invalid symbol format") {
+		t.Errorf("Expected second item to fail with invalid symbol, got %+v", apiResp.Data[1])
+	}
+
+	// Exceeding the maximum batch size is rejected
+	body = `[{"symbol":"AIUSDT","year":"2025","month":"12","day":"26"},{"symbol":"AIUSDT","year":"2025","month":"12","day":"27"},{"symbol":"AIUSDT","year":"2025","month":"12","day":"28"}]`
+	resp2, err := http.Post(testServer.URL+"/download/batch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp2.Body.Close()
+
+	if resp2.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for oversized batch, got %d", resp2.StatusCode)
+	}
+}
+
+// TestE2E_BatchDownloadEndpoint_Timeout tests that the batch timeout bounds the whole batch
+func TestE2E_BatchDownloadEndpoint_Timeout(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	// 2025-12-26 stalls until the client gives up; other days come from the mock
+	stallingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "2025-12-26") {
+			<-r.Context().Done()
+			return
+		}
+		http.Redirect(w, r, mockBinanceServer.URL+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer stallingServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = stallingServer.URL
+	testBatchHandler := &handlers.BatchHandler{
+		Connector:   newTestConnector(t, testConnectorConfig),
+		Timeout:     200 * time.Millisecond,
+		Metrics:     &handlers.RequestMetrics{},
+		Concurrency: 1,
+	}
+
+	testServer := httptest.NewServer(requestTrackingMiddleware(testBatchHandler.Handle))
+	defer testServer.Close()
+
+	body := `[{"symbol":"AIUSDT","year":"2025","month":"12","day":"28"},` +
+		`{"symbol":"AIUSDT","year":"2025","month":"12","day":"26"},` +
+		`{"symbol":"AIUSDT","year":"2025","month":"12","day":"27"}]`
+	start := time.Now()
+	resp, err := http.Post(testServer.URL+"/download/batch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("batch took %v, want it bounded by its timeout", elapsed)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var apiResp struct {
+		Message string                     `json:"message"`
+		Data    []handlers.BatchItemResult `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+	if len(apiResp.Data) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(apiResp.Data))
+	}
+
+	if !apiResp.Data[0].Success {
+		t.Errorf("Expected the item completed before the timeout to succeed, got %+v", apiResp.Data[0])
+	}
+	for _, item := range apiResp.Data[1:] {
+		if item.Success || item.ErrorCode != handlers.CodeTimeout {
+			t.Errorf("Expected %s to fail with %s, got %+v", item.Date, handlers.CodeTimeout, item)
+		}
+	}
+	if !strings.Contains(apiResp.Data[2].Error, "Not downloaded") {
+		t.Errorf("Expected the queued item not to be started, got %q", apiResp.Data[2].Error)
+	}
+	if !strings.Contains(apiResp.Message, "timeout exceeded") {
+		t.Errorf("Expected the message to report the timeout, got %q", apiResp.Message)
+	}
+}
+
+// TestE2E_ExistsEndpoint tests the archive availability check end-to-end
+func TestE2E_ExistsEndpoint(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/exists", requestTrackingMiddleware(testDownloadHandler.HandleExists))
+
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/exists?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var apiResp struct {
+		Success bool                                `json:"success"`
+		Data    binancevisionconnector.Availability `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+
+	if !apiResp.Data.Exists {
+		t.Errorf("Expected archive to exist")
+	}
+	if apiResp.Data.ContentLength <= 0 {
+		t.Errorf("Expected positive content length, got %d", apiResp.Data.ContentLength)
+	}
+}
+
+// TestE2E_DownloadEndpoint_Parquet tests that format=parquet and format=arrow stream
+// a Parquet file and an Arrow IPC stream
+func TestE2E_DownloadEndpoint_Parquet(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/download", requestTrackingMiddleware(testDownloadHandler.Handle))
+
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
 	tests := []struct {
-		name           string
-		method         string
-		queryParams    string
-		expectedStatus int
-		expectError    bool
+		format      string
+		contentType string
+		filename    string
+		prefix      []byte
+		suffix      []byte
 	}{
-		{
-			name:           "missing parameters",
-			method:         "GET",
-			queryParams:    "",
-			expectedStatus: http.StatusBadRequest,
-			expectError:    true,
-		},
-		{
-			name:           "invalid symbol format",
-			method:         "GET",
-			queryParams:    "SYMBOL=ai-usdt&YYYY=2025&MM=12&DD=28",
-			expectedStatus: http.StatusBadRequest,
-			expectError:    true,
-		},
-		{
-			name:           "invalid date",
-			method:         "GET",
-			queryParams:    "SYMBOL=AIUSDT&YYYY=2025&MM=13&DD=28",
-			expectedStatus: http.StatusBadRequest,
-			expectError:    true,
-		},
-		{
-			name:           "wrong HTTP method",
-			method:         "POST",
-			queryParams:    "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28",
-			expectedStatus: http.StatusMethodNotAllowed,
-			expectError:    true,
-		},
+		{"parquet", "application/vnd.apache.parquet", "AIUSDT-trades-2025-12-28.parquet", []byte("PAR1"), []byte("PAR1")},
+		{"arrow", "application/vnd.apache.arrow.stream", "AIUSDT-trades-2025-12-28.arrows",
+			[]byte{0xff, 0xff, 0xff, 0xff}, []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Create a test handler
-			testMetrics := &handlers.RequestMetrics{}
-			testConnectorConfig := binancevisionconnector.DefaultConfig()
-			testConnector := binancevisionconnector.NewConnectorWithConfig(testConnectorConfig)
-			testHandler := &handlers.DownloadHandler{
-				Connector: testConnector,
-				Timeout:   10 * time.Second,
-				Metrics:   testMetrics,
+		t.Run(tt.format, func(t *testing.T) {
+			resp, err := http.Get(testServer.URL + "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&format=" + tt.format)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
 			}
+			defer resp.Body.Close()
 
-			req := httptest.NewRequest(tt.method, "/download?"+tt.queryParams, nil)
-			w := httptest.NewRecorder()
-
-			testHandler.Handle(w, req)
-
-			if w.Code != tt.expectedStatus {
-				t.Errorf("downloadHandler() status code = %d, want %d", w.Code, tt.expectedStatus)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Expected Content-Type %s, got %s", tt.contentType, got)
+			}
+			if got := resp.Header.Get("Content-Disposition"); !strings.Contains(got, tt.filename) {
+				t.Errorf("Expected Content-Disposition naming %s, got %s", tt.filename, got)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			if !bytes.HasPrefix(body, tt.prefix) || !bytes.HasSuffix(body, tt.suffix) {
+				t.Errorf("Expected a complete %s file, got %d bytes", tt.format, len(body))
 			}
 
-			if tt.expectError {
-				var response handlers.APIResponse
-				if err := json.Unmarshal(w.Body.Bytes(), &response); err == nil {
-					if response.Success {
-						t.Errorf("Expected error response but got success")
-					}
-				}
+			// There is no buffered result to paginate
+			resp, err = http.Get(testServer.URL + "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&limit=1&format=" + tt.format)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %s with limit, got %d", tt.format, resp.StatusCode)
 			}
 		})
 	}
 }
 
-// TestHealthHandler tests health handler directly (unit test)
-func TestHealthHandler(t *testing.T) {
-	testMetrics := &handlers.RequestMetrics{}
-	testHandler := &handlers.HealthHandler{
-		Metrics: testMetrics,
+// TestE2E_SymbolsEndpoint tests that /symbols serves the symbol index once it is built
+func TestE2E_SymbolsEndpoint(t *testing.T) {
+	const root = "data/spot/daily/trades/"
+	listingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := r.URL.Query().Get("prefix")
+		fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated>`)
+		if prefix == root {
+			for _, symbol := range []string{"AIUSDT", "BTCUSDT", "BTCEUR"} {
+				fmt.Fprintf(w, `<CommonPrefixes><Prefix>%s%s/</Prefix></CommonPrefixes>`, root, symbol)
+			}
+		} else {
+			symbol := strings.TrimSuffix(strings.TrimPrefix(prefix, root), "/")
+			fmt.Fprintf(w, `<Contents><Key>%s%s-trades-2025-12-28.zip</Key></Contents>`, prefix, symbol)
+		}
+		fmt.Fprint(w, `</ListBucketResult>`)
+	}))
+	defer listingServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.ListingURL = listingServer.URL
+	testConnector := newTestConnector(t, testConnectorConfig)
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: testConnector,
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
 	}
 
-	req := httptest.NewRequest("GET", "/health", nil)
-	w := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/symbols", requestTrackingMiddleware(testDownloadHandler.HandleSymbols))
 
-	testHandler.Handle(w, req)
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
 
-	if w.Code != http.StatusOK {
-		t.Errorf("healthHandler() status code = %d, want %d", w.Code, http.StatusOK)
+	// The index has not been built yet
+	resp, err := http.Get(testServer.URL + "/symbols")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503 before the index is built, got %d", resp.StatusCode)
 	}
 
-	var response handlers.APIResponse
-	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
-		t.Errorf("Failed to parse JSON response: %v", err)
+	if err := testConnector.RefreshSymbols(t.Context()); err != nil {
+		t.Fatalf("RefreshSymbols() error = %v", err)
 	}
 
-	if !response.Success {
-		t.Errorf("Expected health check to succeed")
+	resp, err = http.Get(testServer.URL + "/symbols?prefix=btc")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var apiResp struct {
+		Success bool                `json:"success"`
+		Data    handlers.SymbolList `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+
+	if apiResp.Data.Count != 2 || apiResp.Data.Symbols[0].Symbol != "BTCEUR" || apiResp.Data.Symbols[1].Symbol != "BTCUSDT" {
+		t.Errorf("Expected BTCEUR and BTCUSDT, got %+v", apiResp.Data.Symbols)
+	}
+	if apiResp.Data.Symbols[0].FirstDate != "2025-12-28" {
+		t.Errorf("Expected first date 2025-12-28, got %s", apiResp.Data.Symbols[0].FirstDate)
+	}
+}
+
+// TestE2E_CountEndpoint tests that /count reports the number of trades without returning them
+func TestE2E_CountEndpoint(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/count", requestTrackingMiddleware(testDownloadHandler.HandleCount))
+
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/count?SYMBOL=aiusdt&YYYY=2025&MM=12&DD=28")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var apiResp struct {
+		Success bool                   `json:"success"`
+		Data    map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+
+	if apiResp.Data["symbol"] != "AIUSDT" || apiResp.Data["date"] != "2025-12-28" {
+		t.Errorf("Expected AIUSDT on 2025-12-28, got %v on %v", apiResp.Data["symbol"], apiResp.Data["date"])
+	}
+	if apiResp.Data["trade_count"] != float64(3) {
+		t.Errorf("Expected trade_count 3, got %v", apiResp.Data["trade_count"])
+	}
+	if _, ok := apiResp.Data["trades"]; ok {
+		t.Errorf("Expected no trades in the count response")
+	}
+}
+
+// TestE2E_VWAPEndpoint tests /vwap and the opt-in vwap field of /download
+func TestE2E_VWAPEndpoint(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/vwap", requestTrackingMiddleware(testDownloadHandler.HandleVWAP))
+	mux.HandleFunc("/download", requestTrackingMiddleware(testDownloadHandler.Handle))
+
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	// Quote quantities of the mock trades over their quantities
+	wantVWAP := (0.001234*100 + 0.001235*200 + 0.001236*150) / 450
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantVWAP   bool
+	}{
+		{"vwap endpoint", "/vwap?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28", http.StatusOK, true},
+		{"download with vwap covers the whole day", "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&vwap=true&limit=1", http.StatusOK, true},
+		{"download without vwap", "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28", http.StatusOK, false},
+		{"invalid vwap value", "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&vwap=maybe", http.StatusBadRequest, false},
+		{"missing date", "/vwap?SYMBOL=AIUSDT", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(testServer.URL + tt.path)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+
+			var apiResp struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+				t.Fatalf("Failed to decode JSON response: %v", err)
+			}
+
+			vwap, ok := apiResp.Data["vwap"].(float64)
+			if ok != tt.wantVWAP {
+				t.Fatalf("Expected vwap present = %v, got %v", tt.wantVWAP, apiResp.Data["vwap"])
+			}
+			if ok && math.Abs(vwap-wantVWAP) > 1e-12 {
+				t.Errorf("Expected vwap %v, got %v", wantVWAP, vwap)
+			}
+		})
+	}
+}
+
+// TestE2E_DownloadStreamEndpoint tests the Server-Sent Events progress stream
+func TestE2E_DownloadEndpoint_TradeFilters(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testConnectorConfig.CacheSize = 10
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	testServer := httptest.NewServer(requestTrackingMiddleware(testDownloadHandler.Handle))
+	defer testServer.Close()
+
+	// The mock day has two buyer-maker trades and one taker trade
+	tests := []struct {
+		name         string
+		query        string
+		wantStatus   int
+		wantIDs      []float64
+		wantFiltered float64
+	}{
+		{"all trades", "", http.StatusOK, []float64{123456789, 123456790, 123456791}, 0},
+		{"maker", "&side=maker", http.StatusOK, []float64{123456789, 123456791}, 1},
+		{"taker", "&side=taker", http.StatusOK, []float64{123456790}, 2},
+		{"unfiltered after filtered is not served the filtered result", "", http.StatusOK, []float64{123456789, 123456790, 123456791}, 0},
+		{"invalid side", "&side=buy", http.StatusBadRequest, nil, 0},
+		{"from trade ID", "&FROM_ID=123456790", http.StatusOK, []float64{123456790, 123456791}, 1},
+		{"to trade ID", "&TO_ID=123456790", http.StatusOK, []float64{123456789, 123456790}, 0},
+		{"trade ID range and side", "&FROM_ID=123456789&TO_ID=123456790&side=maker", http.StatusOK, []float64{123456789}, 1},
+		{"reversed trade ID range", "&FROM_ID=123456791&TO_ID=123456789", http.StatusBadRequest, nil, 0},
+		{"non-integer trade ID", "&TO_ID=12.5", http.StatusBadRequest, nil, 0},
+		{"min quote", "&min_quote=0.18", http.StatusOK, []float64{123456790, 123456791}, 1},
+		{"min quote and side", "&min_quote=0.18&side=maker", http.StatusOK, []float64{123456791}, 2},
+		{"negative min quote", "&min_quote=-1", http.StatusBadRequest, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(testServer.URL + "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28" + tt.query)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var apiResp struct {
+				Data struct {
+					TradeCount int                      `json:"trade_count"`
+					Trades     []map[string]interface{} `json:"trades"`
+					ParseStats map[string]interface{}   `json:"parse_stats"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+				t.Fatalf("Failed to decode JSON response: %v", err)
+			}
+
+			if apiResp.Data.TradeCount != len(tt.wantIDs) || len(apiResp.Data.Trades) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d trades, got trade_count %d with %d trades", len(tt.wantIDs), apiResp.Data.TradeCount, len(apiResp.Data.Trades))
+			}
+			for i, trade := range apiResp.Data.Trades {
+				if trade["trade_id"] != tt.wantIDs[i] {
+					t.Errorf("Trade %d: expected trade_id %v, got %v", i, tt.wantIDs[i], trade["trade_id"])
+				}
+			}
+			filtered, _ := apiResp.Data.ParseStats["filtered"].(float64)
+			if filtered != tt.wantFiltered {
+				t.Errorf("Expected parse_stats.filtered %v, got %v", tt.wantFiltered, apiResp.Data.ParseStats["filtered"])
+			}
+		})
+	}
+}
+
+func TestE2E_DownloadEndpoint_Envelope(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	testServer := httptest.NewServer(requestTrackingMiddleware(testDownloadHandler.Handle))
+	defer testServer.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []float64 // Expected bare array; nil expects the envelope
+	}{
+		{"bare trades", "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&envelope=false", http.StatusOK, []float64{123456789, 123456790, 123456791}},
+		{"bare page", "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&envelope=false&offset=1&limit=1", http.StatusOK, []float64{123456790}},
+		{"bare filtered empty", "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&envelope=false&min_quote=100", http.StatusOK, []float64{}},
+		{"explicit envelope", "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&envelope=true", http.StatusOK, nil},
+		{"errors keep the envelope", "SYMBOL=AIUSDT&YYYY=2025&MM=13&DD=28&envelope=false", http.StatusBadRequest, nil},
+		{"invalid envelope", "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&envelope=no", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(testServer.URL + "/download?" + tt.query)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			body, _ := io.ReadAll(resp.Body)
+
+			if tt.wantIDs == nil {
+				var apiResp handlers.APIResponse
+				if err := json.Unmarshal(body, &apiResp); err != nil {
+					t.Fatalf("Failed to decode envelope: %v", err)
+				}
+				if apiResp.Success != (tt.wantStatus == http.StatusOK) || (apiResp.Data == nil && apiResp.Error == "") {
+					t.Errorf("Expected an envelope, got %s", body)
+				}
+				return
+			}
+
+			var trades []map[string]interface{}
+			if err := json.Unmarshal(body, &trades); err != nil {
+				t.Fatalf("Failed to decode bare trades: %v (%s)", err, body)
+			}
+			if trades == nil || len(trades) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d trades, got %s", len(tt.wantIDs), body)
+			}
+			for i, trade := range trades {
+				if trade["trade_id"] != tt.wantIDs[i] {
+					t.Errorf("Trade %d: expected trade_id %v, got %v", i, tt.wantIDs[i], trade["trade_id"])
+				}
+			}
+		})
+	}
+}
+
+func TestE2E_DownloadEndpoint_ResponseCache(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	responses := handlers.NewResponseCache(1<<20, false)
+	metrics := handlers.NewRequestMetrics()
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   metrics,
+		Responses: responses,
+	}
+
+	testServer := httptest.NewServer(requestTrackingMiddleware(testDownloadHandler.Handle))
+	defer testServer.Close()
+
+	get := func(query, requestID string) (int, string, http.Header) {
+		req, _ := http.NewRequest("GET", testServer.URL+"/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28"+query, nil)
+		req.Header.Set(handlers.RequestIDHeader, requestID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read response: %v", err)
+		}
+		return resp.StatusCode, string(body), resp.Header
+	}
+
+	status, first, header := get("&format=csv", "first-id")
+	if cache := header.Get("X-Response-Cache"); status != http.StatusOK || cache != "MISS" {
+		t.Fatalf("Expected status 200 with X-Response-Cache MISS, got %d and %q: %s", status, cache, first)
+	}
+
+	// With upstream gone, only the cached response can answer
+	mockBinanceServer.Close()
+
+	status, second, header := get("&format=csv&timeout=5", "second-id")
+	if status != http.StatusOK || second != first {
+		t.Errorf("Expected the cached response, got status %d: %s", status, second)
+	}
+	if cache := header.Get("X-Response-Cache"); cache != "HIT" {
+		t.Errorf("Expected X-Response-Cache HIT on the replay, got %q", cache)
+	}
+	// Per-request headers come from the replaying request, not the recorded one
+	if got := header.Values(handlers.RequestIDHeader); len(got) != 1 || got[0] != "second-id" {
+		t.Errorf("Expected X-Request-ID second-id on the replay, got %q", got)
+	}
+	if got := header.Values("Vary"); len(got) != 1 || got[0] != "Accept" {
+		t.Errorf("Expected Vary: Accept on the replay, got %q", got)
+	}
+
+	if status, _, _ := get("&format=ndjson", ""); status == http.StatusOK {
+		t.Error("Expected a request in another format to miss the cache and fail")
+	}
+
+	// The replay counts as a download with the trades of the original
+	if stats := responses.Stats(); stats.Hits != 1 || stats.Misses != 2 {
+		t.Errorf("Expected 1 hit and 2 misses, got %+v", stats)
+	}
+	if stats := metrics.DownloadStats(); stats.SampleCount != 2 || stats.AverageTradesPerRequest != 3 {
+		t.Errorf("Expected 2 downloads of 3 trades, got %+v", stats)
+	}
+}
+
+func TestE2E_ExportEndpoint(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	// One day of the range has no archive
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "2025-12-27") {
+			http.NotFound(w, r)
+			return
+		}
+		mockBinanceServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer upstream.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = upstream.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	testServer := httptest.NewServer(requestTrackingMiddleware(testDownloadHandler.HandleExport))
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/export?SYMBOL=AIUSDT&FROM=2025-12-26&TO=2025-12-28")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Disposition"); got != `attachment; filename="AIUSDT-2025-12-26-2025-12-28.tar.gz"` {
+		t.Errorf("Unexpected Content-Disposition %q", got)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	files := map[string][]byte{}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar entry: %v", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", header.Name, err)
+		}
+		names = append(names, header.Name)
+		files[header.Name] = content
+	}
+
+	wantNames := []string{"AIUSDT-2025-12-26.json", "AIUSDT-2025-12-28.json", "_errors.json"}
+	if strings.Join(names, ",") != strings.Join(wantNames, ",") {
+		t.Fatalf("Expected entries %v, got %v", wantNames, names)
+	}
+
+	var day binancevisionconnector.DownloadResult
+	if err := json.Unmarshal(files["AIUSDT-2025-12-28.json"], &day); err != nil {
+		t.Fatalf("Failed to decode day file: %v", err)
+	}
+	if day.Date != "2025-12-28" || day.TradeCount != 3 || len(day.Trades) != 3 {
+		t.Errorf("Unexpected day file: date %s with %d trades", day.Date, len(day.Trades))
+	}
+
+	var failures []handlers.ExportError
+	if err := json.Unmarshal(files["_errors.json"], &failures); err != nil {
+		t.Fatalf("Failed to decode _errors.json: %v", err)
+	}
+	if len(failures) != 1 || failures[0].Date != "2025-12-27" || failures[0].ErrorCode != handlers.CodeArchiveNotFound {
+		t.Errorf("Unexpected failures %+v", failures)
+	}
+}
+
+func TestE2E_DownloadStreamEndpoint(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/download/stream", requestTrackingMiddleware(testDownloadHandler.HandleDownloadStream))
+
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/download/stream?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, got %q", ct)
+	}
+
+	// Collect the event types in order, keeping the data of the last one of each
+	var names []string
+	data := make(map[string]map[string]interface{})
+	var name string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+			names = append(names, name)
+		case strings.HasPrefix(line, "data: "):
+			var payload map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &payload); err != nil {
+				t.Fatalf("Failed to decode %s event data: %v", name, err)
+			}
+			data[name] = payload
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Failed to read event stream: %v", err)
+	}
+
+	if len(names) < 2 || names[len(names)-1] != "complete" || names[len(names)-2] != "progress" {
+		t.Fatalf("Expected progress events followed by complete, got %v", names)
+	}
+	if data["progress"]["trades_parsed"] != float64(3) || data["progress"]["bytes_downloaded"] == float64(0) {
+		t.Errorf("Expected final progress with 3 trades parsed, got %v", data["progress"])
+	}
+	if data["complete"]["trade_count"] != float64(3) || data["complete"]["symbol"] != "AIUSDT" {
+		t.Errorf("Expected a summary of 3 AIUSDT trades, got %v", data["complete"])
+	}
+	if _, ok := data["complete"]["trades"]; ok {
+		t.Errorf("Expected no trades in the complete event")
+	}
+
+	// Invalid parameters are rejected before the stream starts
+	resp, err = http.Get(testServer.URL + "/download/stream?SYMBOL=AIUSDT")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for missing parameters, got %d", resp.StatusCode)
+	}
+}
+
+// TestE2E_VerifyEndpoint tests the /verify data quality report
+func TestE2E_VerifyEndpoint(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", requestTrackingMiddleware(testDownloadHandler.HandleVerify))
+
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/verify?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	var apiResp struct {
+		Success bool                   `json:"success"`
+		Data    map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+
+	if apiResp.Data["parsed_count"] != float64(3) || apiResp.Data["skipped_count"] != float64(0) {
+		t.Errorf("Expected 3 parsed and 0 skipped rows, got %v and %v", apiResp.Data["parsed_count"], apiResp.Data["skipped_count"])
+	}
+	if bytes, _ := apiResp.Data["bytes"].(float64); bytes <= 0 {
+		t.Errorf("Expected the archive size in bytes, got %v", apiResp.Data["bytes"])
+	}
+	// The mock server publishes no valid checksum file
+	if apiResp.Data["checksum_ok"] != false || apiResp.Data["checksum_error"] == nil {
+		t.Errorf("Expected checksum_ok false with a checksum_error, got %v and %v", apiResp.Data["checksum_ok"], apiResp.Data["checksum_error"])
+	}
+}
+
+// TestE2E_DownloadEndpoint_ValidateOnly tests that ?validate=true checks existence without downloading
+func TestE2E_DownloadEndpoint_ValidateOnly(t *testing.T) {
+	var getRequests int
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+	countingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			getRequests++
+		}
+		mockBinanceServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer countingServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = countingServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"valid and exists", "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&validate=true", http.StatusOK},
+		{"invalid symbol", "SYMBOL=ai-usdt&YYYY=2025&MM=12&DD=28&validate=true", http.StatusBadRequest},
+		{"invalid validate value", "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&validate=maybe", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			testDownloadHandler.Handle(w, httptest.NewRequest("GET", "/download?"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var apiResp struct {
+				Data handlers.ValidationResult `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&apiResp); err != nil {
+				t.Fatalf("Failed to decode JSON response: %v", err)
+			}
+			if !apiResp.Data.Valid || !apiResp.Data.Exists {
+				t.Errorf("Expected valid and existing archive, got %+v", apiResp.Data)
+			}
+		})
+	}
+
+	if getRequests != 0 {
+		t.Errorf("Expected no archive downloads, got %d GET requests", getRequests)
+	}
+}
+
+// TestE2E_DownloadEndpoint_MultipleSymbols tests downloading several symbols in one request
+func TestE2E_DownloadEndpoint_MultipleSymbols(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+	// MISSINGUSDT has no archive upstream
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/MISSINGUSDT/") {
+			http.NotFound(w, r)
+			return
+		}
+		mockBinanceServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = server.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector:   newTestConnector(t, testConnectorConfig),
+		Timeout:     10 * time.Second,
+		Metrics:     &handlers.RequestMetrics{},
+		Concurrency: 2,
+	}
+
+	w := httptest.NewRecorder()
+	testDownloadHandler.Handle(w, httptest.NewRequest("GET", "/download?SYMBOL=AIUSDT,BTCUSDT,ai-usdt,MISSINGUSDT,btcusdt&YYYY=2025&MM=12&DD=28", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var apiResp struct {
+		Data struct {
+			Date    string                                            `json:"date"`
+			Results map[string]binancevisionconnector.DownloadResult `json:"results"`
+			Errors  map[string]string                                 `json:"errors"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+
+	if apiResp.Data.Date != "2025-12-28" {
+		t.Errorf("Expected date 2025-12-28, got %s", apiResp.Data.Date)
+	}
+	if len(apiResp.Data.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d: %v", len(apiResp.Data.Results), apiResp.Data.Results)
+	}
+	for _, symbol := range []string{"AIUSDT", "BTCUSDT"} {
+		result, ok := apiResp.Data.Results[symbol]
+		if !ok {
+			t.Errorf("Missing result for %s", symbol)
+			continue
+		}
+		if result.Symbol != symbol || result.TradeCount != 3 {
+			t.Errorf("Unexpected result for %s: symbol %s, %d trades", symbol, result.Symbol, result.TradeCount)
+		}
+	}
+	for _, symbol := range []string{"ai-usdt", "MISSINGUSDT"} {
+		if apiResp.Data.Errors[symbol] == "" {
+			t.Errorf("Expected an error for %s, got %v", symbol, apiResp.Data.Errors)
+		}
+	}
+
+	// Single-symbol-only parameters are rejected
+	w = httptest.NewRecorder()
+	testDownloadHandler.Handle(w, httptest.NewRequest("GET", "/download?SYMBOL=AIUSDT,BTCUSDT&YYYY=2025&MM=12&DD=28&format=csv", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for format with multiple symbols, got %d", w.Code)
+	}
+}
+
+// TestE2E_DownloadDaysEndpoint tests downloading a list of non-contiguous days
+func TestE2E_DownloadDaysEndpoint(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+	// 2025-12-27 has no archive upstream
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "2025-12-27") {
+			http.NotFound(w, r)
+			return
+		}
+		mockBinanceServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = server.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector:     newTestConnector(t, testConnectorConfig),
+		Timeout:       10 * time.Second,
+		Metrics:       &handlers.RequestMetrics{},
+		Concurrency:   2,
+		MaxDays:       3,
+	}
+
+	w := httptest.NewRecorder()
+	testDownloadHandler.HandleDays(w, httptest.NewRequest("GET", "/download/days?SYMBOL=aiusdt&DATES=2025-12-28,2025-12-27,2025-12-22,2025-12-28", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var apiResp struct {
+		Data struct {
+			Symbol  string                                            `json:"symbol"`
+			Results map[string]binancevisionconnector.DownloadResult `json:"results"`
+			Errors  map[string]string                                 `json:"errors"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+
+	if apiResp.Data.Symbol != "AIUSDT" {
+		t.Errorf("Expected symbol AIUSDT, got %s", apiResp.Data.Symbol)
+	}
+	if len(apiResp.Data.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d: %v", len(apiResp.Data.Results), apiResp.Data.Results)
+	}
+	for _, date := range []string{"2025-12-28", "2025-12-22"} {
+		if result, ok := apiResp.Data.Results[date]; !ok || result.Date != date || result.TradeCount != 3 {
+			t.Errorf("Unexpected result for %s: %+v", date, result)
+		}
+	}
+	if len(apiResp.Data.Errors) != 1 || apiResp.Data.Errors["2025-12-27"] == "" {
+		t.Errorf("Expected an error for 2025-12-27 only, got %v", apiResp.Data.Errors)
+	}
+
+	for _, query := range []string{
+		"SYMBOL=AIUSDT",
+		"SYMBOL=AIUSDT&DATES=2025-12-28,2025-13-01",
+		"SYMBOL=AIUSDT&DATES=2025-12-20,2025-12-21,2025-12-22,2025-12-23",
+	} {
+		w = httptest.NewRecorder()
+		testDownloadHandler.HandleDays(w, httptest.NewRequest("GET", "/download/days?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", query, w.Code)
+		}
+	}
+}
+
+// TestE2E_DownloadEndpoint_ConditionalGET tests ETag and Last-Modified revalidation
+func TestE2E_DownloadEndpoint_ConditionalGET(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+	// 2025-12-27 was never published
+	publishingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "2025-12-27") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Last-Modified", "Mon, 29 Dec 2025 03:12:45 GMT")
+		mockBinanceServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer publishingServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = publishingServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	const target = "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD="
+	doDay := func(day, header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target+day, nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		testDownloadHandler.Handle(w, req)
+		return w
+	}
+	do := func(header, value string) *httptest.ResponseRecorder {
+		return doDay("28", header, value)
+	}
+
+	first := do("", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d and ETag %q", first.Code, etag)
+	}
+	if got := first.Header().Get("Last-Modified"); got != "Mon, 29 Dec 2025 03:12:45 GMT" {
+		t.Errorf("Expected the upstream Last-Modified, got %q", got)
+	}
+	// The default JSON response is negotiated too, so caches must key on Accept
+	if got := first.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept" {
+		t.Errorf("Expected Vary: Accept on the JSON response, got %q", got)
+	}
+
+	if w := do("If-None-Match", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected 304 with no body for a matching ETag, got %d with %d bytes", w.Code, w.Body.Len())
+	} else if got := w.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept" {
+		t.Errorf("Expected Vary: Accept on the 304, got %q", got)
+	}
+	if w := do("If-None-Match", `W/"other"`); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a different ETag, got %d", w.Code)
+	}
+
+	// If-Modified-Since is compared with the time the archive was published
+	if w := do("If-Modified-Since", "Mon, 29 Dec 2025 03:12:45 GMT"); w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for If-Modified-Since at publication, got %d", w.Code)
+	}
+	if w := do("If-Modified-Since", "Mon, 29 Dec 2025 01:00:00 GMT"); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 for If-Modified-Since before publication, got %d", w.Code)
+	}
+	// A day that was never published is not reported as unchanged
+	if w := doDay("27", "If-Modified-Since", "Tue, 30 Dec 2025 08:00:00 GMT"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for If-Modified-Since on an unpublished day, got %d", w.Code)
+	}
+}
+
+// TestE2E_PrefetchEndpoint tests that a prefetch job warms the cache for later downloads
+func TestE2E_PrefetchEndpoint(t *testing.T) {
+	var getRequests atomic.Int64
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+	countingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		getRequests.Add(1)
+		mockBinanceServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer countingServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = countingServer.URL
+	testConnectorConfig.CacheSize = 10
+	testConnector := newTestConnector(t, testConnectorConfig)
+
+	testPrefetchHandler := &handlers.PrefetchHandler{
+		Connector:    testConnector,
+		Timeout:      10 * time.Second,
+		Metrics:      &handlers.RequestMetrics{},
+		MaxBatchSize: 2,
+		Concurrency:  2,
+	}
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: testConnector,
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/prefetch", requestTrackingMiddleware(testPrefetchHandler.Handle))
+	mux.HandleFunc("/prefetch/{id}", requestTrackingMiddleware(testPrefetchHandler.HandleStatus))
+	mux.HandleFunc("/download", requestTrackingMiddleware(testDownloadHandler.Handle))
+
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	type jobResponse struct {
+		Success bool                 `json:"success"`
+		Data    handlers.PrefetchJob `json:"data"`
+	}
+
+	// Invalid items reject the whole job
+	body := `[{"symbol":"AIUSDT","year":"2025","month":"12","day":"28"},{"symbol":"AI-USDT","year":"2025","month":"12","day":"28"}]`
+	resp, err := http.Post(testServer.URL+"/prefetch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid item, got %d", resp.StatusCode)
+	}
+
+	body = `[{"symbol":"AIUSDT","year":"2025","month":"12","day":"28"}]`
+	resp, err = http.Post(testServer.URL+"/prefetch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var scheduled jobResponse
+	err = json.NewDecoder(resp.Body).Decode(&scheduled)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+	if resp.StatusCode != http.StatusAccepted || scheduled.Data.ID == "" || scheduled.Data.Total != 1 {
+		t.Fatalf("Expected 202 with a job ID, got %d and %+v", resp.StatusCode, scheduled.Data)
+	}
+
+	// Poll until the job finishes
+	var status jobResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(testServer.URL + "/prefetch/" + scheduled.Data.ID)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&status)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode JSON response: %v", err)
+		}
+		if status.Data.Status == "done" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Prefetch job did not finish, last status %+v", status.Data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status.Data.Succeeded != 1 || status.Data.Items[0].Status != "done" {
+		t.Fatalf("Expected the item to succeed, got %+v", status.Data)
+	}
+
+	// The prefetched day is served from the cache
+	fetched := getRequests.Load()
+	resp, err = http.Get(testServer.URL + "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var download struct {
+		Data binancevisionconnector.DownloadResult `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&download)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+	if !download.Data.CacheHit || download.Data.TradeCount != 3 {
+		t.Errorf("Expected a cache hit with 3 trades, got %+v", download.Data)
+	}
+	if got := getRequests.Load(); got != fetched {
+		t.Errorf("Expected no upstream requests for a cached day, got %d", got-fetched)
+	}
+
+	resp, err = http.Get(testServer.URL + "/prefetch/unknown")
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown job, got %d", resp.StatusCode)
+	}
+}
+
+// TestE2E_PrefetchEndpoint_Shutdown tests that jobs scheduled after Shutdown fail their
+// items without downloading them
+func TestE2E_PrefetchEndpoint_Shutdown(t *testing.T) {
+	var getRequests atomic.Int64
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+	countingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		getRequests.Add(1)
+		mockBinanceServer.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer countingServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = countingServer.URL
+	testConnectorConfig.CacheSize = 10
+	testConnector := newTestConnector(t, testConnectorConfig)
+
+	testPrefetchHandler := &handlers.PrefetchHandler{
+		Connector:   testConnector,
+		Timeout:     10 * time.Second,
+		Metrics:     &handlers.RequestMetrics{},
+		Concurrency: 1,
+	}
+	testPrefetchHandler.Shutdown()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/prefetch", requestTrackingMiddleware(testPrefetchHandler.Handle))
+	mux.HandleFunc("/prefetch/{id}", requestTrackingMiddleware(testPrefetchHandler.HandleStatus))
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	type jobResponse struct {
+		Data handlers.PrefetchJob `json:"data"`
+	}
+
+	// Items are validated like batch items
+	body := `[{"symbol":"AIUSDT","year":"2025","month":"12"}]`
+	resp, err := http.Post(testServer.URL+"/prefetch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var rejected handlers.APIResponse
+	err = json.NewDecoder(resp.Body).Decode(&rejected)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest || rejected.ErrorCode != handlers.CodeMissingParameter {
+		t.Errorf("Expected 400 %s for a missing field, got %d %s", handlers.CodeMissingParameter, resp.StatusCode, rejected.ErrorCode)
+	}
+
+	body = `[{"symbol":"AIUSDT","year":"2025","month":"12","day":"28"},{"symbol":"AIUSDT","year":"2025","month":"12","day":"27"}]`
+	resp, err = http.Post(testServer.URL+"/prefetch", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var scheduled jobResponse
+	err = json.NewDecoder(resp.Body).Decode(&scheduled)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+
+	var status jobResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(testServer.URL + "/prefetch/" + scheduled.Data.ID)
+		if err != nil {
+			t.Fatalf("Failed to make request: %v", err)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&status)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode JSON response: %v", err)
+		}
+		if status.Data.Status == "done" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Prefetch job did not finish, last status %+v", status.Data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status.Data.Failed != 2 || status.Data.Items[0].Status != "failed" || status.Data.Items[1].Status != "failed" {
+		t.Errorf("Expected both items to fail after shutdown, got %+v", status.Data)
+	}
+	if got := getRequests.Load(); got != 0 {
+		t.Errorf("Expected no upstream requests after shutdown, got %d", got)
+	}
+}
+
+// TestE2E_DownloadEndpoint_RateLimited tests that upstream 429 responses are passed through
+func TestE2E_DownloadEndpoint_RateLimited(t *testing.T) {
+	throttledServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer throttledServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = throttledServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	req := httptest.NewRequest("GET", "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28", nil)
+	w := httptest.NewRecorder()
+
+	testDownloadHandler.Handle(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Expected Retry-After 30, got %q", got)
+	}
+
+	var apiResp handlers.APIResponse
+	if err := json.NewDecoder(w.Body).Decode(&apiResp); err != nil {
+		t.Fatalf("Failed to decode JSON response: %v", err)
+	}
+	if want := throttledServer.URL + "/data/spot/daily/trades/AIUSDT/AIUSDT-trades-2025-12-28.zip"; apiResp.SourceURL != want {
+		t.Errorf("Expected source_url %q, got %q", want, apiResp.SourceURL)
+	}
+}
+
+// TestDownloadHandler tests handler directly (unit test)
+func TestDownloadHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		queryParams    string
+		expectedStatus int
+		expectError    bool
+	}{
+		{
+			name:           "missing parameters",
+			method:         "GET",
+			queryParams:    "",
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+		{
+			name:           "invalid symbol format",
+			method:         "GET",
+			queryParams:    "SYMBOL=ai-usdt&YYYY=2025&MM=12&DD=28",
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+		{
+			name:           "invalid date",
+			method:         "GET",
+			queryParams:    "SYMBOL=AIUSDT&YYYY=2025&MM=13&DD=28",
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+		{
+			name:           "wrong HTTP method",
+			method:         "POST",
+			queryParams:    "SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a test handler
+			testMetrics := &handlers.RequestMetrics{}
+			testConnectorConfig := binancevisionconnector.DefaultConfig()
+			testConnector := newTestConnector(t, testConnectorConfig)
+			testHandler := &handlers.DownloadHandler{
+				Connector: testConnector,
+				Timeout:   10 * time.Second,
+				Metrics:   testMetrics,
+			}
+
+			req := httptest.NewRequest(tt.method, "/download?"+tt.queryParams, nil)
+			w := httptest.NewRecorder()
+
+			handlers.MethodGuard(testHandler.Handle, http.MethodGet)(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("downloadHandler() status code = %d, want %d", w.Code, tt.expectedStatus)
+			}
+
+			if tt.expectError {
+				var response handlers.APIResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err == nil {
+					if response.Success {
+						t.Errorf("Expected error response but got success")
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestHealthHandler tests health handler directly (unit test)
+func TestHealthHandler(t *testing.T) {
+	testMetrics := &handlers.RequestMetrics{}
+	testHandler := &handlers.HealthHandler{
+		Metrics: testMetrics,
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	testHandler.Handle(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("healthHandler() status code = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response handlers.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Failed to parse JSON response: %v", err)
+	}
+
+	if !response.Success {
+		t.Errorf("Expected health check to succeed")
+	}
+	if _, ok := response.Data.(map[string]interface{})["download_queue_depth"]; ok {
+		t.Errorf("download_queue_depth reported without a connector")
+	}
+}
+
+// TestHealthHandler_UptimeAndLastDownload tests that /health reports the uptime and
+// the time of the last successful download
+func TestHealthHandler_UptimeAndLastDownload(t *testing.T) {
+	testMetrics := handlers.NewRequestMetrics()
+	testMetrics.StartTime = time.Now().Add(-90 * time.Second)
+	testHandler := &handlers.HealthHandler{
+		Metrics: testMetrics,
+	}
+
+	health := func() map[string]interface{} {
+		req := httptest.NewRequest("GET", "/health", nil)
+		w := httptest.NewRecorder()
+		testHandler.Handle(w, req)
+
+		var response handlers.APIResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse JSON response: %v", err)
+		}
+		return response.Data.(map[string]interface{})
+	}
+
+	before := health()
+	if uptime, ok := before["uptime_seconds"].(float64); !ok || uptime < 90 {
+		t.Errorf("uptime_seconds = %v, want at least 90", before["uptime_seconds"])
+	}
+	if got, ok := before["last_successful_download"]; !ok || got != nil {
+		t.Errorf("last_successful_download = %v, want null before any download", got)
+	}
+
+	testMetrics.RecordDownloadSuccess(time.Second, 3)
+	raw, _ := health()["last_successful_download"].(string)
+	last, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		t.Fatalf("last_successful_download = %q, want an RFC 3339 time: %v", raw, err)
+	}
+	if since := time.Since(last); since < 0 || since > time.Minute {
+		t.Errorf("last_successful_download = %v, want about now", last)
+	}
+}
+
+// TestHealthHandler_DownloadQueue tests that /health reports the connector's download queue
+func TestHealthHandler_DownloadQueue(t *testing.T) {
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.MaxConcurrentDownloads = 2
+	testHandler := &handlers.HealthHandler{
+		Metrics:   &handlers.RequestMetrics{},
+		Connector: newTestConnector(t, testConnectorConfig),
+	}
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	w := httptest.NewRecorder()
+
+	testHandler.Handle(w, req)
+
+	var response handlers.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	health := response.Data.(map[string]interface{})
+	for _, key := range []string{"active_downloads", "download_queue_depth"} {
+		if got, ok := health[key]; !ok || got != float64(0) {
+			t.Errorf("%s = %v, want 0", key, got)
+		}
+	}
+}
+
+// TestHealthHandler_CacheStats tests that /health and /stats report the result cache counters
+func TestHealthHandler_CacheStats(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testConnectorConfig.CacheSize = 1
+	testConnector := newTestConnector(t, testConnectorConfig)
+
+	// A miss, a hit, then a miss for another day that evicts the first
+	for _, day := range []string{"28", "28", "27"} {
+		testConnector.DownloadTrades(context.Background(), "AIUSDT", "2025", "12", day)
+	}
+
+	healthHandler := &handlers.HealthHandler{Metrics: &handlers.RequestMetrics{}, Connector: testConnector}
+	w := httptest.NewRecorder()
+	healthHandler.Handle(w, httptest.NewRequest("GET", "/health", nil))
+
+	var response handlers.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	health := response.Data.(map[string]interface{})
+	want := map[string]float64{"cache_hits": 1, "cache_misses": 2, "cache_evictions": 1, "cache_size": 1, "cache_hit_ratio": 1.0 / 3}
+	for key, value := range want {
+		if got, ok := health[key]; !ok || got != value {
+			t.Errorf("%s = %v, want %v", key, got, value)
+		}
+	}
+
+	statsHandler := &handlers.StatsHandler{Metrics: &handlers.RequestMetrics{}, Connector: testConnector}
+	w = httptest.NewRecorder()
+	statsHandler.Handle(w, httptest.NewRequest("GET", "/stats", nil))
+
+	var statsResponse struct {
+		Data struct {
+			Cache binancevisionconnector.CacheStats `json:"cache"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &statsResponse); err != nil {
+		t.Fatalf("Failed to parse JSON response: %v", err)
+	}
+	if cache := statsResponse.Data.Cache; cache.Hits != 1 || cache.Misses != 2 || cache.Capacity != 1 {
+		t.Errorf("/stats cache = %+v, want 1 hit, 2 misses and capacity 1", cache)
+	}
+}
+
+// TestReadyHandler tests the readiness probe against reachable and unreachable upstreams
+func TestReadyHandler(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	unreachableServer := httptest.NewServer(http.NotFoundHandler())
+	unreachableURL := unreachableServer.URL
+	unreachableServer.Close()
+
+	tests := []struct {
+		name           string
+		baseURL        string
+		expectedStatus int
+	}{
+		{"reachable upstream", mockBinanceServer.URL, http.StatusOK},
+		{"unreachable upstream", unreachableURL, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConnectorConfig := binancevisionconnector.DefaultConfig()
+			testConnectorConfig.BaseURL = tt.baseURL
+			testHandler := &handlers.ReadyHandler{
+				Connector: newTestConnector(t, testConnectorConfig),
+				Timeout:   2 * time.Second,
+			}
+
+			req := httptest.NewRequest("GET", "/ready", nil)
+			w := httptest.NewRecorder()
+
+			testHandler.Handle(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("readyHandler() status code = %d, want %d", w.Code, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+// TestDownloadCommand tests the CLI download subcommand against the mock server
+func TestDownloadCommand(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testConnector := newTestConnector(t, testConnectorConfig)
+
+	t.Run("writes result to file", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "trades.json")
+		var stdout, stderr bytes.Buffer
+
+		code := runDownloadCommand(testConnector, handlers.SymbolAccess{}, []string{"-symbol", "aiusdt", "-date", "2025-12-28", "-out", out}, &stdout, &stderr)
+		if code != 0 {
+			t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+		}
+
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+		var result binancevisionconnector.DownloadResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			t.Fatalf("Failed to decode output: %v", err)
+		}
+		if result.Symbol != "AIUSDT" || result.TradeCount != 3 {
+			t.Errorf("Expected 3 AIUSDT trades, got %d %s trades", result.TradeCount, result.Symbol)
+		}
+	})
+
+	t.Run("parses local archive", func(t *testing.T) {
+		zipData, err := createMockZipFile("AIUSDT", "2025", "12", "28", [][]string{
+			{"123456789", "0.001234", "100.0", "0.1234", "1735430400000", "true", "true"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create zip: %v", err)
+		}
+		archive := filepath.Join(t.TempDir(), "AIUSDT-trades-2025-12-28.zip")
+		if err := os.WriteFile(archive, zipData, 0o644); err != nil {
+			t.Fatalf("Failed to write archive: %v", err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		if code := runDownloadCommand(testConnector, handlers.SymbolAccess{}, []string{"-file", archive}, &stdout, &stderr); code != 0 {
+			t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+		}
+
+		var result binancevisionconnector.DownloadResult
+		if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to decode output: %v", err)
+		}
+		if result.Symbol != "AIUSDT" || result.Date != "2025-12-28" || result.TradeCount != 1 {
+			t.Errorf("Unexpected result: %s %s with %d trades", result.Symbol, result.Date, result.TradeCount)
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		denied := handlers.NewSymbolAccess(nil, []string{"AIUSDT"})
+		for _, tt := range []struct {
+			name   string
+			access handlers.SymbolAccess
+			args   []string
+		}{
+			{"invalid date", handlers.SymbolAccess{}, []string{"-symbol", "AIUSDT", "-date", "28-12-2025"}},
+			{"invalid symbol", handlers.SymbolAccess{}, []string{"-symbol", "AI-USDT", "-date", "2025-12-28"}},
+			{"denied symbol", denied, []string{"-symbol", "aiusdt", "-date", "2025-12-28"}},
+			{"file with symbol", handlers.SymbolAccess{}, []string{"-file", "AIUSDT-trades-2025-12-28.zip", "-symbol", "AIUSDT"}},
+			{"file with date", handlers.SymbolAccess{}, []string{"-file", "AIUSDT-trades-2025-12-28.zip", "-date", "2025-12-28"}},
+		} {
+			var stdout, stderr bytes.Buffer
+			if code := runDownloadCommand(testConnector, tt.access, tt.args, &stdout, &stderr); code != 2 {
+				t.Errorf("%s: expected exit code 2, got %d", tt.name, code)
+			}
+		}
+	})
+
+	t.Run("unwritable output file", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		out := filepath.Join(t.TempDir(), "missing", "trades.json")
+		if code := runDownloadCommand(testConnector, handlers.SymbolAccess{}, []string{"-symbol", "AIUSDT", "-date", "2025-12-28", "-out", out}, &stdout, &stderr); code != 1 {
+			t.Errorf("Expected exit code 1, got %d", code)
+		}
+	})
+
+	t.Run("download failure", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		notFoundServer := httptest.NewServer(http.NotFoundHandler())
+		defer notFoundServer.Close()
+
+		failingConfig := binancevisionconnector.DefaultConfig()
+		failingConfig.BaseURL = notFoundServer.URL
+		failing := newTestConnector(t, failingConfig)
+		if code := runDownloadCommand(failing, handlers.SymbolAccess{}, []string{"-symbol", "AIUSDT", "-date", "2025-12-28"}, &stdout, &stderr); code != 1 {
+			t.Errorf("Expected exit code 1, got %d", code)
+		}
+	})
+}
+
+// TestRequestTrackingMiddleware_RequestID tests that request IDs are generated, propagated and echoed
+func TestRequestTrackingMiddleware_RequestID(t *testing.T) {
+	var seen string
+	handler := requestTrackingMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		seen = handlers.RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", "/download", nil))
+
+		got := w.Header().Get(handlers.RequestIDHeader)
+		if got == "" {
+			t.Fatal("Expected a generated X-Request-ID header")
+		}
+		if seen != got {
+			t.Errorf("Expected context request ID %q, got %q", got, seen)
+		}
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+	})
+
+	t.Run("propagated from client", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/download", nil)
+		req.Header.Set(handlers.RequestIDHeader, "client-id-1")
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if got := w.Header().Get(handlers.RequestIDHeader); got != "client-id-1" || seen != "client-id-1" {
+			t.Errorf("Expected request ID client-id-1, got header %q and context %q", got, seen)
+		}
+	})
+}
+
+// TestE2E_WebSocketDownload tests streaming trades over a WebSocket end-to-end
+func TestE2E_WebSocketDownload(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws/download", requestTrackingMiddleware(testDownloadHandler.HandleWebSocket))
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(testServer.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	fmt.Fprintf(conn, "GET /ws/download?SYMBOL=AIUSDT&date=2025-12-28 HTTP/1.1\r\n"+
+		"Host: localhost\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Failed to read handshake response: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected status 101, got %d", resp.StatusCode)
+	}
+	// Accept value from the RFC 6455 example key
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Unexpected Sec-WebSocket-Accept %q", got)
+	}
+
+	var messages []string
+	for {
+		opcode, payload := readServerFrame(t, reader)
+		if opcode == 0x8 {
+			if code := binary.BigEndian.Uint16(payload); code != 1000 {
+				t.Errorf("Expected close code 1000, got %d", code)
+			}
+			break
+		}
+		messages = append(messages, string(payload))
+	}
+
+	if len(messages) != 4 {
+		t.Fatalf("Expected 3 trades and a summary, got %d messages: %v", len(messages), messages)
+	}
+
+	var trade binancevisionconnector.Trade
+	if err := json.Unmarshal([]byte(messages[0]), &trade); err != nil || trade.TradeID == 0 {
+		t.Errorf("Expected a trade message, got %s", messages[0])
+	}
+
+	var summary struct {
+		Type       string `json:"type"`
+		TradeCount int    `json:"trade_count"`
+	}
+	if err := json.Unmarshal([]byte(messages[3]), &summary); err != nil {
+		t.Fatalf("Failed to decode summary: %v", err)
+	}
+	if summary.Type != "summary" || summary.TradeCount != 3 {
+		t.Errorf("Expected summary with 3 trades, got %s", messages[3])
+	}
+}
+
+// readServerFrame reads one unmasked WebSocket frame sent by the server
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatalf("Failed to read frame header: %v", err)
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("Failed to read frame payload: %v", err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+func TestE2E_DownloadEndpoint_Sample(t *testing.T) {
+	mockBinanceServer := setupMockBinanceServer(t)
+	defer mockBinanceServer.Close()
+
+	testConnectorConfig := binancevisionconnector.DefaultConfig()
+	testConnectorConfig.BaseURL = mockBinanceServer.URL
+	testDownloadHandler := &handlers.DownloadHandler{
+		Connector: newTestConnector(t, testConnectorConfig),
+		Timeout:   10 * time.Second,
+		Metrics:   &handlers.RequestMetrics{},
+	}
+
+	testServer := httptest.NewServer(requestTrackingMiddleware(testDownloadHandler.Handle))
+	defer testServer.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantIDs    []float64
+		wantOffset float64
+	}{
+		{"head", "head=2", http.StatusOK, []float64{123456789, 123456790}, 0},
+		{"tail", "tail=2", http.StatusOK, []float64{123456790, 123456791}, 1},
+		{"tail longer than the day", "tail=10", http.StatusOK, []float64{123456789, 123456790, 123456791}, 0},
+		{"head and tail", "head=1&tail=1", http.StatusBadRequest, nil, 0},
+		{"zero head", "head=0", http.StatusBadRequest, nil, 0},
+		{"huge tail", "tail=10000000000", http.StatusBadRequest, nil, 0},
+		{"with a limit", "tail=1&limit=1", http.StatusBadRequest, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(testServer.URL + "/download?SYMBOL=AIUSDT&YYYY=2025&MM=12&DD=28&" + tt.query)
+			if err != nil {
+				t.Fatalf("Failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var apiResp struct {
+				Data struct {
+					Trades     []map[string]interface{} `json:"trades"`
+					TradeCount float64                  `json:"trade_count"`
+					TotalCount float64                  `json:"total_count"`
+					Offset     float64                  `json:"offset"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			data := apiResp.Data
+			if data.TotalCount != 3 || data.Offset != tt.wantOffset || data.TradeCount != float64(len(tt.wantIDs)) {
+				t.Errorf("Expected total_count 3, offset %v and trade_count %d, got %v, %v and %v",
+					tt.wantOffset, len(tt.wantIDs), data.TotalCount, data.Offset, data.TradeCount)
+			}
+			if len(data.Trades) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d trades, got %d", len(tt.wantIDs), len(data.Trades))
+			}
+			for i, trade := range data.Trades {
+				if trade["trade_id"] != tt.wantIDs[i] {
+					t.Errorf("Trade %d: expected trade_id %v, got %v", i, tt.wantIDs[i], trade["trade_id"])
+				}
+			}
+		})
 	}
 }